@@ -11,7 +11,7 @@ import (
 	ibcante "github.com/cosmos/ibc-go/v4/modules/core/ante"
 	"github.com/cosmos/ibc-go/v4/modules/core/keeper"
 	customante "github.com/oraichain/orai/app/ante"
-	tmlog "github.com/tendermint/tendermint/libs/log"
+	appconfig "github.com/oraichain/orai/cmd/config"
 
 	wasmkeeper "github.com/CosmWasm/wasmd/x/wasm/keeper"
 	wasmTypes "github.com/CosmWasm/wasmd/x/wasm/types"
@@ -25,7 +25,7 @@ import (
 // channel keeper.
 type HandlerOptions struct {
 	AccountKeeper   evmtypes.AccountKeeper
-	BankKeeper      evmtypes.BankKeeper
+	BankKeeper      customante.BankKeeper
 	EvmKeeper       evmante.EVMKeeper
 	FeegrantKeeper  ante.FeegrantKeeper
 	SignModeHandler authsigning.SignModeHandler
@@ -37,6 +37,17 @@ type HandlerOptions struct {
 	TxCounterStoreKey sdk.StoreKey
 	WasmConfig        wasmTypes.WasmConfig
 	Cdc               codec.BinaryCodec
+	ParamStore        customante.ParamStore
+	WasmKeeper        customante.WasmKeeper
+	ContractKeeper    customante.ContractKeeper
+	MempoolAllowlist  *customante.MempoolAllowlist
+	AddressPolicy     *customante.AddressPolicy
+	// SponsorshipStoreKey is the transient store tracking the fees sponsored in the block
+	SponsorshipStoreKey sdk.StoreKey
+	// PanicDumpDir is the directory where the txs making a decorator panic are dumped, disabled if empty
+	PanicDumpDir string
+	// TxPriorityDecorator computes the mempool priority of the checked txs
+	TxPriorityDecorator customante.TxPriorityDecorator
 }
 
 func (options HandlerOptions) Validate() error {
@@ -67,6 +78,15 @@ func (options HandlerOptions) Validate() error {
 	if options.SignModeHandler == nil {
 		return sdkerrors.Wrap(sdkerrors.ErrLogic, "sign mode handler is required for AnteHandler")
 	}
+	if options.WasmKeeper == nil {
+		return sdkerrors.Wrap(sdkerrors.ErrLogic, "wasm keeper is required for AnteHandler")
+	}
+	if options.ContractKeeper == nil {
+		return sdkerrors.Wrap(sdkerrors.ErrLogic, "contract keeper is required for AnteHandler")
+	}
+	if options.SponsorshipStoreKey == nil {
+		return sdkerrors.Wrap(sdkerrors.ErrLogic, "sponsorship store key is required for AnteHandler")
+	}
 	return nil
 }
 
@@ -87,7 +107,8 @@ func NewAnteHandler(options HandlerOptions) (sdk.AnteHandler, error) {
 	) (newCtx sdk.Context, err error) {
 		var anteHandler sdk.AnteHandler
 
-		defer Recover(ctx.Logger(), &err)
+		// the panics of the decorators are recovered by their RecoveryDecorator, this only catches the rest
+		defer Recover(ctx, &err)
 
 		txWithExtensions, ok := tx.(ante.HasExtensionOptionsTx)
 		if ok {
@@ -159,25 +180,38 @@ func newCosmosAnteHandler(options cosmosHandlerOptions) sdk.AnteHandler {
 
 	var sigVerification sdk.AnteDecorator = ante.NewSigVerificationDecorator(options.AccountKeeper, options.SignModeHandler)
 	if options.isEIP712 {
-		sigVerification = evmante.NewEip712SigVerificationDecorator(options.AccountKeeper, options.SignModeHandler, options.EvmKeeper)
+		sigVerification = customante.NewEip712SigVerificationDecorator(options.AccountKeeper, options.SignModeHandler, options.EvmKeeper)
 	}
 
 	decorators = append(decorators,
-		customante.NewEvmMinGasFilter(options.EvmKeeper), // filter out evm denom from min-gas-prices
-		ante.NewMempoolFeeDecorator(),
+		customante.NewMempoolAuthDecorator(options.MempoolAllowlist),
+		customante.NewAddressPolicyDecorator(options.AddressPolicy),
+		customante.NewRedundantRelayDecorator(options.IBCKeeper.ChannelKeeper), // reject redundant IBC relays before any fee is charged
+		customante.NewEvmMinGasFilter(options.EvmKeeper),                       // filter out evm denom from min-gas-prices
+		// value whitelisted fee tokens in orai before the min gas price checks
+		customante.NewFeeAbstractionDecorator(options.WasmKeeper, options.ParamStore, appconfig.CosmosDenom),
+		customante.NewMempoolFeeDecorator(),
+		options.TxPriorityDecorator, // must be after the fee abstraction which values the fee
+		customante.NewMsgMinGasPriceDecorator(options.EvmKeeper, options.ParamStore), // consensus minimum fee per msg type
 		customante.NewVestingAccountDecorator(),
 		customante.NewMinCommissionDecorator(options.Cdc),
 		customante.NewAuthzLimiterDecorator(
 			sdk.MsgTypeURL(&evmtypes.MsgEthereumTx{}),
 			sdk.MsgTypeURL(&vesting.MsgCreateVestingAccount{}),
 		),
+		customante.NewGasCapDecorator(options.ParamStore, options.MaxTxGasWanted),
 		wasmkeeper.NewLimitSimulationGasDecorator(options.WasmConfig.SimulationGasLimit),
 		wasmkeeper.NewCountTXDecorator(options.TxCounterStoreKey),
 		ante.NewValidateBasicDecorator(),
 		ante.NewTxTimeoutHeightDecorator(),
 		ante.NewValidateMemoDecorator(options.AccountKeeper),
 		ante.NewConsumeGasForTxSizeDecorator(options.AccountKeeper),
-		ante.NewDeductFeeDecorator(options.AccountKeeper, options.BankKeeper, options.FeegrantKeeper),
+		// collects the cw20 fee tokens, and deducts the bank fee of new accounts from the sponsorship pool, or else
+		// from the fee payer
+		customante.NewDeductFeeTokensDecorator(options.ContractKeeper, options.ParamStore, customante.NewSponsoredDeductFeeDecorator(
+			options.AccountKeeper, options.BankKeeper, options.FeegrantKeeper,
+			options.ParamStore, options.SponsorshipStoreKey, appconfig.CosmosDenom,
+		)),
 		// SetPubKeyDecorator must be called before all signature verification decorators
 		ante.NewSetPubKeyDecorator(options.AccountKeeper),
 		ante.NewValidateSigCountDecorator(options.AccountKeeper),
@@ -187,37 +221,36 @@ func newCosmosAnteHandler(options cosmosHandlerOptions) sdk.AnteHandler {
 		ibcante.NewAnteDecorator(options.IBCKeeper),
 	)
 
-	return sdk.ChainAnteDecorators(decorators...)
+	return sdk.ChainAnteDecorators(customante.WithRecovery(options.PanicDumpDir, decorators...)...)
 }
 
 func newEthAnteHandler(options HandlerOptions) sdk.AnteHandler {
-	return sdk.ChainAnteDecorators(
+	return sdk.ChainAnteDecorators(customante.WithRecovery(options.PanicDumpDir,
 		evmante.NewEthSetUpContextDecorator(options.EvmKeeper), // outermost AnteDecorator. SetUpContext must be called first
 		evmante.NewEthMempoolFeeDecorator(options.EvmKeeper),   // Check eth effective gas price against minimal-gas-prices
+		options.TxPriorityDecorator,
 		evmante.NewEthValidateBasicDecorator(options.EvmKeeper),
 		evmante.NewEthSigVerificationDecorator(options.EvmKeeper),
+		customante.NewMempoolAuthDecorator(options.MempoolAllowlist), // must be after the sig verification which sets the sender
+		customante.NewAddressPolicyDecorator(options.AddressPolicy),
 		evmante.NewEthAccountVerificationDecorator(options.AccountKeeper, options.BankKeeper, options.EvmKeeper),
 		evmante.NewEthGasConsumeDecorator(options.EvmKeeper, options.MaxTxGasWanted),
 		evmante.NewCanTransferDecorator(options.EvmKeeper),
 		evmante.NewEthIncrementSenderSequenceDecorator(options.AccountKeeper), // innermost AnteDecorator.
-	)
+	)...)
 }
 
-func Recover(logger tmlog.Logger, err *error) {
+// Recover turns a panic of the ante handler into an error
+func Recover(ctx sdk.Context, err *error) {
 	if r := recover(); r != nil {
-		*err = sdkerrors.Wrapf(sdkerrors.ErrPanic, "%v", r)
-
-		if e, ok := r.(error); ok {
-			logger.Error(
-				"ante handler panicked",
-				"error", e,
-				"stack trace", string(debug.Stack()),
-			)
-		} else {
-			logger.Error(
-				"ante handler panicked",
-				"recover", fmt.Sprintf("%v", r),
-			)
-		}
+		kind, recoveredErr := customante.ClassifyPanic(ctx, r)
+		*err = recoveredErr
+
+		ctx.Logger().Error(
+			"ante handler panicked",
+			"kind", kind,
+			"recover", fmt.Sprintf("%v", r),
+			"stack trace", string(debug.Stack()),
+		)
 	}
 }
@@ -0,0 +1,201 @@
+package ante
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+
+	wasmtypes "github.com/CosmWasm/wasmd/x/wasm/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/types/bech32"
+	sdkerrors "github.com/cosmos/cosmos-sdk/types/errors"
+	"github.com/cosmos/cosmos-sdk/x/authz"
+	banktypes "github.com/cosmos/cosmos-sdk/x/bank/types"
+	ibctransfertypes "github.com/cosmos/ibc-go/v4/modules/apps/transfer/types"
+	"github.com/ethereum/go-ethereum/common"
+	evmtypes "github.com/tharsis/ethermint/x/evm/types"
+)
+
+var _ sdk.AnteDecorator = AddressPolicyDecorator{}
+
+// AddressPolicyFile is the format of the node-local policy file
+type AddressPolicyFile struct {
+	// BlockedAddresses are bech32 or 0x hex encoded addresses
+	BlockedAddresses []string `json:"blocked_addresses"`
+}
+
+// LoadAddressPolicyFile reads the blocked addresses of a policy file
+func LoadAddressPolicyFile(path string) ([]sdk.AccAddress, error) {
+	bz, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var file AddressPolicyFile
+	if err := json.Unmarshal(bz, &file); err != nil {
+		return nil, fmt.Errorf("invalid policy file %s: %w", path, err)
+	}
+
+	blocked := make([]sdk.AccAddress, 0, len(file.BlockedAddresses))
+	for _, s := range file.BlockedAddresses {
+		if strings.HasPrefix(s, "0x") {
+			if !common.IsHexAddress(s) {
+				return nil, fmt.Errorf("invalid hex address %s in policy file %s", s, path)
+			}
+			blocked = append(blocked, common.HexToAddress(s).Bytes())
+			continue
+		}
+
+		addr, err := sdk.AccAddressFromBech32(s)
+		if err != nil {
+			return nil, fmt.Errorf("invalid address %s in policy file %s: %w", s, path, err)
+		}
+		blocked = append(blocked, addr)
+	}
+	return blocked, nil
+}
+
+// AddressPolicy is the node-local list of the addresses whose txs must not enter the mempool.
+// It is safe for concurrent use and can be updated while the node is running.
+type AddressPolicy struct {
+	mu      sync.RWMutex
+	blocked map[string]struct{}
+}
+
+// NewAddressPolicy creates a policy blocking the addresses
+func NewAddressPolicy(blocked []sdk.AccAddress) *AddressPolicy {
+	policy := &AddressPolicy{}
+	policy.Update(blocked)
+	return policy
+}
+
+// Update replaces the blocked addresses
+func (p *AddressPolicy) Update(blocked []sdk.AccAddress) {
+	set := make(map[string]struct{}, len(blocked))
+	for _, addr := range blocked {
+		set[string(addr)] = struct{}{}
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.blocked = set
+}
+
+// IsBlocked returns true if the address is blocked
+func (p *AddressPolicy) IsBlocked(addr []byte) bool {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	_, found := p.blocked[string(addr)]
+	return found
+}
+
+func (p *AddressPolicy) isEmpty() bool {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return len(p.blocked) == 0
+}
+
+// AddressPolicyDecorator refuses to admit into the mempool the txs sending funds from or to blocked addresses:
+// bank sends, IBC transfers, wasm msgs with funds and EVM txs. This is a node-local, non-consensus policy,
+// so it only runs in CheckTx and ReCheckTx. On the Ethereum path it must run after the signature verification,
+// which sets the sender of the msgs.
+type AddressPolicyDecorator struct {
+	policy *AddressPolicy
+}
+
+// NewAddressPolicyDecorator creates a decorator enforcing the address policy.
+// A nil policy admits every tx.
+func NewAddressPolicyDecorator(policy *AddressPolicy) AddressPolicyDecorator {
+	return AddressPolicyDecorator{
+		policy: policy,
+	}
+}
+
+func (apd AddressPolicyDecorator) AnteHandle(ctx sdk.Context, tx sdk.Tx, simulate bool, next sdk.AnteHandler) (newCtx sdk.Context, err error) {
+	if (!ctx.IsCheckTx() && !ctx.IsReCheckTx()) || simulate || apd.policy == nil || apd.policy.isEmpty() {
+		return next(ctx, tx, simulate)
+	}
+
+	if err := apd.checkMsgs(tx.GetMsgs()); err != nil {
+		ctx.Logger().Info("rejected tx by address policy", "error", err.Error())
+		return ctx, err
+	}
+
+	return next(ctx, tx, simulate)
+}
+
+func (apd AddressPolicyDecorator) checkMsgs(msgs []sdk.Msg) error {
+	for _, m := range msgs {
+		var addrs []string
+		switch msg := m.(type) {
+		case *banktypes.MsgSend:
+			addrs = []string{msg.FromAddress, msg.ToAddress}
+
+		case *banktypes.MsgMultiSend:
+			for _, input := range msg.Inputs {
+				addrs = append(addrs, input.Address)
+			}
+			for _, output := range msg.Outputs {
+				addrs = append(addrs, output.Address)
+			}
+
+		case *ibctransfertypes.MsgTransfer:
+			addrs = []string{msg.Sender, msg.Receiver}
+
+		case *wasmtypes.MsgExecuteContract:
+			if !msg.Funds.Empty() {
+				addrs = []string{msg.Sender, msg.Contract}
+			}
+
+		case *wasmtypes.MsgInstantiateContract:
+			if !msg.Funds.Empty() {
+				addrs = []string{msg.Sender}
+			}
+
+		case *wasmtypes.MsgInstantiateContract2:
+			if !msg.Funds.Empty() {
+				addrs = []string{msg.Sender}
+			}
+
+		case *evmtypes.MsgEthereumTx:
+			if err := apd.checkEthMsg(msg); err != nil {
+				return err
+			}
+
+		case *authz.MsgExec:
+			innerMsgs, err := msg.GetMessages()
+			if err != nil {
+				return err
+			}
+			if err := apd.checkMsgs(innerMsgs); err != nil {
+				return err
+			}
+		}
+
+		for _, addr := range addrs {
+			// the receivers of IBC transfers use the prefix of their chain
+			if _, bz, err := bech32.DecodeAndConvert(addr); err == nil && apd.policy.IsBlocked(bz) {
+				return sdkerrors.Wrapf(sdkerrors.ErrUnauthorized, "address %s is blocked by the node's policy in %s", addr, sdk.MsgTypeURL(m))
+			}
+		}
+	}
+	return nil
+}
+
+func (apd AddressPolicyDecorator) checkEthMsg(msg *evmtypes.MsgEthereumTx) error {
+	// the sender has been recovered by the signature verification
+	if from := msg.GetFrom(); apd.policy.IsBlocked(from) {
+		return sdkerrors.Wrapf(sdkerrors.ErrUnauthorized, "address %s is blocked by the node's policy in ethereum tx", common.BytesToAddress(from).Hex())
+	}
+
+	txData, err := evmtypes.UnpackTxData(msg.Data)
+	if err != nil {
+		return err
+	}
+	if to := txData.GetTo(); to != nil && apd.policy.IsBlocked(to.Bytes()) {
+		return sdkerrors.Wrapf(sdkerrors.ErrUnauthorized, "address %s is blocked by the node's policy in ethereum tx", to.Hex())
+	}
+	return nil
+}
@@ -0,0 +1,83 @@
+package ante
+
+import (
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+
+	wasmtypes "github.com/CosmWasm/wasmd/x/wasm/types"
+	"github.com/cosmos/cosmos-sdk/testutil"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	sdkerrors "github.com/cosmos/cosmos-sdk/types/errors"
+	"github.com/cosmos/cosmos-sdk/x/authz"
+	banktypes "github.com/cosmos/cosmos-sdk/x/bank/types"
+	ibctransfertypes "github.com/cosmos/ibc-go/v4/modules/apps/transfer/types"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+	evmtypes "github.com/tharsis/ethermint/x/evm/types"
+)
+
+func TestAddressPolicyDecorator(t *testing.T) {
+	blocked := sdk.AccAddress([]byte("blocked_address_____"))
+	other := sdk.AccAddress([]byte("other_address_______"))
+	funds := sdk.NewCoins(sdk.NewInt64Coin(nativeDenom, 1))
+
+	ethTx := func(from, to sdk.AccAddress) *evmtypes.MsgEthereumTx {
+		toAddr := common.BytesToAddress(to)
+		msg := evmtypes.NewTx(big.NewInt(1), 0, &toAddr, big.NewInt(1), 21000, big.NewInt(1), nil, nil, nil, nil)
+		msg.From = common.BytesToAddress(from).Hex()
+		return msg
+	}
+	execMsg := authz.NewMsgExec(other, []sdk.Msg{&banktypes.MsgSend{FromAddress: other.String(), ToAddress: blocked.String()}})
+
+	key := sdk.NewKVStoreKey("test")
+	ctx := testutil.DefaultContext(key, sdk.NewTransientStoreKey("transient_test")).WithIsCheckTx(true)
+	anteHandler := sdk.ChainAnteDecorators(NewAddressPolicyDecorator(NewAddressPolicy([]sdk.AccAddress{blocked})))
+
+	cases := map[string]struct {
+		msg    sdk.Msg
+		ctx    sdk.Context
+		expErr bool
+	}{
+		"bank send from blocked":        {msg: &banktypes.MsgSend{FromAddress: blocked.String(), ToAddress: other.String()}, ctx: ctx, expErr: true},
+		"bank send to blocked":          {msg: &banktypes.MsgSend{FromAddress: other.String(), ToAddress: blocked.String()}, ctx: ctx, expErr: true},
+		"bank send between others":      {msg: &banktypes.MsgSend{FromAddress: other.String(), ToAddress: other.String()}, ctx: ctx},
+		"bank multisend to blocked":     {msg: &banktypes.MsgMultiSend{Outputs: []banktypes.Output{{Address: blocked.String()}}}, ctx: ctx, expErr: true},
+		"ibc transfer from blocked":     {msg: &ibctransfertypes.MsgTransfer{Sender: blocked.String()}, ctx: ctx, expErr: true},
+		"wasm execute with funds":       {msg: &wasmtypes.MsgExecuteContract{Sender: blocked.String(), Funds: funds}, ctx: ctx, expErr: true},
+		"wasm execute without funds":    {msg: &wasmtypes.MsgExecuteContract{Sender: blocked.String()}, ctx: ctx},
+		"evm transfer from blocked":     {msg: ethTx(blocked, other), ctx: ctx, expErr: true},
+		"evm transfer to blocked":       {msg: ethTx(other, blocked), ctx: ctx, expErr: true},
+		"evm transfer between others":   {msg: ethTx(other, other), ctx: ctx},
+		"authz exec of send to blocked": {msg: &execMsg, ctx: ctx, expErr: true},
+		"deliver tx is not restricted":  {msg: &banktypes.MsgSend{FromAddress: blocked.String(), ToAddress: other.String()}, ctx: ctx.WithIsCheckTx(false)},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			_, err := anteHandler(tc.ctx, mockFeeTx{msgs: []sdk.Msg{tc.msg}}, false)
+			if tc.expErr {
+				require.ErrorIs(t, err, sdkerrors.ErrUnauthorized)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestLoadAddressPolicyFile(t *testing.T) {
+	bech32Addr := sdk.AccAddress([]byte("blocked_address_____"))
+	hexAddr := common.HexToAddress("0x00000000000000000000000000000000deadbeef")
+
+	path := filepath.Join(t.TempDir(), "policy.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{"blocked_addresses":["`+bech32Addr.String()+`","`+hexAddr.Hex()+`"]}`), 0o600))
+
+	blocked, err := LoadAddressPolicyFile(path)
+	require.NoError(t, err)
+	require.Equal(t, []sdk.AccAddress{bech32Addr, hexAddr.Bytes()}, blocked)
+
+	require.NoError(t, os.WriteFile(path, []byte(`{"blocked_addresses":["0xinvalid"]}`), 0o600))
+	_, err = LoadAddressPolicyFile(path)
+	require.Error(t, err)
+}
@@ -0,0 +1,136 @@
+package ante
+
+import (
+	"encoding/json"
+	"strings"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	sdkerrors "github.com/cosmos/cosmos-sdk/types/errors"
+	authtypes "github.com/cosmos/cosmos-sdk/x/auth/types"
+)
+
+// Cw20DenomPrefix prefixes the contract address of a CW20 token to form its fee denom, e.g. cw20/orai1...
+const Cw20DenomPrefix = "cw20/"
+
+// ContractKeeper specifies the interface that DeductFeeTokensDecorator requires to collect CW20 fee tokens
+type ContractKeeper interface {
+	Execute(ctx sdk.Context, contractAddress sdk.AccAddress, caller sdk.AccAddress, msg []byte, coins sdk.Coins) ([]byte, error)
+}
+
+type cw20TransferFrom struct {
+	TransferFrom struct {
+		Owner     string  `json:"owner"`
+		Recipient string  `json:"recipient"`
+		Amount    sdk.Int `json:"amount"`
+	} `json:"transfer_from"`
+}
+
+// Cw20Denom returns the fee denom of the CW20 token contract
+func Cw20Denom(contract sdk.AccAddress) string {
+	return Cw20DenomPrefix + contract.String()
+}
+
+// Cw20Contract returns the contract address of a CW20 fee denom, reporting whether denom is one
+func Cw20Contract(denom string) (sdk.AccAddress, bool, error) {
+	if !strings.HasPrefix(denom, Cw20DenomPrefix) {
+		return nil, false, nil
+	}
+	contract, err := sdk.AccAddressFromBech32(strings.TrimPrefix(denom, Cw20DenomPrefix))
+	return contract, true, err
+}
+
+// splitCw20Fee splits fee between its bank coins and its CW20 tokens
+func splitCw20Fee(fee sdk.Coins) (bankFee sdk.Coins, cw20Fee sdk.Coins) {
+	for _, coin := range fee {
+		if strings.HasPrefix(coin.Denom, Cw20DenomPrefix) {
+			cw20Fee = append(cw20Fee, coin)
+		} else {
+			bankFee = append(bankFee, coin)
+		}
+	}
+	return bankFee, cw20Fee
+}
+
+// cw20FeeTx overrides the fee of a tx with its bank coins
+type cw20FeeTx struct {
+	sdk.FeeTx
+	fee sdk.Coins
+}
+
+func (tx cw20FeeTx) GetFee() sdk.Coins { return tx.fee }
+
+var _ sdk.AnteDecorator = DeductFeeTokensDecorator{}
+
+// DeductFeeTokensDecorator escrows the fee of the tx into the fee collector. The whitelisted CW20 fee tokens, with a
+// cw20/<contract> denom, are collected from the fee payer by a transfer_from executed by the fee collector, which the
+// fee payer must have granted a CW20 allowance to. The bank coins of the fee, the whitelisted IBC fee tokens included,
+// are deducted by the wrapped decorator, which does not see the CW20 tokens.
+// CW20 fee tokens cannot be paid by a fee granter, as fee allowances are in bank coins.
+type DeductFeeTokensDecorator struct {
+	contractKeeper ContractKeeper
+	paramStore     ParamStore
+	deductFee      sdk.AnteDecorator
+}
+
+// NewDeductFeeTokensDecorator creates a decorator collecting the CW20 fee tokens and deducting the other fee coins
+// with deductFee
+func NewDeductFeeTokensDecorator(contractKeeper ContractKeeper, paramStore ParamStore, deductFee sdk.AnteDecorator) DeductFeeTokensDecorator {
+	return DeductFeeTokensDecorator{
+		contractKeeper: contractKeeper,
+		paramStore:     paramStore,
+		deductFee:      deductFee,
+	}
+}
+
+func (dftd DeductFeeTokensDecorator) AnteHandle(ctx sdk.Context, tx sdk.Tx, simulate bool, next sdk.AnteHandler) (newCtx sdk.Context, err error) {
+	feeTx, ok := tx.(sdk.FeeTx)
+	if !ok {
+		return ctx, sdkerrors.Wrap(sdkerrors.ErrTxDecode, "Tx must be a FeeTx")
+	}
+
+	bankFee, cw20Fee := splitCw20Fee(feeTx.GetFee())
+	if len(cw20Fee) == 0 {
+		return dftd.deductFee.AnteHandle(ctx, tx, simulate, next)
+	}
+	if feeTx.FeeGranter() != nil {
+		return ctx, sdkerrors.Wrap(sdkerrors.ErrInvalidRequest, "cw20 fee tokens cannot be paid by a fee granter")
+	}
+
+	params := dftd.paramStore.GetParams(ctx)
+	for _, coin := range cw20Fee {
+		if _, found := params.FeeToken(coin.Denom); !found {
+			return ctx, sdkerrors.Wrapf(sdkerrors.ErrInvalidCoins, "cw20 fee token %s is not whitelisted", coin.Denom)
+		}
+		if err := dftd.collectCw20(ctx, feeTx.FeePayer(), coin); err != nil {
+			return ctx, err
+		}
+	}
+
+	// the next decorators get the tx itself rather than the one holding the bank fee only
+	return dftd.deductFee.AnteHandle(ctx, cw20FeeTx{FeeTx: feeTx, fee: bankFee}, simulate, func(ctx sdk.Context, _ sdk.Tx, simulate bool) (sdk.Context, error) {
+		return next(ctx, tx, simulate)
+	})
+}
+
+// collectCw20 transfers the CW20 fee token from the fee payer to the fee collector
+func (dftd DeductFeeTokensDecorator) collectCw20(ctx sdk.Context, feePayer sdk.AccAddress, coin sdk.Coin) error {
+	contract, _, err := Cw20Contract(coin.Denom)
+	if err != nil {
+		return sdkerrors.Wrapf(sdkerrors.ErrInvalidCoins, "cw20 fee token %s: %s", coin.Denom, err)
+	}
+
+	feeCollector := authtypes.NewModuleAddress(authtypes.FeeCollectorName)
+	var msg cw20TransferFrom
+	msg.TransferFrom.Owner = feePayer.String()
+	msg.TransferFrom.Recipient = feeCollector.String()
+	msg.TransferFrom.Amount = coin.Amount
+	bz, err := json.Marshal(msg)
+	if err != nil {
+		return sdkerrors.Wrap(sdkerrors.ErrJSONMarshal, err.Error())
+	}
+
+	if _, err := dftd.contractKeeper.Execute(ctx, contract, feeCollector, bz, nil); err != nil {
+		return sdkerrors.Wrapf(sdkerrors.ErrInsufficientFunds, "cannot collect fee %s from %s: %s", coin, feePayer, err)
+	}
+	return nil
+}
@@ -0,0 +1,135 @@
+package ante
+
+import (
+	"errors"
+	"testing"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	sdkerrors "github.com/cosmos/cosmos-sdk/types/errors"
+	authtypes "github.com/cosmos/cosmos-sdk/x/auth/types"
+	banktypes "github.com/cosmos/cosmos-sdk/x/bank/types"
+	"github.com/stretchr/testify/require"
+)
+
+type execution struct {
+	contract sdk.AccAddress
+	caller   sdk.AccAddress
+	msg      string
+}
+
+// mockContractKeeper records the executions of the CW20 fee tokens
+type mockContractKeeper struct {
+	executions []execution
+	err        error
+}
+
+func (m *mockContractKeeper) Execute(_ sdk.Context, contract sdk.AccAddress, caller sdk.AccAddress, msg []byte, _ sdk.Coins) ([]byte, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	m.executions = append(m.executions, execution{contract: contract, caller: caller, msg: string(msg)})
+	return nil, nil
+}
+
+// mockDeductFee records the fee it deducts
+type mockDeductFee struct {
+	fee sdk.Coins
+}
+
+func (m *mockDeductFee) AnteHandle(ctx sdk.Context, tx sdk.Tx, simulate bool, next sdk.AnteHandler) (sdk.Context, error) {
+	m.fee = tx.(sdk.FeeTx).GetFee()
+	return next(ctx, tx, simulate)
+}
+
+func TestDeductFeeTokensDecorator(t *testing.T) {
+	token := sdk.AccAddress("cw20_token__________")
+	cw20Denom := Cw20Denom(token)
+	unknownDenom := Cw20Denom(sdk.AccAddress("unknown_token_______"))
+	payer := sdk.AccAddress("payer_______________")
+	feeCollector := authtypes.NewModuleAddress(authtypes.FeeCollectorName)
+
+	params := DefaultParams()
+	params.FeeTokens = []FeeToken{{Denom: cw20Denom, Rate: sdk.NewDecWithPrec(5, 1)}}
+	ctx, paramStore := setupParamStore(t, params)
+
+	cases := map[string]struct {
+		tx            mockFeeTx
+		contractErr   error
+		expErr        *sdkerrors.Error
+		expExecutions []execution
+		expDeducted   sdk.Coins
+	}{
+		"bank fee is deducted as is": {
+			tx:          mockFeeTx{fee: sdk.NewCoins(sdk.NewInt64Coin(nativeDenom, 10), sdk.NewInt64Coin(ibcDenom, 5)), payer: payer},
+			expDeducted: sdk.NewCoins(sdk.NewInt64Coin(nativeDenom, 10), sdk.NewInt64Coin(ibcDenom, 5)),
+		},
+		"cw20 fee is transferred to the fee collector": {
+			tx: mockFeeTx{fee: sdk.NewCoins(sdk.NewInt64Coin(cw20Denom, 100)), payer: payer},
+			expExecutions: []execution{{
+				contract: token,
+				caller:   feeCollector,
+				msg:      `{"transfer_from":{"owner":"` + payer.String() + `","recipient":"` + feeCollector.String() + `","amount":"100"}}`,
+			}},
+		},
+		"bank fee is deducted along with the cw20 fee": {
+			tx:            mockFeeTx{fee: sdk.NewCoins(sdk.NewInt64Coin(cw20Denom, 100), sdk.NewInt64Coin(nativeDenom, 10)), payer: payer},
+			expExecutions: []execution{{contract: token, caller: feeCollector}},
+			expDeducted:   sdk.NewCoins(sdk.NewInt64Coin(nativeDenom, 10)),
+		},
+		"cw20 fee not whitelisted": {
+			tx:     mockFeeTx{fee: sdk.NewCoins(sdk.NewInt64Coin(unknownDenom, 100)), payer: payer},
+			expErr: sdkerrors.ErrInvalidCoins,
+		},
+		"cw20 fee paid by a granter": {
+			tx:     mockFeeTx{fee: sdk.NewCoins(sdk.NewInt64Coin(cw20Denom, 100)), payer: payer, granter: sdk.AccAddress("granter_____________")},
+			expErr: sdkerrors.ErrInvalidRequest,
+		},
+		"cw20 transfer failure": {
+			tx:          mockFeeTx{fee: sdk.NewCoins(sdk.NewInt64Coin(cw20Denom, 100)), payer: payer},
+			contractErr: errors.New("no allowance for this account"),
+			expErr:      sdkerrors.ErrInsufficientFunds,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			contractKeeper := &mockContractKeeper{err: tc.contractErr}
+			deductFee := &mockDeductFee{}
+			decorator := NewDeductFeeTokensDecorator(contractKeeper, paramStore, deductFee)
+
+			tc.tx.msgs = []sdk.Msg{&banktypes.MsgSend{}}
+			var nextTx sdk.Tx
+			_, err := decorator.AnteHandle(ctx, tc.tx, false, func(ctx sdk.Context, tx sdk.Tx, _ bool) (sdk.Context, error) {
+				nextTx = tx
+				return ctx, nil
+			})
+			if tc.expErr != nil {
+				require.ErrorIs(t, err, tc.expErr)
+				require.Nil(t, nextTx)
+				return
+			}
+			require.NoError(t, err)
+
+			// the next decorators get the tx with its whole fee
+			require.Equal(t, tc.tx, nextTx)
+			require.Equal(t, tc.expDeducted, deductFee.fee)
+			require.Len(t, contractKeeper.executions, len(tc.expExecutions))
+			for i, exp := range tc.expExecutions {
+				require.Equal(t, exp.contract, contractKeeper.executions[i].contract)
+				require.Equal(t, exp.caller, contractKeeper.executions[i].caller)
+				if exp.msg != "" {
+					require.JSONEq(t, exp.msg, contractKeeper.executions[i].msg)
+				}
+			}
+		})
+	}
+}
+
+func TestCw20FeeTokenParams(t *testing.T) {
+	params := DefaultParams()
+	params.FeeTokens = []FeeToken{{Denom: Cw20Denom(sdk.AccAddress("cw20_token__________")), Rate: sdk.OneDec()}}
+	require.NoError(t, params.Validate())
+
+	params.FeeTokens = []FeeToken{{Denom: Cw20DenomPrefix + "orai1invalid", Rate: sdk.OneDec()}}
+	require.Error(t, params.Validate())
+}
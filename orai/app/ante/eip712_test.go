@@ -0,0 +1,120 @@
+package ante
+
+import (
+	"testing"
+
+	codectypes "github.com/cosmos/cosmos-sdk/codec/types"
+	"github.com/cosmos/cosmos-sdk/simapp"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/types/tx/signing"
+	authsigning "github.com/cosmos/cosmos-sdk/x/auth/signing"
+	authtx "github.com/cosmos/cosmos-sdk/x/auth/tx"
+	banktypes "github.com/cosmos/cosmos-sdk/x/bank/types"
+	ethcrypto "github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/require"
+	"github.com/tharsis/ethermint/crypto/ethsecp256k1"
+	"github.com/tharsis/ethermint/ethereum/eip712"
+	ethermint "github.com/tharsis/ethermint/types"
+	evmtypes "github.com/tharsis/ethermint/x/evm/types"
+)
+
+const eip712ChainID = "oraitest_9999-1"
+
+// eip712Params allows bank sends to be signed with EIP712
+func eip712Params() evmtypes.Params {
+	params := evmtypes.DefaultParams()
+	params.EIP712AllowedMsgs = []evmtypes.EIP712AllowedMsg{{
+		MsgTypeUrl:       "/cosmos.bank.v1beta1.MsgSend",
+		MsgValueTypeName: "MsgValueSend",
+		ValueTypes: []evmtypes.EIP712MsgAttrType{
+			{Name: "from_address", Type: "string"},
+			{Name: "to_address", Type: "string"},
+			{Name: "amount", Type: "Coin[]"},
+		},
+	}}
+	return params
+}
+
+// eip712Tx builds an EIP712 tx of the fee payer key, with typed data signed by signer
+func eip712Tx(t *testing.T, payerKey *ethsecp256k1.PrivKey, granter sdk.AccAddress, sign func(typedData []byte) []byte) (authsigning.Tx, authsigning.SignerData) {
+	encCfg := simapp.MakeTestEncodingConfig()
+	ethermint.RegisterInterfaces(encCfg.InterfaceRegistry)
+
+	payer := sdk.AccAddress(payerKey.PubKey().Address())
+	builder := encCfg.TxConfig.NewTxBuilder()
+	require.NoError(t, builder.SetMsgs(banktypes.NewMsgSend(payer, payer, sdk.NewCoins(sdk.NewInt64Coin(nativeDenom, 1)))))
+	builder.SetFeeAmount(sdk.NewCoins(sdk.NewInt64Coin(nativeDenom, 100)))
+	builder.SetGasLimit(200000)
+	builder.SetFeeGranter(granter)
+	require.NoError(t, builder.SetSignatures(signing.SignatureV2{
+		PubKey:   payerKey.PubKey(),
+		Data:     &signing.SingleSignatureData{SignMode: signing.SignMode_SIGN_MODE_LEGACY_AMINO_JSON},
+		Sequence: 3,
+	}))
+
+	signerData := authsigning.SignerData{ChainID: eip712ChainID, AccountNumber: 7, Sequence: 3}
+	typedData, err := Eip712TypedData(signerData, builder.GetTx(), payer, 9999, eip712Params())
+	require.NoError(t, err)
+	sigHash, err := eip712.ComputeTypedDataHash(typedData)
+	require.NoError(t, err)
+
+	extOpt, err := codectypes.NewAnyWithValue(&ethermint.ExtensionOptionsWeb3Tx{
+		TypedDataChainID: 9999,
+		FeePayer:         payer.String(),
+		FeePayerSig:      sign(sigHash),
+	})
+	require.NoError(t, err)
+	builder.(authtx.ExtensionOptionsTxBuilder).SetExtensionOptions(extOpt)
+
+	return builder.GetTx(), signerData
+}
+
+func TestVerifyEip712SignatureFeeGranter(t *testing.T) {
+	payerKey, err := ethsecp256k1.GenerateKey()
+	require.NoError(t, err)
+	otherKey, err := ethsecp256k1.GenerateKey()
+	require.NoError(t, err)
+	granter := sdk.AccAddress(otherKey.PubKey().Address())
+
+	signWith := func(key *ethsecp256k1.PrivKey) func([]byte) []byte {
+		return func(hash []byte) []byte {
+			ecdsaKey, err := key.ToECDSA()
+			require.NoError(t, err)
+			sig, err := ethcrypto.Sign(hash, ecdsaKey)
+			require.NoError(t, err)
+			return sig
+		}
+	}
+
+	verify := func(tx authsigning.Tx, signerData authsigning.SignerData) error {
+		sigs, err := tx.GetSignaturesV2()
+		require.NoError(t, err)
+		return VerifyEip712Signature(payerKey.PubKey(), signerData, sigs[0].Data, tx, eip712Params())
+	}
+
+	// without granter, the typed data is the one of ethermint
+	tx, signerData := eip712Tx(t, payerKey, nil, signWith(payerKey))
+	require.NoError(t, verify(tx, signerData))
+
+	// the granter is part of the signed typed data
+	tx, signerData = eip712Tx(t, payerKey, granter, signWith(payerKey))
+	require.Equal(t, granter, tx.FeeGranter())
+	require.NoError(t, verify(tx, signerData))
+
+	typedData, err := Eip712TypedData(signerData, tx, tx.FeePayer(), 9999, eip712Params())
+	require.NoError(t, err)
+	require.Equal(t, granter.String(), typedData.Message["fee"].(map[string]interface{})["granter"])
+
+	// a granter added after signing is rejected
+	var unsignedSig []byte
+	eip712Tx(t, payerKey, nil, func(hash []byte) []byte {
+		unsignedSig = signWith(payerKey)(hash)
+		return unsignedSig
+	})
+	tx, signerData = eip712Tx(t, payerKey, granter, func([]byte) []byte { return unsignedSig })
+	require.Error(t, verify(tx, signerData))
+
+	// only the fee payer can sign
+	tx, signerData = eip712Tx(t, payerKey, granter, signWith(otherKey))
+	require.Error(t, verify(tx, signerData))
+}
@@ -0,0 +1,13 @@
+package ante
+
+import (
+	sdkerrors "github.com/cosmos/cosmos-sdk/types/errors"
+)
+
+// ante errors
+var (
+	ErrTxGasCapExceeded  = sdkerrors.Register(SubspaceName, 2, "tx gas limit exceeds the maximum gas per tx")
+	ErrMsgGasCapExceeded = sdkerrors.Register(SubspaceName, 3, "tx gas limit exceeds the maximum gas for msg type")
+	// ErrAccountGasCapExceeded is returned when the gas limit of a tx exceeds the cap of its fee payer
+	ErrAccountGasCapExceeded = sdkerrors.Register(SubspaceName, 4, "tx gas limit exceeds the maximum gas for account")
+)
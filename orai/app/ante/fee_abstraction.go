@@ -0,0 +1,134 @@
+package ante
+
+import (
+	"encoding/json"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	sdkerrors "github.com/cosmos/cosmos-sdk/types/errors"
+)
+
+var _ sdk.AnteDecorator = FeeAbstractionDecorator{}
+
+// WasmKeeper specifies the interface that FeeAbstractionDecorator requires to query price contracts
+type WasmKeeper interface {
+	QuerySmart(ctx sdk.Context, contractAddr sdk.AccAddress, req []byte) ([]byte, error)
+}
+
+type effectiveFeeKey struct{}
+
+type priceQuery struct {
+	Price struct {
+		Denom string `json:"denom"`
+	} `json:"price"`
+}
+
+type priceResponse struct {
+	Rate sdk.Dec `json:"rate"`
+}
+
+// FeeAbstractionDecorator lets txs pay their fees in governance whitelisted denoms (e.g. IBC vouchers or CW20 tokens).
+// It converts the whitelisted fee coins to the native denom and stores the result in the context, so that
+// the min gas price checks compare the native value of the fee. The fee coins themselves are escrowed
+// as-is into the fee collector by the DeductFeeTokensDecorator.
+type FeeAbstractionDecorator struct {
+	wasmKeeper  WasmKeeper
+	paramStore  ParamStore
+	nativeDenom string
+}
+
+// NewFeeAbstractionDecorator creates a decorator converting whitelisted fee tokens to nativeDenom
+func NewFeeAbstractionDecorator(wasmKeeper WasmKeeper, paramStore ParamStore, nativeDenom string) FeeAbstractionDecorator {
+	return FeeAbstractionDecorator{
+		wasmKeeper:  wasmKeeper,
+		paramStore:  paramStore,
+		nativeDenom: nativeDenom,
+	}
+}
+
+func (fad FeeAbstractionDecorator) AnteHandle(ctx sdk.Context, tx sdk.Tx, simulate bool, next sdk.AnteHandler) (newCtx sdk.Context, err error) {
+	feeTx, ok := tx.(sdk.FeeTx)
+	if !ok {
+		return ctx, sdkerrors.Wrap(sdkerrors.ErrTxDecode, "Tx must be a FeeTx")
+	}
+
+	effectiveFee, converted, err := fad.ConvertFee(ctx, feeTx.GetFee())
+	if err != nil {
+		return ctx, err
+	}
+	if converted {
+		ctx = ctx.WithValue(effectiveFeeKey{}, effectiveFee)
+	}
+
+	return next(ctx, tx, simulate)
+}
+
+// ConvertFee converts the whitelisted fee tokens of fee to the native denom.
+// It reports whether any coin has been converted.
+func (fad FeeAbstractionDecorator) ConvertFee(ctx sdk.Context, fee sdk.Coins) (sdk.Coins, bool, error) {
+	params := fad.paramStore.GetParams(ctx)
+	if len(params.FeeTokens) == 0 {
+		return fee, false, nil
+	}
+
+	converted := false
+	effectiveFee := sdk.NewCoins()
+	for _, coin := range fee {
+		feeToken, found := params.FeeToken(coin.Denom)
+		if !found || coin.Denom == fad.nativeDenom {
+			effectiveFee = effectiveFee.Add(coin)
+			continue
+		}
+
+		rate, err := fad.conversionRate(ctx, feeToken)
+		if err != nil {
+			return nil, false, err
+		}
+		effectiveFee = effectiveFee.Add(sdk.NewCoin(fad.nativeDenom, rate.MulInt(coin.Amount).TruncateInt()))
+		converted = true
+	}
+
+	return effectiveFee, converted, nil
+}
+
+// conversionRate returns the amount of native denom worth one unit of the fee token
+func (fad FeeAbstractionDecorator) conversionRate(ctx sdk.Context, feeToken FeeToken) (sdk.Dec, error) {
+	if feeToken.PriceContract == "" {
+		return feeToken.Rate, nil
+	}
+
+	contractAddr, err := sdk.AccAddressFromBech32(feeToken.PriceContract)
+	if err != nil {
+		return sdk.Dec{}, sdkerrors.Wrapf(sdkerrors.ErrInvalidAddress, "price contract of fee token %s: %s", feeToken.Denom, err)
+	}
+
+	var query priceQuery
+	query.Price.Denom = feeToken.Denom
+	req, err := json.Marshal(query)
+	if err != nil {
+		return sdk.Dec{}, sdkerrors.Wrap(sdkerrors.ErrJSONMarshal, err.Error())
+	}
+
+	bz, err := fad.wasmKeeper.QuerySmart(ctx, contractAddr, req)
+	if err != nil {
+		return sdk.Dec{}, sdkerrors.Wrapf(sdkerrors.ErrInvalidRequest, "cannot query price of fee token %s: %s", feeToken.Denom, err)
+	}
+
+	var res priceResponse
+	if err := json.Unmarshal(bz, &res); err != nil {
+		return sdk.Dec{}, sdkerrors.Wrapf(sdkerrors.ErrJSONUnmarshal, "invalid price of fee token %s: %s", feeToken.Denom, err)
+	}
+	if res.Rate.IsNil() || !res.Rate.IsPositive() {
+		return sdk.Dec{}, sdkerrors.Wrapf(sdkerrors.ErrInvalidRequest, "invalid price of fee token %s: %s", feeToken.Denom, res.Rate)
+	}
+
+	return res.Rate, nil
+}
+
+// EffectiveFee returns the fee of the tx with the whitelisted fee tokens converted to the native denom.
+// It falls back to the fee of the tx when FeeAbstractionDecorator did not convert anything.
+func EffectiveFee(ctx sdk.Context, feeTx sdk.FeeTx) sdk.Coins {
+	if fee, ok := ctx.Value(effectiveFeeKey{}).(sdk.Coins); ok {
+		return fee
+	}
+	return feeTx.GetFee()
+}
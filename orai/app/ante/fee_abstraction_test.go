@@ -0,0 +1,185 @@
+package ante
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	sdkerrors "github.com/cosmos/cosmos-sdk/types/errors"
+	banktypes "github.com/cosmos/cosmos-sdk/x/bank/types"
+	"github.com/stretchr/testify/require"
+)
+
+const ibcDenom = "ibc/C458B4CC4F5581388B9ACB40774FDFBCEDC77A7F7CDFB112B469794AF86C4A69"
+
+var priceContract = sdk.AccAddress([]byte("price_contract______")).String()
+
+// mockPriceContract answers the price queries of the fee abstraction
+type mockPriceContract struct {
+	rates   map[string]string
+	err     error
+	queries int
+}
+
+func (m *mockPriceContract) QuerySmart(_ sdk.Context, contractAddr sdk.AccAddress, req []byte) ([]byte, error) {
+	m.queries++
+	if m.err != nil {
+		return nil, m.err
+	}
+	if contractAddr.String() != priceContract {
+		return nil, errors.New("unknown contract")
+	}
+
+	var query priceQuery
+	if err := json.Unmarshal(req, &query); err != nil {
+		return nil, err
+	}
+	rate, ok := m.rates[query.Price.Denom]
+	if !ok {
+		return nil, errors.New("no price for denom")
+	}
+	return []byte(`{"rate":"` + rate + `"}`), nil
+}
+
+// runFeeAbstraction runs the fee abstraction decorator and returns the fee seen by the next decorator
+func runFeeAbstraction(ctx sdk.Context, decorator FeeAbstractionDecorator, tx mockFeeTx) (sdk.Coins, error) {
+	var effectiveFee sdk.Coins
+	_, err := decorator.AnteHandle(ctx, tx, false, func(ctx sdk.Context, tx sdk.Tx, _ bool) (sdk.Context, error) {
+		effectiveFee = EffectiveFee(ctx, tx.(sdk.FeeTx))
+		return ctx, nil
+	})
+	return effectiveFee, err
+}
+
+func TestFeeAbstractionDecorator(t *testing.T) {
+	params := DefaultParams()
+	cw20Denom := Cw20Denom(sdk.AccAddress("cw20_token__________"))
+	params.FeeTokens = []FeeToken{
+		{Denom: ibcDenom, PriceContract: priceContract},
+		{Denom: "ibc/TWAP", Rate: sdk.NewDecWithPrec(25, 1)},
+		{Denom: cw20Denom, PriceContract: priceContract},
+	}
+
+	cases := map[string]struct {
+		fee       sdk.Coins
+		contract  *mockPriceContract
+		expFee    sdk.Coins
+		expErr    *sdkerrors.Error
+		expQuery  int
+		unchanged bool
+	}{
+		"native fee is untouched": {
+			fee:       sdk.NewCoins(sdk.NewInt64Coin(nativeDenom, 100)),
+			contract:  &mockPriceContract{},
+			expFee:    sdk.NewCoins(sdk.NewInt64Coin(nativeDenom, 100)),
+			unchanged: true,
+		},
+		"non whitelisted denom is untouched": {
+			fee:       sdk.NewCoins(sdk.NewInt64Coin("ibc/UNKNOWN", 100)),
+			contract:  &mockPriceContract{},
+			expFee:    sdk.NewCoins(sdk.NewInt64Coin("ibc/UNKNOWN", 100)),
+			unchanged: true,
+		},
+		"price contract rate": {
+			fee:      sdk.NewCoins(sdk.NewInt64Coin(ibcDenom, 1000)),
+			contract: &mockPriceContract{rates: map[string]string{ibcDenom: "0.5"}},
+			expFee:   sdk.NewCoins(sdk.NewInt64Coin(nativeDenom, 500)),
+			expQuery: 1,
+		},
+		"price contract amount is truncated": {
+			fee:      sdk.NewCoins(sdk.NewInt64Coin(ibcDenom, 3)),
+			contract: &mockPriceContract{rates: map[string]string{ibcDenom: "0.5"}},
+			expFee:   sdk.NewCoins(sdk.NewInt64Coin(nativeDenom, 1)),
+			expQuery: 1,
+		},
+		"fixed rate": {
+			fee:      sdk.NewCoins(sdk.NewInt64Coin("ibc/TWAP", 100)),
+			contract: &mockPriceContract{},
+			expFee:   sdk.NewCoins(sdk.NewInt64Coin(nativeDenom, 250)),
+		},
+		"cw20 token": {
+			fee:      sdk.NewCoins(sdk.NewInt64Coin(cw20Denom, 100)),
+			contract: &mockPriceContract{rates: map[string]string{cw20Denom: "3"}},
+			expFee:   sdk.NewCoins(sdk.NewInt64Coin(nativeDenom, 300)),
+			expQuery: 1,
+		},
+		"mixed native and whitelisted fee": {
+			fee:      sdk.NewCoins(sdk.NewInt64Coin(nativeDenom, 10), sdk.NewInt64Coin(ibcDenom, 100), sdk.NewInt64Coin("ibc/TWAP", 100)),
+			contract: &mockPriceContract{rates: map[string]string{ibcDenom: "2"}},
+			expFee:   sdk.NewCoins(sdk.NewInt64Coin(nativeDenom, 460)),
+			expQuery: 1,
+		},
+		"price contract failure": {
+			fee:      sdk.NewCoins(sdk.NewInt64Coin(ibcDenom, 100)),
+			contract: &mockPriceContract{err: errors.New("contract panicked")},
+			expErr:   sdkerrors.ErrInvalidRequest,
+			expQuery: 1,
+		},
+		"price contract returns a non positive rate": {
+			fee:      sdk.NewCoins(sdk.NewInt64Coin(ibcDenom, 100)),
+			contract: &mockPriceContract{rates: map[string]string{ibcDenom: "0"}},
+			expErr:   sdkerrors.ErrInvalidRequest,
+			expQuery: 1,
+		},
+		"price contract returns garbage": {
+			fee:      sdk.NewCoins(sdk.NewInt64Coin(ibcDenom, 100)),
+			contract: &mockPriceContract{rates: map[string]string{ibcDenom: `"}`}},
+			expErr:   sdkerrors.ErrJSONUnmarshal,
+			expQuery: 1,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			ctx, paramStore := setupParamStore(t, params)
+			decorator := NewFeeAbstractionDecorator(tc.contract, paramStore, nativeDenom)
+			tx := mockFeeTx{msgs: []sdk.Msg{&banktypes.MsgSend{}}, fee: tc.fee, gas: 100000}
+
+			effectiveFee, err := runFeeAbstraction(ctx, decorator, tx)
+			require.Equal(t, tc.expQuery, tc.contract.queries)
+			if tc.expErr != nil {
+				require.ErrorIs(t, err, tc.expErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.expFee, effectiveFee)
+			if tc.unchanged {
+				require.Equal(t, tx.GetFee(), effectiveFee)
+			}
+		})
+	}
+}
+
+func TestFeeAbstractionMinGasPrices(t *testing.T) {
+	params := DefaultParams()
+	params.FeeTokens = []FeeToken{{Denom: ibcDenom, PriceContract: priceContract}}
+	params.DefaultMinGasPrices = sdk.NewDecCoins(sdk.NewDecCoinFromDec(nativeDenom, sdk.NewDecWithPrec(1, 2)))
+	ctx, paramStore := setupParamStore(t, params)
+	ctx = ctx.WithIsCheckTx(true).WithMinGasPrices(sdk.NewDecCoins(sdk.NewDecCoinFromDec(nativeDenom, sdk.NewDecWithPrec(1, 3))))
+
+	contract := &mockPriceContract{rates: map[string]string{ibcDenom: "0.5"}}
+	anteHandler := sdk.ChainAnteDecorators(
+		NewFeeAbstractionDecorator(contract, paramStore, nativeDenom),
+		NewMempoolFeeDecorator(),
+		NewMsgMinGasPriceDecorator(mockEVMKeeper{}, paramStore),
+	)
+
+	// 100000 gas at 0.01orai requires 1000orai, i.e. 2000 units of the ibc denom
+	tx := mockFeeTx{msgs: []sdk.Msg{&banktypes.MsgSend{}}, gas: 100000}
+
+	_, err := anteHandler(ctx, tx.WithFee(sdk.NewCoins(sdk.NewInt64Coin(ibcDenom, 2000))), false)
+	require.NoError(t, err)
+
+	_, err = anteHandler(ctx, tx.WithFee(sdk.NewCoins(sdk.NewInt64Coin(ibcDenom, 1999))), false)
+	require.ErrorIs(t, err, sdkerrors.ErrInsufficientFee)
+
+	// the node's min-gas-prices alone would accept it
+	_, err = anteHandler(ctx, tx.WithFee(sdk.NewCoins(sdk.NewInt64Coin(ibcDenom, 200))), false)
+	require.ErrorIs(t, err, sdkerrors.ErrInsufficientFee)
+	require.Contains(t, err.Error(), "consensus minimum")
+
+	// not whitelisted denoms are not valued in orai
+	_, err = anteHandler(ctx, tx.WithFee(sdk.NewCoins(sdk.NewInt64Coin("ibc/UNKNOWN", 1000000))), false)
+	require.ErrorIs(t, err, sdkerrors.ErrInsufficientFee)
+}
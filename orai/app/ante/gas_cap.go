@@ -0,0 +1,66 @@
+package ante
+
+import (
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	sdkerrors "github.com/cosmos/cosmos-sdk/types/errors"
+)
+
+var _ sdk.AnteDecorator = GasCapDecorator{}
+
+// GasCapDecorator bounds the gas limit of Cosmos txs. It enforces the consensus-level MaxTxGas,
+// AccountGasCaps and MsgGasCaps ante params in CheckTx and DeliverTx, and the node's max-tx-gas-wanted
+// in CheckTx, like the EVM ante handler does for Ethereum txs. The gas cap of the fee payer, if any,
+// replaces MaxTxGas.
+type GasCapDecorator struct {
+	paramStore     ParamStore
+	maxTxGasWanted uint64
+}
+
+// NewGasCapDecorator creates a decorator bounding the gas limit of txs.
+// A zero maxTxGasWanted disables the node-local limit.
+func NewGasCapDecorator(paramStore ParamStore, maxTxGasWanted uint64) GasCapDecorator {
+	return GasCapDecorator{
+		paramStore:     paramStore,
+		maxTxGasWanted: maxTxGasWanted,
+	}
+}
+
+func (gcd GasCapDecorator) AnteHandle(ctx sdk.Context, tx sdk.Tx, simulate bool, next sdk.AnteHandler) (newCtx sdk.Context, err error) {
+	feeTx, ok := tx.(sdk.FeeTx)
+	if !ok {
+		return ctx, sdkerrors.Wrap(sdkerrors.ErrTxDecode, "Tx must be a FeeTx")
+	}
+
+	// simulations are bounded by the wasm simulation gas limit instead
+	if simulate {
+		return next(ctx, tx, simulate)
+	}
+
+	gas := feeTx.GetGas()
+	if ctx.IsCheckTx() && gcd.maxTxGasWanted != 0 && gas > gcd.maxTxGasWanted {
+		return ctx, sdkerrors.Wrapf(ErrTxGasCapExceeded, "gas limit %d, node max-tx-gas-wanted %d", gas, gcd.maxTxGasWanted)
+	}
+
+	params := gcd.paramStore.GetParams(ctx)
+	if maxGas, found := params.GasCapForAccount(feeTx.FeePayer()); found {
+		if gas > maxGas {
+			return ctx, sdkerrors.Wrapf(ErrAccountGasCapExceeded, "gas limit %d, max gas %d for %s", gas, maxGas, feeTx.FeePayer())
+		}
+	} else if params.MaxTxGas != 0 && gas > params.MaxTxGas {
+		return ctx, sdkerrors.Wrapf(ErrTxGasCapExceeded, "gas limit %d, max tx gas %d", gas, params.MaxTxGas)
+	}
+
+	if len(params.MsgGasCaps) > 0 {
+		typeURLs, err := msgTypeURLs(tx.GetMsgs())
+		if err != nil {
+			return ctx, err
+		}
+		for _, typeURL := range typeURLs {
+			if maxGas, found := params.GasCapForMsg(typeURL); found && gas > maxGas {
+				return ctx, sdkerrors.Wrapf(ErrMsgGasCapExceeded, "gas limit %d, max gas %d for %s", gas, maxGas, typeURL)
+			}
+		}
+	}
+
+	return next(ctx, tx, simulate)
+}
@@ -0,0 +1,173 @@
+package ante
+
+import (
+	"testing"
+
+	wasmtypes "github.com/CosmWasm/wasmd/x/wasm/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	sdkerrors "github.com/cosmos/cosmos-sdk/types/errors"
+	"github.com/cosmos/cosmos-sdk/x/authz"
+	banktypes "github.com/cosmos/cosmos-sdk/x/bank/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGasCapDecorator(t *testing.T) {
+	params := DefaultParams()
+	params.MaxTxGas = 10_000_000
+	params.MsgGasCaps = []MsgGasCap{{MsgTypeURL: sdk.MsgTypeURL(&banktypes.MsgSend{}), MaxGas: 200_000}}
+	relayer, limited := sdk.AccAddress("relayer_____________"), sdk.AccAddress("limited_____________")
+	params.AccountGasCaps = []AccountGasCap{
+		{Address: relayer.String(), MaxGas: 50_000_000},
+		{Address: limited.String(), MaxGas: 1_000_000},
+	}
+	ctx, paramStore := setupParamStore(t, params)
+
+	send, execute := &banktypes.MsgSend{}, &wasmtypes.MsgExecuteContract{}
+	execMsg := authz.NewMsgExec(sdk.AccAddress("grantee_____________"), []sdk.Msg{send})
+
+	cases := map[string]struct {
+		msgs           []sdk.Msg
+		gas            uint64
+		payer          sdk.AccAddress
+		maxTxGasWanted uint64
+		checkTx        bool
+		simulate       bool
+		expErr         *sdkerrors.Error
+	}{
+		"below max tx gas": {
+			msgs: []sdk.Msg{execute},
+			gas:  10_000_000,
+		},
+		"above max tx gas": {
+			msgs:   []sdk.Msg{execute},
+			gas:    10_000_001,
+			expErr: ErrTxGasCapExceeded,
+		},
+		"account gas cap above max tx gas": {
+			msgs:  []sdk.Msg{execute},
+			gas:   50_000_000,
+			payer: relayer,
+		},
+		"above account gas cap": {
+			msgs:   []sdk.Msg{execute},
+			gas:    50_000_001,
+			payer:  relayer,
+			expErr: ErrAccountGasCapExceeded,
+		},
+		"account gas cap below max tx gas": {
+			msgs:   []sdk.Msg{execute},
+			gas:    1_000_001,
+			payer:  limited,
+			expErr: ErrAccountGasCapExceeded,
+		},
+		"msg gas cap applies along with the account gas cap": {
+			msgs:   []sdk.Msg{send},
+			gas:    200_001,
+			payer:  relayer,
+			expErr: ErrMsgGasCapExceeded,
+		},
+		"node max tx gas wanted applies along with the account gas cap": {
+			msgs:           []sdk.Msg{execute},
+			gas:            5_000_001,
+			payer:          relayer,
+			maxTxGasWanted: 5_000_000,
+			checkTx:        true,
+			expErr:         ErrTxGasCapExceeded,
+		},
+		"below msg gas cap": {
+			msgs: []sdk.Msg{send},
+			gas:  200_000,
+		},
+		"above msg gas cap": {
+			msgs:   []sdk.Msg{execute, send},
+			gas:    200_001,
+			expErr: ErrMsgGasCapExceeded,
+		},
+		"msg gas cap of the msgs of authz exec": {
+			msgs:   []sdk.Msg{&execMsg},
+			gas:    200_001,
+			expErr: ErrMsgGasCapExceeded,
+		},
+		"node max tx gas wanted in check tx": {
+			msgs:           []sdk.Msg{execute},
+			gas:            5_000_001,
+			maxTxGasWanted: 5_000_000,
+			checkTx:        true,
+			expErr:         ErrTxGasCapExceeded,
+		},
+		"below node max tx gas wanted": {
+			msgs:           []sdk.Msg{execute},
+			gas:            5_000_000,
+			maxTxGasWanted: 5_000_000,
+			checkTx:        true,
+		},
+		"node max tx gas wanted is ignored in deliver tx": {
+			msgs:           []sdk.Msg{execute},
+			gas:            5_000_001,
+			maxTxGasWanted: 5_000_000,
+		},
+		"max tx gas is enforced in check tx": {
+			msgs:    []sdk.Msg{execute},
+			gas:     10_000_001,
+			checkTx: true,
+			expErr:  ErrTxGasCapExceeded,
+		},
+		"skipped in simulation": {
+			msgs:           []sdk.Msg{send},
+			gas:            20_000_000,
+			maxTxGasWanted: 5_000_000,
+			checkTx:        true,
+			simulate:       true,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			decorator := NewGasCapDecorator(paramStore, tc.maxTxGasWanted)
+			called := false
+			_, err := decorator.AnteHandle(ctx.WithIsCheckTx(tc.checkTx), mockFeeTx{msgs: tc.msgs, gas: tc.gas, payer: tc.payer}, tc.simulate, func(ctx sdk.Context, _ sdk.Tx, _ bool) (sdk.Context, error) {
+				called = true
+				return ctx, nil
+			})
+			if tc.expErr != nil {
+				require.ErrorIs(t, err, tc.expErr)
+				require.False(t, called)
+				return
+			}
+			require.NoError(t, err)
+			require.True(t, called)
+		})
+	}
+}
+
+func TestGasCapErrorCodes(t *testing.T) {
+	// the codes are part of the tx results, changing them breaks the clients matching on them
+	require.Equal(t, SubspaceName, ErrTxGasCapExceeded.Codespace())
+	require.Equal(t, uint32(2), ErrTxGasCapExceeded.ABCICode())
+	require.Equal(t, SubspaceName, ErrMsgGasCapExceeded.Codespace())
+	require.Equal(t, uint32(3), ErrMsgGasCapExceeded.ABCICode())
+	require.Equal(t, SubspaceName, ErrAccountGasCapExceeded.Codespace())
+	require.Equal(t, uint32(4), ErrAccountGasCapExceeded.ABCICode())
+}
+
+func TestAccountGasCapParams(t *testing.T) {
+	account := sdk.AccAddress("account_____________")
+	params := DefaultParams()
+	params.AccountGasCaps = []AccountGasCap{{Address: account.String(), MaxGas: 1_000_000}}
+	require.NoError(t, params.Validate())
+
+	maxGas, found := params.GasCapForAccount(account)
+	require.True(t, found)
+	require.Equal(t, uint64(1_000_000), maxGas)
+	_, found = params.GasCapForAccount(sdk.AccAddress("other_______________"))
+	require.False(t, found)
+
+	for name, caps := range map[string][]AccountGasCap{
+		"invalid address": {{Address: "orai1invalid", MaxGas: 1_000_000}},
+		"zero max gas":    {{Address: account.String(), MaxGas: 0}},
+		"duplicate":       {{Address: account.String(), MaxGas: 1_000_000}, {Address: account.String(), MaxGas: 2_000_000}},
+	} {
+		params.AccountGasCaps = caps
+		require.Error(t, params.Validate(), name)
+	}
+}
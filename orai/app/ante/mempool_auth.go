@@ -0,0 +1,100 @@
+package ante
+
+import (
+	"sync"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	sdkerrors "github.com/cosmos/cosmos-sdk/types/errors"
+	evmtypes "github.com/tharsis/ethermint/x/evm/types"
+)
+
+var _ sdk.AnteDecorator = MempoolAuthDecorator{}
+
+// MempoolAllowlist is the node-local list of the addresses allowed to submit txs to the mempool.
+// It is safe for concurrent use and can be updated while the node is running.
+type MempoolAllowlist struct {
+	mu        sync.RWMutex
+	enabled   bool
+	addresses map[string]struct{}
+}
+
+// NewMempoolAllowlist creates an allowlist, which only restricts the mempool when enabled
+func NewMempoolAllowlist(enabled bool, addresses []sdk.AccAddress) *MempoolAllowlist {
+	allowlist := &MempoolAllowlist{}
+	allowlist.Update(enabled, addresses)
+	return allowlist
+}
+
+// Update replaces the allowlist
+func (l *MempoolAllowlist) Update(enabled bool, addresses []sdk.AccAddress) {
+	set := make(map[string]struct{}, len(addresses))
+	for _, addr := range addresses {
+		set[string(addr)] = struct{}{}
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.enabled = enabled
+	l.addresses = set
+}
+
+// Enabled returns true if the mempool only admits txs signed by the allowlist
+func (l *MempoolAllowlist) Enabled() bool {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return l.enabled
+}
+
+// IsAllowed returns true if the address may submit txs to the mempool
+func (l *MempoolAllowlist) IsAllowed(addr sdk.AccAddress) bool {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	if !l.enabled {
+		return true
+	}
+	_, found := l.addresses[string(addr)]
+	return found
+}
+
+// MempoolAuthDecorator only admits into the mempool the txs whose signers are all in the allowlist.
+// It only runs in CheckTx and ReCheckTx, so txs already in the mempool are evicted when the allowlist shrinks.
+// On the Ethereum path it must run after the signature verification, which sets the sender of the msgs.
+type MempoolAuthDecorator struct {
+	allowlist *MempoolAllowlist
+}
+
+// NewMempoolAuthDecorator creates a decorator restricting the mempool to the allowlist.
+// A nil allowlist admits every tx.
+func NewMempoolAuthDecorator(allowlist *MempoolAllowlist) MempoolAuthDecorator {
+	return MempoolAuthDecorator{
+		allowlist: allowlist,
+	}
+}
+
+func (mad MempoolAuthDecorator) AnteHandle(ctx sdk.Context, tx sdk.Tx, simulate bool, next sdk.AnteHandler) (newCtx sdk.Context, err error) {
+	if (!ctx.IsCheckTx() && !ctx.IsReCheckTx()) || simulate || mad.allowlist == nil || !mad.allowlist.Enabled() {
+		return next(ctx, tx, simulate)
+	}
+
+	for _, msg := range tx.GetMsgs() {
+		var signers []sdk.AccAddress
+		if ethMsg, ok := msg.(*evmtypes.MsgEthereumTx); ok {
+			// the sender has been recovered by the signature verification
+			from := ethMsg.GetFrom()
+			if from.Empty() {
+				return ctx, sdkerrors.Wrap(sdkerrors.ErrUnauthorized, "sender of ethereum tx is not set")
+			}
+			signers = []sdk.AccAddress{from}
+		} else {
+			signers = msg.GetSigners()
+		}
+
+		for _, signer := range signers {
+			if !mad.allowlist.IsAllowed(signer) {
+				return ctx, sdkerrors.Wrapf(sdkerrors.ErrUnauthorized, "signer %s is not authorized to submit txs to this node's mempool", signer)
+			}
+		}
+	}
+
+	return next(ctx, tx, simulate)
+}
@@ -0,0 +1,75 @@
+package ante
+
+import (
+	"testing"
+
+	"github.com/cosmos/cosmos-sdk/testutil"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	sdkerrors "github.com/cosmos/cosmos-sdk/types/errors"
+	banktypes "github.com/cosmos/cosmos-sdk/x/bank/types"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+	evmtypes "github.com/tharsis/ethermint/x/evm/types"
+)
+
+func TestMempoolAuthDecorator(t *testing.T) {
+	allowed := sdk.AccAddress([]byte("allowed_address_____"))
+	other := sdk.AccAddress([]byte("other_address_______"))
+
+	cosmosTx := func(signer sdk.AccAddress) sdk.Tx {
+		return mockFeeTx{msgs: []sdk.Msg{&banktypes.MsgSend{FromAddress: signer.String()}}}
+	}
+	ethTx := func(sender sdk.AccAddress) sdk.Tx {
+		msg := &evmtypes.MsgEthereumTx{}
+		if sender != nil {
+			msg.From = common.BytesToAddress(sender).Hex()
+		}
+		return mockFeeTx{msgs: []sdk.Msg{msg}}
+	}
+
+	key := sdk.NewKVStoreKey("test")
+	ctx := testutil.DefaultContext(key, sdk.NewTransientStoreKey("transient_test")).WithIsCheckTx(true)
+
+	allowlist := NewMempoolAllowlist(true, []sdk.AccAddress{allowed})
+	anteHandler := sdk.ChainAnteDecorators(NewMempoolAuthDecorator(allowlist))
+
+	cases := map[string]struct {
+		tx     sdk.Tx
+		ctx    sdk.Context
+		sim    bool
+		expErr bool
+	}{
+		"cosmos tx from allowed signer":       {tx: cosmosTx(allowed), ctx: ctx},
+		"cosmos tx from other signer":         {tx: cosmosTx(other), ctx: ctx, expErr: true},
+		"cosmos tx from other signer recheck": {tx: cosmosTx(other), ctx: ctx.WithIsReCheckTx(true), expErr: true},
+		"ethereum tx from allowed sender":     {tx: ethTx(allowed), ctx: ctx},
+		"ethereum tx from other sender":       {tx: ethTx(other), ctx: ctx, expErr: true},
+		"ethereum tx without sender":          {tx: ethTx(nil), ctx: ctx, expErr: true},
+		"deliver tx is not restricted":        {tx: cosmosTx(other), ctx: ctx.WithIsCheckTx(false)},
+		"simulation is not restricted":        {tx: ethTx(other), ctx: ctx, sim: true},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			_, err := anteHandler(tc.ctx, tc.tx, tc.sim)
+			if tc.expErr {
+				require.ErrorIs(t, err, sdkerrors.ErrUnauthorized)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+
+	// reloading the allowlist applies to the next txs
+	allowlist.Update(true, []sdk.AccAddress{other})
+	_, err := anteHandler(ctx, cosmosTx(allowed), false)
+	require.ErrorIs(t, err, sdkerrors.ErrUnauthorized)
+	_, err = anteHandler(ctx, ethTx(other), false)
+	require.NoError(t, err)
+
+	allowlist.Update(false, nil)
+	_, err = anteHandler(ctx, cosmosTx(allowed), false)
+	require.NoError(t, err)
+	_, err = anteHandler(ctx, ethTx(allowed), false)
+	require.NoError(t, err)
+}
@@ -0,0 +1,110 @@
+package ante
+
+import (
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	sdkerrors "github.com/cosmos/cosmos-sdk/types/errors"
+	"github.com/cosmos/cosmos-sdk/x/authz"
+)
+
+var _ sdk.AnteDecorator = MsgMinGasPriceDecorator{}
+
+// MsgMinGasPriceDecorator enforces the consensus-level minimum gas prices stored in the ante params.
+// Unlike the node's min-gas-prices, it is applied in DeliverTx as well as CheckTx, and the required
+// price depends on the msg types contained in the tx.
+type MsgMinGasPriceDecorator struct {
+	evmKeeper  EVMKeeper
+	paramStore ParamStore
+}
+
+// NewMsgMinGasPriceDecorator creates a decorator enforcing the per msg type minimum gas prices
+func NewMsgMinGasPriceDecorator(evmKeeper EVMKeeper, paramStore ParamStore) MsgMinGasPriceDecorator {
+	return MsgMinGasPriceDecorator{
+		evmKeeper:  evmKeeper,
+		paramStore: paramStore,
+	}
+}
+
+func (mgpd MsgMinGasPriceDecorator) AnteHandle(ctx sdk.Context, tx sdk.Tx, simulate bool, next sdk.AnteHandler) (newCtx sdk.Context, err error) {
+	feeTx, ok := tx.(sdk.FeeTx)
+	if !ok {
+		return ctx, sdkerrors.Wrap(sdkerrors.ErrTxDecode, "Tx must be a FeeTx")
+	}
+
+	// gentxs are delivered without fees during InitChain
+	if simulate || ctx.BlockHeight() == 0 {
+		return next(ctx, tx, simulate)
+	}
+
+	minGasPrices, err := mgpd.minGasPrices(ctx, tx.GetMsgs())
+	if err != nil {
+		return ctx, err
+	}
+
+	if !minGasPrices.IsZero() {
+		requiredFees := make(sdk.Coins, len(minGasPrices))
+
+		// fee = ceil(minGasPrice * gasLimit), same as the node's min-gas-prices check
+		glDec := sdk.NewDec(int64(feeTx.GetGas()))
+		for i, gp := range minGasPrices {
+			fee := gp.Amount.Mul(glDec)
+			requiredFees[i] = sdk.NewCoin(gp.Denom, fee.Ceil().RoundInt())
+		}
+
+		feeCoins := EffectiveFee(ctx, feeTx)
+		if !feeCoins.IsAnyGTE(requiredFees) {
+			return ctx, sdkerrors.Wrapf(sdkerrors.ErrInsufficientFee, "insufficient fees; got: %s consensus minimum required: %s", feeCoins, requiredFees)
+		}
+	}
+
+	return next(ctx, tx, simulate)
+}
+
+// minGasPrices returns, for each denom, the highest minimum gas price required by the msgs.
+// Msgs wrapped in authz MsgExec are priced by their inner msg types. The EvmDenom is filtered out
+// the same way EvmMinGasFilter does for the node's min-gas-prices.
+func (mgpd MsgMinGasPriceDecorator) minGasPrices(ctx sdk.Context, msgs []sdk.Msg) (sdk.DecCoins, error) {
+	params := mgpd.paramStore.GetParams(ctx)
+	evmDenom := mgpd.evmKeeper.GetParams(ctx).EvmDenom
+
+	typeURLs, err := msgTypeURLs(msgs)
+	if err != nil {
+		return nil, err
+	}
+
+	minGasPrices := sdk.NewDecCoins()
+	for _, typeURL := range typeURLs {
+		for _, gasPrice := range params.MinGasPricesForMsg(typeURL) {
+			if gasPrice.Denom == evmDenom {
+				continue
+			}
+			if current := minGasPrices.AmountOf(gasPrice.Denom); gasPrice.Amount.GT(current) {
+				minGasPrices = minGasPrices.Add(sdk.NewDecCoinFromDec(gasPrice.Denom, gasPrice.Amount.Sub(current)))
+			}
+		}
+	}
+
+	return minGasPrices, nil
+}
+
+// msgTypeURLs returns the type urls of the msgs, replacing authz MsgExec by the msgs it wraps
+func msgTypeURLs(msgs []sdk.Msg) ([]string, error) {
+	var typeURLs []string
+	for _, msg := range msgs {
+		execMsg, ok := msg.(*authz.MsgExec)
+		if !ok {
+			typeURLs = append(typeURLs, sdk.MsgTypeURL(msg))
+			continue
+		}
+
+		innerMsgs, err := execMsg.GetMessages()
+		if err != nil {
+			return nil, err
+		}
+		innerTypeURLs, err := msgTypeURLs(innerMsgs)
+		if err != nil {
+			return nil, err
+		}
+		typeURLs = append(typeURLs, innerTypeURLs...)
+	}
+	return typeURLs, nil
+}
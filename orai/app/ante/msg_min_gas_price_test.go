@@ -0,0 +1,202 @@
+package ante
+
+import (
+	"testing"
+
+	"github.com/cosmos/cosmos-sdk/codec"
+	"github.com/cosmos/cosmos-sdk/simapp"
+	"github.com/cosmos/cosmos-sdk/store"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	sdkerrors "github.com/cosmos/cosmos-sdk/types/errors"
+	"github.com/cosmos/cosmos-sdk/x/authz"
+	banktypes "github.com/cosmos/cosmos-sdk/x/bank/types"
+	paramtypes "github.com/cosmos/cosmos-sdk/x/params/types"
+	stakingtypes "github.com/cosmos/cosmos-sdk/x/staking/types"
+	"github.com/stretchr/testify/require"
+	"github.com/tendermint/tendermint/libs/log"
+	tmproto "github.com/tendermint/tendermint/proto/tendermint/types"
+	dbm "github.com/tendermint/tm-db"
+	evmtypes "github.com/tharsis/ethermint/x/evm/types"
+)
+
+const nativeDenom = "orai"
+
+type mockFeeTx struct {
+	msgs    []sdk.Msg
+	fee     sdk.Coins
+	gas     uint64
+	payer   sdk.AccAddress
+	granter sdk.AccAddress
+}
+
+func (tx mockFeeTx) GetMsgs() []sdk.Msg              { return tx.msgs }
+func (tx mockFeeTx) ValidateBasic() error            { return nil }
+func (tx mockFeeTx) GetGas() uint64                  { return tx.gas }
+func (tx mockFeeTx) GetFee() sdk.Coins               { return tx.fee }
+func (tx mockFeeTx) FeePayer() sdk.AccAddress        { return tx.payer }
+func (tx mockFeeTx) FeeGranter() sdk.AccAddress      { return tx.granter }
+func (tx mockFeeTx) WithFee(fee sdk.Coins) mockFeeTx { tx.fee = fee; return tx }
+
+type mockEVMKeeper struct{}
+
+func (mockEVMKeeper) GetParams(_ sdk.Context) evmtypes.Params {
+	return evmtypes.Params{EvmDenom: "aorai"}
+}
+
+// setupParamStore returns a context with the ante params set, and the extra transient stores mounted
+func setupParamStore(t *testing.T, params Params, tkeys ...sdk.StoreKey) (sdk.Context, ParamStore) {
+	key := sdk.NewKVStoreKey(paramtypes.StoreKey)
+	tkey := sdk.NewTransientStoreKey(paramtypes.TStoreKey)
+
+	cms := store.NewCommitMultiStore(dbm.NewMemDB())
+	cms.MountStoreWithDB(key, sdk.StoreTypeIAVL, nil)
+	cms.MountStoreWithDB(tkey, sdk.StoreTypeTransient, nil)
+	for _, k := range tkeys {
+		cms.MountStoreWithDB(k, sdk.StoreTypeTransient, nil)
+	}
+	require.NoError(t, cms.LoadLatestVersion())
+	ctx := sdk.NewContext(cms, tmproto.Header{Height: 1}, false, log.NewNopLogger())
+
+	encCfg := simapp.MakeTestEncodingConfig()
+	subspace := paramtypes.NewSubspace(encCfg.Marshaler, codec.NewLegacyAmino(), key, tkey, SubspaceName)
+	paramStore := NewParamStore(subspace)
+
+	require.NoError(t, params.Validate())
+	paramStore.SetParams(ctx, params)
+	return ctx, paramStore
+}
+
+func TestMsgMinGasPriceDecorator(t *testing.T) {
+	params := DefaultParams()
+	params.DefaultMinGasPrices = sdk.NewDecCoins(sdk.NewDecCoinFromDec(nativeDenom, sdk.NewDecWithPrec(1, 2)))
+	params.MsgMinGasPrices = []MsgMinGasPrice{
+		{
+			MsgTypeURL: sdk.MsgTypeURL(&banktypes.MsgSend{}),
+			MinGasPrices: sdk.NewDecCoins(
+				sdk.NewDecCoinFromDec(nativeDenom, sdk.NewDecWithPrec(5, 2)),
+				sdk.NewDecCoinFromDec("aorai", sdk.NewDec(1_000_000_000)),
+			),
+		},
+		{MsgTypeURL: sdk.MsgTypeURL(&stakingtypes.MsgDelegate{}), MinGasPrices: sdk.DecCoins{}},
+		{MsgTypeURL: sdk.MsgTypeURL(&stakingtypes.MsgUndelegate{}), MinGasPrices: sdk.NewDecCoins(sdk.NewDecCoin("aorai", sdk.NewInt(1)))},
+	}
+	ctx, paramStore := setupParamStore(t, params)
+	decorator := NewMsgMinGasPriceDecorator(mockEVMKeeper{}, paramStore)
+
+	addr := sdk.AccAddress("grantee_____________")
+	send, multiSend := &banktypes.MsgSend{}, &banktypes.MsgMultiSend{}
+	delegate, undelegate := &stakingtypes.MsgDelegate{}, &stakingtypes.MsgUndelegate{}
+	exec := func(msgs []sdk.Msg) *authz.MsgExec {
+		execMsg := authz.NewMsgExec(addr, msgs)
+		return &execMsg
+	}
+	fee := func(amount int64) sdk.Coins { return sdk.NewCoins(sdk.NewInt64Coin(nativeDenom, amount)) }
+
+	cases := map[string]struct {
+		msgs         []sdk.Msg
+		fee          sdk.Coins
+		gas          uint64
+		effectiveFee sdk.Coins
+		checkTx      bool
+		genesis      bool
+		simulate     bool
+		expErr       *sdkerrors.Error
+	}{
+		"default min gas price": {
+			msgs: []sdk.Msg{multiSend},
+			fee:  fee(1000),
+		},
+		"insufficient default min gas price": {
+			msgs:   []sdk.Msg{multiSend},
+			fee:    fee(999),
+			expErr: sdkerrors.ErrInsufficientFee,
+		},
+		"required fee is rounded up": {
+			msgs:   []sdk.Msg{multiSend},
+			fee:    fee(1000),
+			gas:    100_001,
+			expErr: sdkerrors.ErrInsufficientFee,
+		},
+		"per msg min gas price overrides the default": {
+			msgs: []sdk.Msg{send},
+			fee:  fee(5000),
+		},
+		"insufficient per msg min gas price": {
+			msgs:   []sdk.Msg{send},
+			fee:    fee(1000),
+			expErr: sdkerrors.ErrInsufficientFee,
+		},
+		"msg type without min gas price": {
+			msgs: []sdk.Msg{delegate},
+		},
+		"highest min gas price of the msgs": {
+			msgs:   []sdk.Msg{delegate, multiSend, send},
+			fee:    fee(1000),
+			expErr: sdkerrors.ErrInsufficientFee,
+		},
+		"authz exec is priced by its msgs": {
+			msgs: []sdk.Msg{exec([]sdk.Msg{delegate})},
+		},
+		"insufficient min gas price of the msgs of authz exec": {
+			msgs:   []sdk.Msg{exec([]sdk.Msg{delegate, send})},
+			fee:    fee(1000),
+			expErr: sdkerrors.ErrInsufficientFee,
+		},
+		"nested authz exec": {
+			msgs:   []sdk.Msg{exec([]sdk.Msg{exec([]sdk.Msg{send})})},
+			fee:    fee(1000),
+			expErr: sdkerrors.ErrInsufficientFee,
+		},
+		"evm denom min gas price is ignored": {
+			msgs: []sdk.Msg{undelegate},
+		},
+		"fee tokens are valued with the effective fee": {
+			msgs:         []sdk.Msg{send},
+			fee:          sdk.NewCoins(sdk.NewInt64Coin(ibcDenom, 10)),
+			effectiveFee: fee(5000),
+		},
+		"enforced in check tx": {
+			msgs:    []sdk.Msg{send},
+			fee:     fee(1000),
+			checkTx: true,
+			expErr:  sdkerrors.ErrInsufficientFee,
+		},
+		"skipped for gentxs": {
+			msgs:    []sdk.Msg{send},
+			genesis: true,
+		},
+		"skipped in simulation": {
+			msgs:     []sdk.Msg{send},
+			simulate: true,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			ctx := ctx.WithIsCheckTx(tc.checkTx)
+			if tc.genesis {
+				ctx = ctx.WithBlockHeight(0)
+			}
+			if tc.effectiveFee != nil {
+				ctx = ctx.WithValue(effectiveFeeKey{}, tc.effectiveFee)
+			}
+			gas := tc.gas
+			if gas == 0 {
+				gas = 100_000
+			}
+
+			called := false
+			_, err := decorator.AnteHandle(ctx, mockFeeTx{msgs: tc.msgs, fee: tc.fee, gas: gas}, tc.simulate, func(ctx sdk.Context, _ sdk.Tx, _ bool) (sdk.Context, error) {
+				called = true
+				return ctx, nil
+			})
+			if tc.expErr != nil {
+				require.ErrorIs(t, err, tc.expErr)
+				require.False(t, called)
+				return
+			}
+			require.NoError(t, err)
+			require.True(t, called)
+		})
+	}
+}
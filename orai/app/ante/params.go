@@ -0,0 +1,365 @@
+package ante
+
+import (
+	"fmt"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	paramtypes "github.com/cosmos/cosmos-sdk/x/params/types"
+)
+
+// SubspaceName is the name of the params subspace holding the consensus-level ante parameters.
+// The values can be changed through governance param change proposals.
+const SubspaceName = "ante"
+
+var (
+	// KeyDefaultMinGasPrices is store's key for DefaultMinGasPrices Params
+	KeyDefaultMinGasPrices = []byte("DefaultMinGasPrices")
+	// KeyMsgMinGasPrices is store's key for MsgMinGasPrices Params
+	KeyMsgMinGasPrices = []byte("MsgMinGasPrices")
+	// KeyFeeTokens is store's key for FeeTokens Params
+	KeyFeeTokens = []byte("FeeTokens")
+	// KeyMaxTxGas is store's key for MaxTxGas Params
+	KeyMaxTxGas = []byte("MaxTxGas")
+	// KeyMsgGasCaps is store's key for MsgGasCaps Params
+	KeyMsgGasCaps = []byte("MsgGasCaps")
+	// KeyAccountGasCaps is store's key for AccountGasCaps Params
+	KeyAccountGasCaps = []byte("AccountGasCaps")
+	// KeySponsorship is store's key for Sponsorship Params
+	KeySponsorship = []byte("Sponsorship")
+)
+
+// MsgMinGasPrice is the minimum gas price required by txs containing a given msg type
+type MsgMinGasPrice struct {
+	MsgTypeURL   string       `json:"msg_type_url" yaml:"msg_type_url"`
+	MinGasPrices sdk.DecCoins `json:"min_gas_prices" yaml:"min_gas_prices"`
+}
+
+// FeeToken is a governance whitelisted denom that can be used to pay tx fees.
+// Its amount is converted to the native denom using either the price contract or the fixed rate.
+type FeeToken struct {
+	// Denom is a bank denom, e.g. an IBC voucher, or a CW20 token as cw20/<contract address>
+	Denom string `json:"denom" yaml:"denom"`
+	// PriceContract is a wasm contract answering {"price":{"denom":...}} with {"rate":...}.
+	// When empty, Rate is used instead.
+	PriceContract string `json:"price_contract" yaml:"price_contract"`
+	// Rate is the amount of native denom worth one unit of Denom, e.g. a governance maintained TWAP
+	Rate sdk.Dec `json:"rate" yaml:"rate"`
+}
+
+// MsgGasCap is the maximum gas limit of txs containing a given msg type
+type MsgGasCap struct {
+	MsgTypeURL string `json:"msg_type_url" yaml:"msg_type_url"`
+	MaxGas     uint64 `json:"max_gas" yaml:"max_gas"`
+}
+
+// AccountGasCap is the maximum gas limit of the txs whose fees are paid by a given account.
+// It replaces MaxTxGas for the account, so it can be higher, e.g. for relayers, as well as lower.
+type AccountGasCap struct {
+	Address string `json:"address" yaml:"address"`
+	MaxGas  uint64 `json:"max_gas" yaml:"max_gas"`
+}
+
+// SponsorshipParams configures the sponsorship pool paying the fees of the first txs of new accounts
+type SponsorshipParams struct {
+	// MsgTypeURLs are the msg types that can be sponsored, the sponsorship is disabled if empty
+	MsgTypeURLs []string `json:"msg_type_urls" yaml:"msg_type_urls"`
+	// MaxSequence is the account sequence from which txs are no longer sponsored
+	MaxSequence uint64 `json:"max_sequence" yaml:"max_sequence"`
+	// MaxFeePerTx is the maximum fee sponsored for a tx
+	MaxFeePerTx sdk.Coins `json:"max_fee_per_tx" yaml:"max_fee_per_tx"`
+	// BlockBudget is the maximum amount of fees sponsored in a block
+	BlockBudget sdk.Coins `json:"block_budget" yaml:"block_budget"`
+	// MaxTxsPerBlock is the maximum number of txs sponsored in a block
+	MaxTxsPerBlock uint64 `json:"max_txs_per_block" yaml:"max_txs_per_block"`
+}
+
+// IsMsgSponsored returns true if the msg type can be sponsored
+func (sp SponsorshipParams) IsMsgSponsored(msgTypeURL string) bool {
+	for _, typeURL := range sp.MsgTypeURLs {
+		if typeURL == msgTypeURL {
+			return true
+		}
+	}
+	return false
+}
+
+// Params defines the consensus-level parameters enforced by the ante handler
+type Params struct {
+	// DefaultMinGasPrices applies to every msg type that has no entry in MsgMinGasPrices
+	DefaultMinGasPrices sdk.DecCoins     `json:"default_min_gas_prices" yaml:"default_min_gas_prices"`
+	MsgMinGasPrices     []MsgMinGasPrice `json:"msg_min_gas_prices" yaml:"msg_min_gas_prices"`
+	FeeTokens           []FeeToken       `json:"fee_tokens" yaml:"fee_tokens"`
+	// MaxTxGas is the maximum gas limit of Cosmos txs, 0 means no limit
+	MaxTxGas       uint64          `json:"max_tx_gas" yaml:"max_tx_gas"`
+	MsgGasCaps     []MsgGasCap     `json:"msg_gas_caps" yaml:"msg_gas_caps"`
+	AccountGasCaps []AccountGasCap `json:"account_gas_caps" yaml:"account_gas_caps"`
+	// Sponsorship configures the sponsorship pool
+	Sponsorship SponsorshipParams `json:"sponsorship" yaml:"sponsorship"`
+}
+
+// ParamKeyTable type declaration for parameters
+func ParamKeyTable() paramtypes.KeyTable {
+	return paramtypes.NewKeyTable().RegisterParamSet(&Params{})
+}
+
+// DefaultParams returns the default ante parameters, which do not enforce any consensus minimum fee
+func DefaultParams() Params {
+	return Params{
+		DefaultMinGasPrices: sdk.DecCoins{},
+		MsgMinGasPrices:     []MsgMinGasPrice{},
+		FeeTokens:           []FeeToken{},
+		MaxTxGas:            0,
+		MsgGasCaps:          []MsgGasCap{},
+		AccountGasCaps:      []AccountGasCap{},
+		Sponsorship: SponsorshipParams{
+			MsgTypeURLs: []string{},
+			MaxFeePerTx: sdk.Coins{},
+			BlockBudget: sdk.Coins{},
+		},
+	}
+}
+
+// Validate all ante parameters
+func (p Params) Validate() error {
+	if err := validateDefaultMinGasPrices(p.DefaultMinGasPrices); err != nil {
+		return err
+	}
+	if err := validateMsgMinGasPrices(p.MsgMinGasPrices); err != nil {
+		return err
+	}
+	if err := validateFeeTokens(p.FeeTokens); err != nil {
+		return err
+	}
+	if err := validateMaxTxGas(p.MaxTxGas); err != nil {
+		return err
+	}
+	if err := validateMsgGasCaps(p.MsgGasCaps); err != nil {
+		return err
+	}
+	if err := validateAccountGasCaps(p.AccountGasCaps); err != nil {
+		return err
+	}
+	return validateSponsorship(p.Sponsorship)
+}
+
+// ParamSetPairs implements params.ParamSet
+func (p *Params) ParamSetPairs() paramtypes.ParamSetPairs {
+	return paramtypes.ParamSetPairs{
+		paramtypes.NewParamSetPair(KeyDefaultMinGasPrices, &p.DefaultMinGasPrices, validateDefaultMinGasPrices),
+		paramtypes.NewParamSetPair(KeyMsgMinGasPrices, &p.MsgMinGasPrices, validateMsgMinGasPrices),
+		paramtypes.NewParamSetPair(KeyFeeTokens, &p.FeeTokens, validateFeeTokens),
+		paramtypes.NewParamSetPair(KeyMaxTxGas, &p.MaxTxGas, validateMaxTxGas),
+		paramtypes.NewParamSetPair(KeyMsgGasCaps, &p.MsgGasCaps, validateMsgGasCaps),
+		paramtypes.NewParamSetPair(KeyAccountGasCaps, &p.AccountGasCaps, validateAccountGasCaps),
+		paramtypes.NewParamSetPair(KeySponsorship, &p.Sponsorship, validateSponsorship),
+	}
+}
+
+// MinGasPricesForMsg returns the minimum gas prices configured for the msg type url, falling back to the default ones
+func (p Params) MinGasPricesForMsg(msgTypeURL string) sdk.DecCoins {
+	for _, m := range p.MsgMinGasPrices {
+		if m.MsgTypeURL == msgTypeURL {
+			return m.MinGasPrices
+		}
+	}
+	return p.DefaultMinGasPrices
+}
+
+// FeeToken returns the whitelisted fee token for the denom, if any
+func (p Params) FeeToken(denom string) (FeeToken, bool) {
+	for _, ft := range p.FeeTokens {
+		if ft.Denom == denom {
+			return ft, true
+		}
+	}
+	return FeeToken{}, false
+}
+
+// GasCapForMsg returns the maximum gas limit configured for the msg type url, if any
+func (p Params) GasCapForMsg(msgTypeURL string) (uint64, bool) {
+	for _, c := range p.MsgGasCaps {
+		if c.MsgTypeURL == msgTypeURL {
+			return c.MaxGas, true
+		}
+	}
+	return 0, false
+}
+
+// GasCapForAccount returns the maximum gas limit configured for the txs paid by the account, if any
+func (p Params) GasCapForAccount(addr sdk.AccAddress) (uint64, bool) {
+	for _, c := range p.AccountGasCaps {
+		if c.Address == addr.String() {
+			return c.MaxGas, true
+		}
+	}
+	return 0, false
+}
+
+func validateDefaultMinGasPrices(i interface{}) error {
+	v, ok := i.(sdk.DecCoins)
+	if !ok {
+		return fmt.Errorf("invalid parameter type: %T", i)
+	}
+	return v.Validate()
+}
+
+func validateMsgMinGasPrices(i interface{}) error {
+	v, ok := i.([]MsgMinGasPrice)
+	if !ok {
+		return fmt.Errorf("invalid parameter type: %T", i)
+	}
+
+	seen := make(map[string]bool, len(v))
+	for _, m := range v {
+		if m.MsgTypeURL == "" {
+			return fmt.Errorf("msg type url cannot be empty")
+		}
+		if seen[m.MsgTypeURL] {
+			return fmt.Errorf("duplicate min gas prices for msg type %s", m.MsgTypeURL)
+		}
+		seen[m.MsgTypeURL] = true
+
+		if err := m.MinGasPrices.Validate(); err != nil {
+			return fmt.Errorf("invalid min gas prices for msg type %s: %w", m.MsgTypeURL, err)
+		}
+	}
+	return nil
+}
+
+func validateFeeTokens(i interface{}) error {
+	v, ok := i.([]FeeToken)
+	if !ok {
+		return fmt.Errorf("invalid parameter type: %T", i)
+	}
+
+	seen := make(map[string]bool, len(v))
+	for _, ft := range v {
+		if err := sdk.ValidateDenom(ft.Denom); err != nil {
+			return fmt.Errorf("invalid fee token denom: %w", err)
+		}
+		if seen[ft.Denom] {
+			return fmt.Errorf("duplicate fee token %s", ft.Denom)
+		}
+		seen[ft.Denom] = true
+		if _, _, err := Cw20Contract(ft.Denom); err != nil {
+			return fmt.Errorf("invalid cw20 contract of fee token %s: %w", ft.Denom, err)
+		}
+
+		if ft.PriceContract != "" {
+			if _, err := sdk.AccAddressFromBech32(ft.PriceContract); err != nil {
+				return fmt.Errorf("invalid price contract for fee token %s: %w", ft.Denom, err)
+			}
+			continue
+		}
+		if ft.Rate.IsNil() || !ft.Rate.IsPositive() {
+			return fmt.Errorf("fee token %s requires a price contract or a positive rate", ft.Denom)
+		}
+	}
+	return nil
+}
+
+func validateMaxTxGas(i interface{}) error {
+	if _, ok := i.(uint64); !ok {
+		return fmt.Errorf("invalid parameter type: %T", i)
+	}
+	return nil
+}
+
+func validateMsgGasCaps(i interface{}) error {
+	v, ok := i.([]MsgGasCap)
+	if !ok {
+		return fmt.Errorf("invalid parameter type: %T", i)
+	}
+
+	seen := make(map[string]bool, len(v))
+	for _, c := range v {
+		if c.MsgTypeURL == "" {
+			return fmt.Errorf("msg type url cannot be empty")
+		}
+		if seen[c.MsgTypeURL] {
+			return fmt.Errorf("duplicate gas cap for msg type %s", c.MsgTypeURL)
+		}
+		seen[c.MsgTypeURL] = true
+
+		if c.MaxGas == 0 {
+			return fmt.Errorf("gas cap for msg type %s must be positive", c.MsgTypeURL)
+		}
+	}
+	return nil
+}
+
+func validateAccountGasCaps(i interface{}) error {
+	v, ok := i.([]AccountGasCap)
+	if !ok {
+		return fmt.Errorf("invalid parameter type: %T", i)
+	}
+
+	seen := make(map[string]bool, len(v))
+	for _, c := range v {
+		addr, err := sdk.AccAddressFromBech32(c.Address)
+		if err != nil {
+			return fmt.Errorf("invalid gas cap account %s: %w", c.Address, err)
+		}
+		// compare the normalized addresses, as bech32 is case insensitive
+		if seen[addr.String()] {
+			return fmt.Errorf("duplicate gas cap for account %s", c.Address)
+		}
+		seen[addr.String()] = true
+
+		if c.MaxGas == 0 {
+			return fmt.Errorf("gas cap for account %s must be positive", c.Address)
+		}
+	}
+	return nil
+}
+
+func validateSponsorship(i interface{}) error {
+	v, ok := i.(SponsorshipParams)
+	if !ok {
+		return fmt.Errorf("invalid parameter type: %T", i)
+	}
+
+	seen := make(map[string]bool, len(v.MsgTypeURLs))
+	for _, typeURL := range v.MsgTypeURLs {
+		if typeURL == "" {
+			return fmt.Errorf("sponsored msg type url cannot be empty")
+		}
+		if seen[typeURL] {
+			return fmt.Errorf("duplicate sponsored msg type %s", typeURL)
+		}
+		seen[typeURL] = true
+	}
+
+	if err := v.MaxFeePerTx.Validate(); err != nil {
+		return fmt.Errorf("invalid max fee per sponsored tx: %w", err)
+	}
+	if err := v.BlockBudget.Validate(); err != nil {
+		return fmt.Errorf("invalid sponsorship block budget: %w", err)
+	}
+	return nil
+}
+
+// ParamStore reads the ante parameters from their params subspace
+type ParamStore struct {
+	subspace paramtypes.Subspace
+}
+
+// NewParamStore returns a ParamStore backed by the given subspace
+func NewParamStore(subspace paramtypes.Subspace) ParamStore {
+	if !subspace.HasKeyTable() {
+		subspace = subspace.WithKeyTable(ParamKeyTable())
+	}
+	return ParamStore{subspace: subspace}
+}
+
+// GetParams returns the stored ante parameters. Parameters that have never been set keep their default value
+func (ps ParamStore) GetParams(ctx sdk.Context) Params {
+	params := DefaultParams()
+	ps.subspace.GetParamSetIfExists(ctx, &params)
+	return params
+}
+
+// SetParams stores the ante parameters
+func (ps ParamStore) SetParams(ctx sdk.Context, params Params) {
+	ps.subspace.SetParamSet(ctx, &params)
+}
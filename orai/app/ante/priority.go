@@ -0,0 +1,151 @@
+package ante
+
+import (
+	"math"
+	"math/big"
+	"sync/atomic"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	clienttypes "github.com/cosmos/ibc-go/v4/modules/core/02-client/types"
+	channeltypes "github.com/cosmos/ibc-go/v4/modules/core/04-channel/types"
+	evmutilkeeper "github.com/kava-labs/kava/x/evmutil/keeper"
+	evmtypes "github.com/tharsis/ethermint/x/evm/types"
+)
+
+var _ sdk.AnteDecorator = TxPriorityDecorator{}
+
+// TxPriority computes the mempool priority of txs for the Tendermint priority mempool.
+// The priority is the effective gas price of the tx expressed in EvmDenom per gas unit, so that
+// Cosmos txs (paying in orai or whitelisted fee tokens) and Ethereum txs are ordered on the same scale.
+type TxPriority struct {
+	evmKeeper       EVMKeeper
+	feeMarketKeeper evmtypes.FeeMarketKeeper
+	nativeDenom     string
+
+	// prioritizeIBCRelay gives the highest priority to txs that only relay IBC packets
+	prioritizeIBCRelay bool
+}
+
+// NewTxPriority creates a TxPriority valuing fees in nativeDenom and the EvmDenom
+func NewTxPriority(
+	evmKeeper EVMKeeper, feeMarketKeeper evmtypes.FeeMarketKeeper, nativeDenom string, prioritizeIBCRelay bool,
+) TxPriority {
+	return TxPriority{
+		evmKeeper:          evmKeeper,
+		feeMarketKeeper:    feeMarketKeeper,
+		nativeDenom:        nativeDenom,
+		prioritizeIBCRelay: prioritizeIBCRelay,
+	}
+}
+
+// GetTxPriority returns the priority of the tx. The fee of Cosmos txs is their EffectiveFee, so the ctx must have
+// gone through the FeeAbstractionDecorator. Txs whose fee cannot be valued get the lowest priority.
+func (tp TxPriority) GetTxPriority(ctx sdk.Context, tx sdk.Tx) int64 {
+	msgs := tx.GetMsgs()
+	if tp.prioritizeIBCRelay && isIBCRelayTx(msgs) {
+		return math.MaxInt64
+	}
+
+	if len(msgs) > 0 {
+		if _, ok := msgs[0].(*evmtypes.MsgEthereumTx); ok {
+			return tp.ethTxPriority(ctx, msgs)
+		}
+	}
+
+	feeTx, ok := tx.(sdk.FeeTx)
+	if !ok || feeTx.GetGas() == 0 {
+		return 0
+	}
+
+	fee := EffectiveFee(ctx, feeTx)
+	evmDenom := tp.evmKeeper.GetParams(ctx).EvmDenom
+	value := fee.AmountOf(tp.nativeDenom).Mul(evmutilkeeper.ConversionMultiplier)
+	if evmDenom != "" {
+		value = value.Add(fee.AmountOf(evmDenom))
+	}
+
+	return clampPriority(new(big.Int).Quo(value.BigInt(), new(big.Int).SetUint64(feeTx.GetGas())))
+}
+
+// TxPriorityDecorator computes the priority of the txs checked for the mempool, see TxPriority. The SDK v0.45
+// context has no priority, so the decorator keeps the priority of the last checked tx for the app to set it in the
+// response of CheckTx, which Tendermint never runs concurrently.
+type TxPriorityDecorator struct {
+	txPriority TxPriority
+	priority   *int64
+}
+
+// NewTxPriorityDecorator creates a decorator computing the priorities of txs with txPriority
+func NewTxPriorityDecorator(txPriority TxPriority) TxPriorityDecorator {
+	return TxPriorityDecorator{
+		txPriority: txPriority,
+		priority:   new(int64),
+	}
+}
+
+func (tpd TxPriorityDecorator) AnteHandle(ctx sdk.Context, tx sdk.Tx, simulate bool, next sdk.AnteHandler) (newCtx sdk.Context, err error) {
+	if ctx.IsCheckTx() && !simulate {
+		atomic.StoreInt64(tpd.priority, tpd.txPriority.GetTxPriority(ctx, tx))
+	}
+
+	return next(ctx, tx, simulate)
+}
+
+// TakePriority returns the priority of the last tx checked and resets it
+func (tpd TxPriorityDecorator) TakePriority() int64 {
+	return atomic.SwapInt64(tpd.priority, 0)
+}
+
+// ethTxPriority returns the lowest effective gas price of the MsgEthereumTxs, which are priced in EvmDenom
+func (tp TxPriority) ethTxPriority(ctx sdk.Context, msgs []sdk.Msg) int64 {
+	baseFee := tp.feeMarketKeeper.GetBaseFee(ctx)
+
+	priority := int64(math.MaxInt64)
+	for _, msg := range msgs {
+		ethMsg, ok := msg.(*evmtypes.MsgEthereumTx)
+		if !ok {
+			return 0
+		}
+		txData, err := evmtypes.UnpackTxData(ethMsg.Data)
+		if err != nil || txData.GetGas() == 0 {
+			return 0
+		}
+
+		gasPrice := txData.GetGasPrice()
+		if baseFee != nil {
+			gasPrice = new(big.Int).Quo(txData.EffectiveFee(baseFee), new(big.Int).SetUint64(txData.GetGas()))
+		}
+
+		if p := clampPriority(gasPrice); p < priority {
+			priority = p
+		}
+	}
+	return priority
+}
+
+// isIBCRelayTx returns true if the msgs only relay IBC packets, optionally along with client updates
+func isIBCRelayTx(msgs []sdk.Msg) bool {
+	packetMsgs := 0
+	for _, msg := range msgs {
+		switch msg.(type) {
+		case *channeltypes.MsgRecvPacket, *channeltypes.MsgAcknowledgement,
+			*channeltypes.MsgTimeout, *channeltypes.MsgTimeoutOnClose:
+			packetMsgs++
+		case *clienttypes.MsgUpdateClient:
+		default:
+			return false
+		}
+	}
+	return packetMsgs > 0
+}
+
+func clampPriority(v *big.Int) int64 {
+	switch {
+	case v == nil || v.Sign() <= 0:
+		return 0
+	case v.IsInt64():
+		return v.Int64()
+	default:
+		return math.MaxInt64
+	}
+}
@@ -0,0 +1,156 @@
+package ante
+
+import (
+	"math"
+	"math/big"
+	"testing"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	banktypes "github.com/cosmos/cosmos-sdk/x/bank/types"
+	clienttypes "github.com/cosmos/ibc-go/v4/modules/core/02-client/types"
+	channeltypes "github.com/cosmos/ibc-go/v4/modules/core/04-channel/types"
+	ethtypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/require"
+	evmtypes "github.com/tharsis/ethermint/x/evm/types"
+	feemarkettypes "github.com/tharsis/ethermint/x/feemarket/types"
+)
+
+type mockFeeMarketKeeper struct {
+	baseFee *big.Int
+}
+
+func (m mockFeeMarketKeeper) GetBaseFee(sdk.Context) *big.Int { return m.baseFee }
+func (mockFeeMarketKeeper) GetParams(sdk.Context) feemarkettypes.Params {
+	return feemarkettypes.DefaultParams()
+}
+
+// ethTx returns a tx holding a legacy MsgEthereumTx if gasPrice is set, a dynamic fee one otherwise
+func ethTx(gasPrice, gasFeeCap, gasTipCap *big.Int) *evmtypes.MsgEthereumTx {
+	if gasPrice != nil {
+		return evmtypes.NewTx(big.NewInt(1), 0, nil, nil, 21000, gasPrice, nil, nil, nil, nil)
+	}
+	return evmtypes.NewTx(big.NewInt(1), 0, nil, nil, 21000, nil, gasFeeCap, gasTipCap, nil, &ethtypes.AccessList{})
+}
+
+func TestTxPriority(t *testing.T) {
+	ctx, _ := setupParamStore(t, DefaultParams())
+	send := banktypes.NewMsgSend(sdk.AccAddress("from"), sdk.AccAddress("to"), nil)
+	relay := []sdk.Msg{&clienttypes.MsgUpdateClient{}, &channeltypes.MsgRecvPacket{}, &channeltypes.MsgAcknowledgement{}}
+
+	cases := map[string]struct {
+		tx            mockFeeTx
+		effectiveFee  sdk.Coins
+		baseFee       *big.Int
+		prioritizeIBC bool
+		expPriority   int64
+	}{
+		"native fee is valued in evm denom per gas": {
+			tx:          mockFeeTx{msgs: []sdk.Msg{send}, fee: sdk.NewCoins(sdk.NewInt64Coin(nativeDenom, 1000)), gas: 100},
+			expPriority: 10_000_000_000_000,
+		},
+		"native and evm denom fees add up": {
+			tx:          mockFeeTx{msgs: []sdk.Msg{send}, fee: sdk.NewCoins(sdk.NewInt64Coin(nativeDenom, 1), sdk.NewInt64Coin("aorai", 500)), gas: 10},
+			expPriority: 100_000_000_050,
+		},
+		"fee tokens are valued with the effective fee": {
+			tx:           mockFeeTx{msgs: []sdk.Msg{send}, fee: sdk.NewCoins(sdk.NewInt64Coin(ibcDenom, 1000)), gas: 100},
+			effectiveFee: sdk.NewCoins(sdk.NewInt64Coin(nativeDenom, 500)),
+			expPriority:  5_000_000_000_000,
+		},
+		"fee not whitelisted has the lowest priority": {
+			tx:          mockFeeTx{msgs: []sdk.Msg{send}, fee: sdk.NewCoins(sdk.NewInt64Coin(ibcDenom, 1000)), gas: 100},
+			expPriority: 0,
+		},
+		"no gas has the lowest priority": {
+			tx:          mockFeeTx{msgs: []sdk.Msg{send}, fee: sdk.NewCoins(sdk.NewInt64Coin(nativeDenom, 1000))},
+			expPriority: 0,
+		},
+		"cosmos priority is clamped": {
+			tx:          mockFeeTx{msgs: []sdk.Msg{send}, fee: sdk.NewCoins(sdk.NewInt64Coin(nativeDenom, math.MaxInt64)), gas: 1},
+			expPriority: math.MaxInt64,
+		},
+		"legacy eth tx gas price": {
+			tx:          mockFeeTx{msgs: []sdk.Msg{ethTx(big.NewInt(50), nil, nil)}},
+			expPriority: 50,
+		},
+		"dynamic fee eth tx effective gas price": {
+			tx:          mockFeeTx{msgs: []sdk.Msg{ethTx(nil, big.NewInt(100), big.NewInt(5))}},
+			baseFee:     big.NewInt(10),
+			expPriority: 15,
+		},
+		"dynamic fee eth tx gas price is capped by the fee cap": {
+			tx:          mockFeeTx{msgs: []sdk.Msg{ethTx(nil, big.NewInt(12), big.NewInt(5))}},
+			baseFee:     big.NewInt(10),
+			expPriority: 12,
+		},
+		"lowest gas price of the eth msgs": {
+			tx:          mockFeeTx{msgs: []sdk.Msg{ethTx(big.NewInt(50), nil, nil), ethTx(big.NewInt(20), nil, nil)}},
+			expPriority: 20,
+		},
+		"eth msgs mixed with cosmos msgs have the lowest priority": {
+			tx:          mockFeeTx{msgs: []sdk.Msg{ethTx(big.NewInt(50), nil, nil), send}},
+			expPriority: 0,
+		},
+		"eth priority is clamped": {
+			tx:          mockFeeTx{msgs: []sdk.Msg{ethTx(new(big.Int).Lsh(big.NewInt(1), 70), nil, nil)}},
+			expPriority: math.MaxInt64,
+		},
+		"ibc relay has the highest priority": {
+			tx:            mockFeeTx{msgs: relay, gas: 100},
+			prioritizeIBC: true,
+			expPriority:   math.MaxInt64,
+		},
+		"ibc relay is valued by its fee when not prioritized": {
+			tx:          mockFeeTx{msgs: relay, fee: sdk.NewCoins(sdk.NewInt64Coin("aorai", 1000)), gas: 100},
+			expPriority: 10,
+		},
+		"client updates alone are not relays": {
+			tx:            mockFeeTx{msgs: []sdk.Msg{&clienttypes.MsgUpdateClient{}}, gas: 100},
+			prioritizeIBC: true,
+			expPriority:   0,
+		},
+		"relay mixed with other msgs is not prioritized": {
+			tx:            mockFeeTx{msgs: append([]sdk.Msg{send}, relay...), gas: 100},
+			prioritizeIBC: true,
+			expPriority:   0,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			txPriority := NewTxPriority(mockEVMKeeper{}, mockFeeMarketKeeper{baseFee: tc.baseFee}, nativeDenom, tc.prioritizeIBC)
+			ctx := ctx
+			if tc.effectiveFee != nil {
+				ctx = ctx.WithValue(effectiveFeeKey{}, tc.effectiveFee)
+			}
+			require.Equal(t, tc.expPriority, txPriority.GetTxPriority(ctx, tc.tx))
+		})
+	}
+}
+
+func TestTxPriorityDecorator(t *testing.T) {
+	ctx, _ := setupParamStore(t, DefaultParams())
+	decorator := NewTxPriorityDecorator(NewTxPriority(mockEVMKeeper{}, mockFeeMarketKeeper{}, nativeDenom, false))
+	tx := mockFeeTx{fee: sdk.NewCoins(sdk.NewInt64Coin("aorai", 1000)), gas: 100}
+
+	run := func(ctx sdk.Context, simulate bool) {
+		_, err := decorator.AnteHandle(ctx, tx, simulate, func(ctx sdk.Context, _ sdk.Tx, _ bool) (sdk.Context, error) {
+			return ctx, nil
+		})
+		require.NoError(t, err)
+	}
+
+	run(ctx.WithIsCheckTx(true), false)
+	require.Equal(t, int64(10), decorator.TakePriority())
+	require.Zero(t, decorator.TakePriority())
+
+	// the fee abstraction in the ante ctx values the fee
+	run(ctx.WithIsCheckTx(true).WithValue(effectiveFeeKey{}, sdk.NewCoins(sdk.NewInt64Coin("aorai", 5000))), false)
+	require.Equal(t, int64(50), decorator.TakePriority())
+
+	// only the txs checked for the mempool have a priority
+	run(ctx.WithIsCheckTx(true), true)
+	require.Zero(t, decorator.TakePriority())
+	run(ctx, false)
+	require.Zero(t, decorator.TakePriority())
+}
@@ -0,0 +1,114 @@
+package ante
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime/debug"
+
+	metrics "github.com/armon/go-metrics"
+	"github.com/cosmos/cosmos-sdk/telemetry"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	sdkerrors "github.com/cosmos/cosmos-sdk/types/errors"
+)
+
+const (
+	panicKindOutOfGas   = "out_of_gas"
+	panicKindUnexpected = "unexpected"
+)
+
+var _ sdk.AnteDecorator = RecoveryDecorator{}
+
+// RecoveryDecorator recovers the panics of the decorator it wraps and turns them into errors naming the decorator.
+// Out of gas panics are expected and reported as ErrOutOfGas, any other panic is reported as ErrPanic, logged with
+// its stack trace and, when dumpDir is set, the tx bytes are written there so that the crash can be replayed offline.
+// Since every decorator of the chain is wrapped, a panic is recovered by the wrapper of the decorator that panicked.
+type RecoveryDecorator struct {
+	decorator sdk.AnteDecorator
+	name      string
+	dumpDir   string
+}
+
+// NewRecoveryDecorator wraps the decorator so that its panics are recovered
+func NewRecoveryDecorator(decorator sdk.AnteDecorator, dumpDir string) RecoveryDecorator {
+	return RecoveryDecorator{
+		decorator: decorator,
+		name:      fmt.Sprintf("%T", decorator),
+		dumpDir:   dumpDir,
+	}
+}
+
+// WithRecovery wraps each decorator in a RecoveryDecorator
+func WithRecovery(dumpDir string, decorators ...sdk.AnteDecorator) []sdk.AnteDecorator {
+	wrapped := make([]sdk.AnteDecorator, len(decorators))
+	for i, decorator := range decorators {
+		wrapped[i] = NewRecoveryDecorator(decorator, dumpDir)
+	}
+	return wrapped
+}
+
+func (rd RecoveryDecorator) AnteHandle(ctx sdk.Context, tx sdk.Tx, simulate bool, next sdk.AnteHandler) (newCtx sdk.Context, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			newCtx = ctx
+			err = rd.handlePanic(ctx, r)
+		}
+	}()
+
+	return rd.decorator.AnteHandle(ctx, tx, simulate, next)
+}
+
+func (rd RecoveryDecorator) handlePanic(ctx sdk.Context, r interface{}) error {
+	kind, err := ClassifyPanic(ctx, r)
+	telemetry.IncrCounterWithLabels(
+		[]string{"ante", "panic"}, 1,
+		[]metrics.Label{telemetry.NewLabel("decorator", rd.name), telemetry.NewLabel("kind", kind)},
+	)
+	if kind == panicKindOutOfGas {
+		return sdkerrors.Wrapf(err, "in %s", rd.name)
+	}
+
+	logger := ctx.Logger().With("decorator", rd.name)
+	logger.Error("ante handler panicked", "recover", fmt.Sprintf("%v", r), "stack trace", string(debug.Stack()))
+	if rd.dumpDir != "" && len(ctx.TxBytes()) > 0 {
+		path, dumpErr := dumpTx(rd.dumpDir, ctx.TxBytes())
+		if dumpErr != nil {
+			logger.Error("failed to dump tx of ante handler panic", "error", dumpErr)
+		} else {
+			logger.Error("dumped tx of ante handler panic", "path", path)
+		}
+	}
+
+	return sdkerrors.Wrapf(err, "in %s", rd.name)
+}
+
+// ClassifyPanic reports whether a recovered panic ran out of gas or was unexpected, and converts it into an error
+func ClassifyPanic(ctx sdk.Context, r interface{}) (kind string, err error) {
+	switch rType := r.(type) {
+	case sdk.ErrorOutOfGas:
+		gasWanted, gasUsed := uint64(0), uint64(0)
+		if gasMeter := ctx.GasMeter(); gasMeter != nil {
+			gasWanted, gasUsed = gasMeter.Limit(), gasMeter.GasConsumed()
+		}
+		return panicKindOutOfGas, sdkerrors.Wrapf(
+			sdkerrors.ErrOutOfGas, "out of gas in location: %v; gasWanted: %d, gasUsed: %d",
+			rType.Descriptor, gasWanted, gasUsed,
+		)
+	case sdk.ErrorGasOverflow:
+		return panicKindOutOfGas, sdkerrors.Wrapf(sdkerrors.ErrOutOfGas, "gas overflow in location: %v", rType.Descriptor)
+	default:
+		return panicKindUnexpected, sdkerrors.Wrapf(sdkerrors.ErrPanic, "%v", r)
+	}
+}
+
+// dumpTx writes the tx bytes to a file of dir named after their hash
+func dumpTx(dir string, txBytes []byte) (string, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", err
+	}
+	hash := sha256.Sum256(txBytes)
+	path := filepath.Join(dir, hex.EncodeToString(hash[:])+".tx")
+	return path, os.WriteFile(path, txBytes, 0o600)
+}
@@ -0,0 +1,60 @@
+package ante
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/cosmos/cosmos-sdk/testutil"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	sdkerrors "github.com/cosmos/cosmos-sdk/types/errors"
+	"github.com/stretchr/testify/require"
+)
+
+type panickingDecorator struct {
+	value interface{}
+}
+
+func (pd panickingDecorator) AnteHandle(ctx sdk.Context, _ sdk.Tx, _ bool, _ sdk.AnteHandler) (sdk.Context, error) {
+	if pd.value == nil {
+		ctx.GasMeter().ConsumeGas(ctx.GasMeter().Limit()+1, "panicking decorator")
+	}
+	panic(pd.value)
+}
+
+type passDecorator struct{}
+
+func (passDecorator) AnteHandle(ctx sdk.Context, tx sdk.Tx, simulate bool, next sdk.AnteHandler) (sdk.Context, error) {
+	return next(ctx, tx, simulate)
+}
+
+func TestRecoveryDecorator(t *testing.T) {
+	key := sdk.NewKVStoreKey("test")
+	txBytes := []byte("tx bytes")
+	ctx := testutil.DefaultContext(key, sdk.NewTransientStoreKey("transient_test")).
+		WithGasMeter(sdk.NewGasMeter(1000)).
+		WithTxBytes(txBytes)
+	tx := mockFeeTx{}
+
+	// out of gas panics are expected, nothing is dumped
+	dumpDir := filepath.Join(t.TempDir(), "dump")
+	anteHandler := sdk.ChainAnteDecorators(WithRecovery(dumpDir, passDecorator{}, panickingDecorator{})...)
+	_, err := anteHandler(ctx, tx, false)
+	require.ErrorIs(t, err, sdkerrors.ErrOutOfGas)
+	require.Contains(t, err.Error(), "ante.panickingDecorator")
+	require.NoDirExists(t, dumpDir)
+
+	// unexpected panics name the decorator which panicked and dump the tx
+	anteHandler = sdk.ChainAnteDecorators(WithRecovery(dumpDir, passDecorator{}, panickingDecorator{value: "boom"})...)
+	_, err = anteHandler(ctx, tx, false)
+	require.ErrorIs(t, err, sdkerrors.ErrPanic)
+	require.Contains(t, err.Error(), "boom")
+	require.Contains(t, err.Error(), "ante.panickingDecorator")
+
+	files, err := os.ReadDir(dumpDir)
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+	bz, err := os.ReadFile(filepath.Join(dumpDir, files[0].Name()))
+	require.NoError(t, err)
+	require.Equal(t, txBytes, bz)
+}
@@ -0,0 +1,114 @@
+package ante
+
+import (
+	"github.com/cosmos/cosmos-sdk/telemetry"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	clienttypes "github.com/cosmos/ibc-go/v4/modules/core/02-client/types"
+	channeltypes "github.com/cosmos/ibc-go/v4/modules/core/04-channel/types"
+	"github.com/cosmos/ibc-go/v4/modules/core/exported"
+)
+
+var _ sdk.AnteDecorator = RedundantRelayDecorator{}
+
+// ChannelKeeper specifies the interface that RedundantRelayDecorator requires
+type ChannelKeeper interface {
+	GetChannel(ctx sdk.Context, portID, channelID string) (channeltypes.Channel, bool)
+	GetNextSequenceRecv(ctx sdk.Context, portID, channelID string) (uint64, bool)
+	GetPacketReceipt(ctx sdk.Context, portID, channelID string, sequence uint64) (string, bool)
+	GetPacketCommitment(ctx sdk.Context, portID, channelID string, sequence uint64) []byte
+}
+
+// RedundantRelayDecorator rejects in CheckTx the relay txs whose packets have all been relayed already.
+// Unlike the IBC AnteDecorator, it only reads the packet receipts and commitments instead of executing
+// the packet msgs, so it runs before the fees are checked and deducted and rejecting costs nothing.
+// The IBC AnteDecorator is kept at the end of the chain for the cases this cheap check cannot detect.
+type RedundantRelayDecorator struct {
+	channelKeeper ChannelKeeper
+}
+
+// NewRedundantRelayDecorator creates a decorator rejecting redundant IBC relay txs from the mempool
+func NewRedundantRelayDecorator(channelKeeper ChannelKeeper) RedundantRelayDecorator {
+	return RedundantRelayDecorator{
+		channelKeeper: channelKeeper,
+	}
+}
+
+func (rrd RedundantRelayDecorator) AnteHandle(ctx sdk.Context, tx sdk.Tx, simulate bool, next sdk.AnteHandler) (newCtx sdk.Context, err error) {
+	// do not run redundancy check on DeliverTx or simulate
+	if (!ctx.IsCheckTx() && !ctx.IsReCheckTx()) || simulate {
+		return next(ctx, tx, simulate)
+	}
+
+	redundancies := 0
+	packetMsgs := 0
+	for _, m := range tx.GetMsgs() {
+		switch msg := m.(type) {
+		case *channeltypes.MsgRecvPacket:
+			if rrd.isReceived(ctx, msg.Packet) {
+				redundancies++
+			}
+			packetMsgs++
+
+		case *channeltypes.MsgAcknowledgement:
+			if rrd.isCommitmentDeleted(ctx, msg.Packet) {
+				redundancies++
+			}
+			packetMsgs++
+
+		case *channeltypes.MsgTimeout:
+			if rrd.isCommitmentDeleted(ctx, msg.Packet) {
+				redundancies++
+			}
+			packetMsgs++
+
+		case *channeltypes.MsgTimeoutOnClose:
+			if rrd.isCommitmentDeleted(ctx, msg.Packet) {
+				redundancies++
+			}
+			packetMsgs++
+
+		case *clienttypes.MsgUpdateClient:
+			// client updates only matter along with the packets they prove
+
+		default:
+			// txs batching other msgs must still be processed
+			return next(ctx, tx, simulate)
+		}
+	}
+
+	if redundancies > 0 {
+		telemetry.IncrCounter(float32(redundancies), "ante", "ibc", "redundant_packets")
+	}
+
+	// only reject if all packet messages are redundant
+	if redundancies == packetMsgs && packetMsgs > 0 {
+		telemetry.IncrCounter(1, "ante", "ibc", "redundant_relays_dropped")
+		return ctx, channeltypes.ErrRedundantTx
+	}
+
+	return next(ctx, tx, simulate)
+}
+
+// isReceived returns true if the packet has already been received on its destination channel
+func (rrd RedundantRelayDecorator) isReceived(ctx sdk.Context, packet channeltypes.Packet) bool {
+	channel, found := rrd.channelKeeper.GetChannel(ctx, packet.GetDestPort(), packet.GetDestChannel())
+	if !found {
+		return false
+	}
+
+	switch channel.Ordering {
+	case channeltypes.ORDERED:
+		nextSequenceRecv, found := rrd.channelKeeper.GetNextSequenceRecv(ctx, packet.GetDestPort(), packet.GetDestChannel())
+		return found && packet.GetSequence() < nextSequenceRecv
+	case channeltypes.UNORDERED:
+		_, found := rrd.channelKeeper.GetPacketReceipt(ctx, packet.GetDestPort(), packet.GetDestChannel(), packet.GetSequence())
+		return found
+	default:
+		return false
+	}
+}
+
+// isCommitmentDeleted returns true if the packet commitment has already been deleted by an acknowledgement or a timeout
+func (rrd RedundantRelayDecorator) isCommitmentDeleted(ctx sdk.Context, packet exported.PacketI) bool {
+	return len(rrd.channelKeeper.GetPacketCommitment(ctx, packet.GetSourcePort(), packet.GetSourceChannel(), packet.GetSequence())) == 0
+}
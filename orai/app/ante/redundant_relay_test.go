@@ -0,0 +1,158 @@
+package ante
+
+import (
+	"fmt"
+	"testing"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	banktypes "github.com/cosmos/cosmos-sdk/x/bank/types"
+	clienttypes "github.com/cosmos/ibc-go/v4/modules/core/02-client/types"
+	channeltypes "github.com/cosmos/ibc-go/v4/modules/core/04-channel/types"
+	"github.com/stretchr/testify/require"
+)
+
+// mockChannelKeeper holds the channels, receipts and commitments read by the RedundantRelayDecorator
+type mockChannelKeeper struct {
+	channels         map[string]channeltypes.Channel
+	nextSequenceRecv map[string]uint64
+	receipts         map[string]bool
+	commitments      map[string]bool
+}
+
+func (m mockChannelKeeper) GetChannel(_ sdk.Context, portID, channelID string) (channeltypes.Channel, bool) {
+	channel, found := m.channels[portID+"/"+channelID]
+	return channel, found
+}
+
+func (m mockChannelKeeper) GetNextSequenceRecv(_ sdk.Context, portID, channelID string) (uint64, bool) {
+	sequence, found := m.nextSequenceRecv[portID+"/"+channelID]
+	return sequence, found
+}
+
+func (m mockChannelKeeper) GetPacketReceipt(_ sdk.Context, portID, channelID string, sequence uint64) (string, bool) {
+	if m.receipts[fmt.Sprintf("%s/%s/%d", portID, channelID, sequence)] {
+		return string([]byte{1}), true
+	}
+	return "", false
+}
+
+func (m mockChannelKeeper) GetPacketCommitment(_ sdk.Context, portID, channelID string, sequence uint64) []byte {
+	if m.commitments[fmt.Sprintf("%s/%s/%d", portID, channelID, sequence)] {
+		return []byte("commitment")
+	}
+	return nil
+}
+
+func TestRedundantRelayDecorator(t *testing.T) {
+	ctx, _ := setupParamStore(t, DefaultParams())
+	decorator := NewRedundantRelayDecorator(mockChannelKeeper{
+		channels: map[string]channeltypes.Channel{
+			"transfer/channel-0": {Ordering: channeltypes.UNORDERED},
+			"ica/channel-1":      {Ordering: channeltypes.ORDERED},
+		},
+		nextSequenceRecv: map[string]uint64{"ica/channel-1": 5},
+		receipts:         map[string]bool{"transfer/channel-0/1": true},
+		commitments:      map[string]bool{"transfer/channel-2/3": true},
+	})
+
+	recv := func(port, channel string, sequence uint64) *channeltypes.MsgRecvPacket {
+		return &channeltypes.MsgRecvPacket{Packet: channeltypes.Packet{Sequence: sequence, DestinationPort: port, DestinationChannel: channel}}
+	}
+	sent := func(sequence uint64) channeltypes.Packet {
+		return channeltypes.Packet{Sequence: sequence, SourcePort: "transfer", SourceChannel: "channel-2"}
+	}
+	ack := func(sequence uint64) *channeltypes.MsgAcknowledgement {
+		return &channeltypes.MsgAcknowledgement{Packet: sent(sequence)}
+	}
+	timeout := func(sequence uint64) *channeltypes.MsgTimeout {
+		return &channeltypes.MsgTimeout{Packet: sent(sequence)}
+	}
+	timeoutOnClose := func(sequence uint64) *channeltypes.MsgTimeoutOnClose {
+		return &channeltypes.MsgTimeoutOnClose{Packet: sent(sequence)}
+	}
+
+	cases := map[string]struct {
+		msgs      []sdk.Msg
+		deliverTx bool
+		simulate  bool
+		redundant bool
+	}{
+		"received packet on unordered channel": {
+			msgs:      []sdk.Msg{&clienttypes.MsgUpdateClient{}, recv("transfer", "channel-0", 1)},
+			redundant: true,
+		},
+		"new packet on unordered channel": {
+			msgs: []sdk.Msg{recv("transfer", "channel-0", 2)},
+		},
+		"received packet on ordered channel": {
+			msgs:      []sdk.Msg{recv("ica", "channel-1", 4)},
+			redundant: true,
+		},
+		"next packet on ordered channel": {
+			msgs: []sdk.Msg{recv("ica", "channel-1", 5)},
+		},
+		"packet on unknown channel": {
+			msgs: []sdk.Msg{recv("transfer", "channel-9", 1)},
+		},
+		"acknowledged packet": {
+			msgs:      []sdk.Msg{ack(1)},
+			redundant: true,
+		},
+		"pending acknowledgement": {
+			msgs: []sdk.Msg{ack(3)},
+		},
+		"timed out packet": {
+			msgs:      []sdk.Msg{timeout(1), timeoutOnClose(2)},
+			redundant: true,
+		},
+		"pending timeout": {
+			msgs: []sdk.Msg{timeout(3)},
+		},
+		"pending timeout on close": {
+			msgs: []sdk.Msg{timeoutOnClose(3)},
+		},
+		"one new packet among redundant ones": {
+			msgs: []sdk.Msg{
+				&clienttypes.MsgUpdateClient{}, recv("transfer", "channel-0", 1), recv("ica", "channel-1", 4), ack(1),
+				recv("transfer", "channel-0", 2),
+			},
+		},
+		"client update alone": {
+			msgs: []sdk.Msg{&clienttypes.MsgUpdateClient{}},
+		},
+		"redundant packets along with other msgs": {
+			msgs: []sdk.Msg{recv("transfer", "channel-0", 1), &banktypes.MsgSend{}},
+		},
+		"not checked in deliver tx": {
+			msgs:      []sdk.Msg{recv("transfer", "channel-0", 1)},
+			deliverTx: true,
+		},
+		"not checked in simulation": {
+			msgs:     []sdk.Msg{recv("transfer", "channel-0", 1)},
+			simulate: true,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			called := false
+			_, err := decorator.AnteHandle(ctx.WithIsCheckTx(!tc.deliverTx), mockFeeTx{msgs: tc.msgs}, tc.simulate, func(ctx sdk.Context, _ sdk.Tx, _ bool) (sdk.Context, error) {
+				called = true
+				return ctx, nil
+			})
+			if tc.redundant {
+				require.ErrorIs(t, err, channeltypes.ErrRedundantTx)
+				require.False(t, called)
+				return
+			}
+			require.NoError(t, err)
+			require.True(t, called)
+		})
+	}
+
+	// recheck drops the relays whose packets were relayed by the last block
+	_, err := decorator.AnteHandle(ctx.WithIsReCheckTx(true), mockFeeTx{msgs: []sdk.Msg{ack(1)}}, false, func(ctx sdk.Context, _ sdk.Tx, _ bool) (sdk.Context, error) {
+		return ctx, nil
+	})
+	require.ErrorIs(t, err, channeltypes.ErrRedundantTx)
+}
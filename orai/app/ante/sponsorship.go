@@ -0,0 +1,189 @@
+package ante
+
+import (
+	"encoding/binary"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	sdkerrors "github.com/cosmos/cosmos-sdk/types/errors"
+	"github.com/cosmos/cosmos-sdk/x/auth/ante"
+	authtypes "github.com/cosmos/cosmos-sdk/x/auth/types"
+	evmtypes "github.com/tharsis/ethermint/x/evm/types"
+)
+
+const (
+	// SponsorshipPoolName is the module account funded by governance to sponsor fees
+	SponsorshipPoolName = "sponsorship"
+	// TStoreKey is the transient store of the ante handler, tracking the sponsored fees of the block
+	TStoreKey = "transient_ante"
+
+	// EventTypeSponsoredFee is emitted when the sponsorship pool pays the fee of a tx
+	EventTypeSponsoredFee = "sponsored_fee"
+)
+
+var (
+	sponsoredFeesKey     = []byte{0x01}
+	sponsoredTxsKey      = []byte{0x02}
+	sponsoredAccountsKey = []byte{0x03}
+)
+
+// BankKeeper specifies the interface of the bank keeper that the ante handler requires
+type BankKeeper interface {
+	evmtypes.BankKeeper
+	GetAllBalances(ctx sdk.Context, addr sdk.AccAddress) sdk.Coins
+	SendCoinsFromModuleToModule(ctx sdk.Context, senderModule, recipientModule string, amt sdk.Coins) error
+}
+
+var _ sdk.AnteDecorator = SponsoredDeductFeeDecorator{}
+
+// SponsoredDeductFeeDecorator deducts the fee of the txs of new accounts from the sponsorship pool, and falls back to
+// the DeductFeeDecorator for the other txs. A tx is sponsored when:
+//   - all its msgs, the ones executed by authz MsgExec included, have a sponsored type and it has no fee granter,
+//   - its fee payer exists, holds no native denom and its sequence is below the max sequence,
+//   - its fee does not exceed the max fee per tx, nor the remaining block budget of the pool,
+//   - the fee payer has no other sponsored tx in the block and the block has not reached its max sponsored txs.
+type SponsoredDeductFeeDecorator struct {
+	ak          ante.AccountKeeper
+	bankKeeper  BankKeeper
+	paramStore  ParamStore
+	storeKey    sdk.StoreKey
+	nativeDenom string
+	deductFee   ante.DeductFeeDecorator
+}
+
+// NewSponsoredDeductFeeDecorator creates a decorator deducting fees from the sponsorship pool or the fee payer
+func NewSponsoredDeductFeeDecorator(
+	ak ante.AccountKeeper, bk BankKeeper, fk ante.FeegrantKeeper, paramStore ParamStore, storeKey sdk.StoreKey, nativeDenom string,
+) SponsoredDeductFeeDecorator {
+	return SponsoredDeductFeeDecorator{
+		ak:          ak,
+		bankKeeper:  bk,
+		paramStore:  paramStore,
+		storeKey:    storeKey,
+		nativeDenom: nativeDenom,
+		deductFee:   ante.NewDeductFeeDecorator(ak, bk, fk),
+	}
+}
+
+func (sdfd SponsoredDeductFeeDecorator) AnteHandle(ctx sdk.Context, tx sdk.Tx, simulate bool, next sdk.AnteHandler) (newCtx sdk.Context, err error) {
+	feeTx, ok := tx.(sdk.FeeTx)
+	if !ok {
+		return ctx, sdkerrors.Wrap(sdkerrors.ErrTxDecode, "Tx must be a FeeTx")
+	}
+
+	sponsored, err := sdfd.isSponsored(ctx, feeTx)
+	if err != nil {
+		return ctx, err
+	}
+	if !sponsored {
+		return sdfd.deductFee.AnteHandle(ctx, tx, simulate, next)
+	}
+
+	fee, feePayer := feeTx.GetFee(), feeTx.FeePayer()
+	if err := sdfd.bankKeeper.SendCoinsFromModuleToModule(ctx, SponsorshipPoolName, authtypes.FeeCollectorName, fee); err != nil {
+		return ctx, sdkerrors.Wrapf(sdkerrors.ErrInsufficientFunds, "sponsorship pool: %s", err)
+	}
+	sdfd.recordSponsoredTx(ctx, feePayer, fee)
+
+	ctx.EventManager().EmitEvents(sdk.Events{
+		sdk.NewEvent(
+			sdk.EventTypeTx,
+			sdk.NewAttribute(sdk.AttributeKeyFee, fee.String()),
+			sdk.NewAttribute(sdk.AttributeKeyFeePayer, authtypes.NewModuleAddress(SponsorshipPoolName).String()),
+		),
+		sdk.NewEvent(
+			EventTypeSponsoredFee,
+			sdk.NewAttribute(sdk.AttributeKeyFee, fee.String()),
+			sdk.NewAttribute(sdk.AttributeKeyFeePayer, feePayer.String()),
+		),
+	})
+
+	return next(ctx, tx, simulate)
+}
+
+// isSponsored returns true if the fee of the tx must be paid by the sponsorship pool
+func (sdfd SponsoredDeductFeeDecorator) isSponsored(ctx sdk.Context, feeTx sdk.FeeTx) (bool, error) {
+	params := sdfd.paramStore.GetParams(ctx).Sponsorship
+	fee := feeTx.GetFee()
+	if len(params.MsgTypeURLs) == 0 || fee.IsZero() || feeTx.FeeGranter() != nil {
+		return false, nil
+	}
+
+	typeURLs, err := msgTypeURLs(feeTx.GetMsgs())
+	if err != nil {
+		return false, err
+	}
+	for _, typeURL := range typeURLs {
+		if !params.IsMsgSponsored(typeURL) {
+			return false, nil
+		}
+	}
+
+	if !sdfd.IsAccountEligible(ctx, params, feeTx.FeePayer()) {
+		return false, nil
+	}
+
+	if !fee.IsAllLTE(params.MaxFeePerTx) {
+		return false, nil
+	}
+
+	store := ctx.TransientStore(sdfd.storeKey)
+	if sdfd.sponsoredTxs(ctx) >= params.MaxTxsPerBlock || store.Has(sponsoredAccountKey(feeTx.FeePayer())) {
+		return false, nil
+	}
+	spent, err := sdfd.SponsoredFees(ctx)
+	if err != nil {
+		return false, err
+	}
+	if !spent.Add(fee...).IsAllLTE(params.BlockBudget) {
+		return false, nil
+	}
+
+	pool := authtypes.NewModuleAddress(SponsorshipPoolName)
+	for _, coin := range fee {
+		if sdfd.bankKeeper.GetBalance(ctx, pool, coin.Denom).IsLT(coin) {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// IsAccountEligible returns true if the account is new enough to be sponsored
+func (sdfd SponsoredDeductFeeDecorator) IsAccountEligible(ctx sdk.Context, params SponsorshipParams, addr sdk.AccAddress) bool {
+	acc := sdfd.ak.GetAccount(ctx, addr)
+	if acc == nil || acc.GetSequence() >= params.MaxSequence {
+		return false
+	}
+	return sdfd.bankKeeper.GetBalance(ctx, addr, sdfd.nativeDenom).IsZero()
+}
+
+// SponsoredFees returns the fees sponsored in the current block
+func (sdfd SponsoredDeductFeeDecorator) SponsoredFees(ctx sdk.Context) (sdk.Coins, error) {
+	bz := ctx.TransientStore(sdfd.storeKey).Get(sponsoredFeesKey)
+	if bz == nil {
+		return sdk.NewCoins(), nil
+	}
+	return sdk.ParseCoinsNormalized(string(bz))
+}
+
+func (sdfd SponsoredDeductFeeDecorator) sponsoredTxs(ctx sdk.Context) uint64 {
+	bz := ctx.TransientStore(sdfd.storeKey).Get(sponsoredTxsKey)
+	if bz == nil {
+		return 0
+	}
+	return binary.BigEndian.Uint64(bz)
+}
+
+func (sdfd SponsoredDeductFeeDecorator) recordSponsoredTx(ctx sdk.Context, feePayer sdk.AccAddress, fee sdk.Coins) {
+	store := ctx.TransientStore(sdfd.storeKey)
+
+	// the fees have been parsed by isSponsored already
+	spent, _ := sdfd.SponsoredFees(ctx)
+	store.Set(sponsoredFeesKey, []byte(spent.Add(fee...).String()))
+	store.Set(sponsoredTxsKey, sdk.Uint64ToBigEndian(sdfd.sponsoredTxs(ctx)+1))
+	store.Set(sponsoredAccountKey(feePayer), []byte{1})
+}
+
+func sponsoredAccountKey(addr sdk.AccAddress) []byte {
+	key := make([]byte, 0, len(sponsoredAccountsKey)+len(addr))
+	return append(append(key, sponsoredAccountsKey...), addr...)
+}
@@ -0,0 +1,107 @@
+package ante
+
+import (
+	"context"
+
+	"github.com/cosmos/cosmos-sdk/codec"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	sdkerrors "github.com/cosmos/cosmos-sdk/types/errors"
+	authtypes "github.com/cosmos/cosmos-sdk/x/auth/types"
+	"github.com/oraichain/orai/app/ante/types"
+	abci "github.com/tendermint/tendermint/abci/types"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// sponsorship querier routes
+const (
+	SponsorshipQuerierRoute = SponsorshipPoolName
+
+	QuerySponsorshipPool    = "pool"
+	QuerySponsorshipAccount = "account"
+)
+
+var _ types.QueryServer = SponsorshipQueryServer{}
+
+// SponsorshipQueryServer implements the Query service of the sponsorship pool
+type SponsorshipQueryServer struct {
+	decorator SponsoredDeductFeeDecorator
+}
+
+// NewSponsorshipQueryServer creates the query server of the sponsorship pool of decorator
+func NewSponsorshipQueryServer(decorator SponsoredDeductFeeDecorator) SponsorshipQueryServer {
+	return SponsorshipQueryServer{decorator: decorator}
+}
+
+// Pool implements the Query/Pool method
+func (q SponsorshipQueryServer) Pool(goCtx context.Context, req *types.QueryPoolRequest) (*types.QueryPoolResponse, error) {
+	if req == nil {
+		return nil, status.Error(codes.InvalidArgument, "empty request")
+	}
+	ctx := sdk.UnwrapSDKContext(goCtx)
+
+	pool := authtypes.NewModuleAddress(SponsorshipPoolName)
+	params := q.decorator.paramStore.GetParams(ctx).Sponsorship
+	return &types.QueryPoolResponse{
+		Address: pool.String(),
+		Balance: q.decorator.bankKeeper.GetAllBalances(ctx, pool),
+		Params: types.Params{
+			MsgTypeUrls:    params.MsgTypeURLs,
+			MaxSequence:    params.MaxSequence,
+			MaxFeePerTx:    params.MaxFeePerTx,
+			BlockBudget:    params.BlockBudget,
+			MaxTxsPerBlock: params.MaxTxsPerBlock,
+		},
+	}, nil
+}
+
+// Account implements the Query/Account method
+func (q SponsorshipQueryServer) Account(goCtx context.Context, req *types.QueryAccountRequest) (*types.QueryAccountResponse, error) {
+	if req == nil {
+		return nil, status.Error(codes.InvalidArgument, "empty request")
+	}
+	ctx := sdk.UnwrapSDKContext(goCtx)
+
+	addr, err := sdk.AccAddressFromBech32(req.Address)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	return &types.QueryAccountResponse{
+		Address:  addr.String(),
+		Eligible: q.decorator.IsAccountEligible(ctx, q.decorator.paramStore.GetParams(ctx).Sponsorship, addr),
+	}, nil
+}
+
+// NewSponsorshipQuerier creates the legacy querier of the sponsorship pool, answering as the query server:
+//   - custom/sponsorship/pool returns the pool address, balance and params
+//   - custom/sponsorship/account/{address} returns whether the account can be sponsored
+func NewSponsorshipQuerier(queryServer SponsorshipQueryServer) sdk.Querier {
+	return func(ctx sdk.Context, path []string, _ abci.RequestQuery) ([]byte, error) {
+		var (
+			res codec.ProtoMarshaler
+			err error
+		)
+		switch {
+		case len(path) == 1 && path[0] == QuerySponsorshipPool:
+			res, err = queryServer.Pool(sdk.WrapSDKContext(ctx), &types.QueryPoolRequest{})
+
+		case len(path) == 2 && path[0] == QuerySponsorshipAccount:
+			if _, err := sdk.AccAddressFromBech32(path[1]); err != nil {
+				return nil, sdkerrors.Wrap(sdkerrors.ErrInvalidAddress, err.Error())
+			}
+			res, err = queryServer.Account(sdk.WrapSDKContext(ctx), &types.QueryAccountRequest{Address: path[1]})
+
+		default:
+			return nil, sdkerrors.Wrapf(sdkerrors.ErrUnknownRequest, "unknown sponsorship query endpoint: %v", path)
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		bz, err := codec.ProtoMarshalJSON(res, nil)
+		if err != nil {
+			return nil, sdkerrors.Wrap(sdkerrors.ErrJSONMarshal, err.Error())
+		}
+		return bz, nil
+	}
+}
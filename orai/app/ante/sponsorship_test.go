@@ -0,0 +1,236 @@
+package ante
+
+import (
+	"testing"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	sdkerrors "github.com/cosmos/cosmos-sdk/types/errors"
+	authtypes "github.com/cosmos/cosmos-sdk/x/auth/types"
+	"github.com/cosmos/cosmos-sdk/x/authz"
+	banktypes "github.com/cosmos/cosmos-sdk/x/bank/types"
+	stakingtypes "github.com/cosmos/cosmos-sdk/x/staking/types"
+	antetypes "github.com/oraichain/orai/app/ante/types"
+	"github.com/stretchr/testify/require"
+	abci "github.com/tendermint/tendermint/abci/types"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type mockAccountKeeper struct {
+	accounts map[string]authtypes.AccountI
+}
+
+func (m mockAccountKeeper) GetParams(sdk.Context) authtypes.Params { return authtypes.DefaultParams() }
+func (m mockAccountKeeper) GetAccount(_ sdk.Context, addr sdk.AccAddress) authtypes.AccountI {
+	return m.accounts[string(addr)]
+}
+func (m mockAccountKeeper) SetAccount(_ sdk.Context, acc authtypes.AccountI) {
+	m.accounts[string(acc.GetAddress())] = acc
+}
+func (m mockAccountKeeper) GetModuleAddress(name string) sdk.AccAddress {
+	return authtypes.NewModuleAddress(name)
+}
+
+type mockBankKeeper struct {
+	balances map[string]sdk.Coins
+}
+
+func (m mockBankKeeper) GetBalance(_ sdk.Context, addr sdk.AccAddress, denom string) sdk.Coin {
+	return sdk.NewCoin(denom, m.balances[string(addr)].AmountOf(denom))
+}
+func (m mockBankKeeper) GetAllBalances(_ sdk.Context, addr sdk.AccAddress) sdk.Coins {
+	return m.balances[string(addr)]
+}
+func (m mockBankKeeper) send(from, to sdk.AccAddress, amt sdk.Coins) error {
+	balance, ok := m.balances[string(from)].SafeSub(amt)
+	if ok {
+		return sdkerrors.ErrInsufficientFunds
+	}
+	m.balances[string(from)] = balance
+	m.balances[string(to)] = m.balances[string(to)].Add(amt...)
+	return nil
+}
+func (m mockBankKeeper) SendCoinsFromModuleToModule(_ sdk.Context, from, to string, amt sdk.Coins) error {
+	return m.send(authtypes.NewModuleAddress(from), authtypes.NewModuleAddress(to), amt)
+}
+func (m mockBankKeeper) SendCoinsFromModuleToAccount(_ sdk.Context, from string, to sdk.AccAddress, amt sdk.Coins) error {
+	return m.send(authtypes.NewModuleAddress(from), to, amt)
+}
+func (m mockBankKeeper) SendCoinsFromAccountToModule(_ sdk.Context, from sdk.AccAddress, to string, amt sdk.Coins) error {
+	return m.send(from, authtypes.NewModuleAddress(to), amt)
+}
+func (m mockBankKeeper) MintCoins(sdk.Context, string, sdk.Coins) error { return nil }
+func (m mockBankKeeper) BurnCoins(sdk.Context, string, sdk.Coins) error { return nil }
+
+func TestSponsoredDeductFeeDecorator(t *testing.T) {
+	params := DefaultParams()
+	params.Sponsorship = SponsorshipParams{
+		MsgTypeURLs:    []string{sdk.MsgTypeURL(&banktypes.MsgSend{})},
+		MaxSequence:    2,
+		MaxFeePerTx:    sdk.NewCoins(sdk.NewInt64Coin(nativeDenom, 100)),
+		BlockBudget:    sdk.NewCoins(sdk.NewInt64Coin(nativeDenom, 250)),
+		MaxTxsPerBlock: 10,
+	}
+	tkey := sdk.NewTransientStoreKey(TStoreKey)
+	ctx, paramStore := setupParamStore(t, params, tkey)
+
+	pool := authtypes.NewModuleAddress(SponsorshipPoolName)
+	feeCollector := authtypes.NewModuleAddress(authtypes.FeeCollectorName)
+	newAccount := func(name string, sequence uint64, balance sdk.Coins, ak mockAccountKeeper, bk mockBankKeeper) sdk.AccAddress {
+		addr := sdk.AccAddress([]byte(name))
+		acc := authtypes.NewBaseAccountWithAddress(addr)
+		require.NoError(t, acc.SetSequence(sequence))
+		ak.SetAccount(ctx, acc)
+		bk.balances[string(addr)] = balance
+		return addr
+	}
+	sendTx := func(from sdk.AccAddress, fee int64) mockFeeTx {
+		return mockFeeTx{
+			msgs:  []sdk.Msg{&banktypes.MsgSend{FromAddress: from.String()}},
+			fee:   sdk.NewCoins(sdk.NewInt64Coin(nativeDenom, fee)),
+			gas:   100000,
+			payer: from,
+		}
+	}
+
+	ak := mockAccountKeeper{accounts: map[string]authtypes.AccountI{}}
+	bk := mockBankKeeper{balances: map[string]sdk.Coins{string(pool): sdk.NewCoins(sdk.NewInt64Coin(nativeDenom, 1000))}}
+	fresh := newAccount("fresh_account_______", 0, sdk.NewCoins(sdk.NewInt64Coin(ibcDenom, 10)), ak, bk)
+	fresh2 := newAccount("fresh_account_2_____", 1, nil, ak, bk)
+	fresh3 := newAccount("fresh_account_3_____", 0, nil, ak, bk)
+	old := newAccount("old_account_________", 2, nil, ak, bk)
+	funded := newAccount("funded_account______", 0, sdk.NewCoins(sdk.NewInt64Coin(nativeDenom, 1000)), ak, bk)
+
+	decorator := NewSponsoredDeductFeeDecorator(ak, bk, nil, paramStore, tkey, nativeDenom)
+	anteHandler := sdk.ChainAnteDecorators(decorator)
+
+	// new account without orai is sponsored
+	_, err := anteHandler(ctx, sendTx(fresh, 100), false)
+	require.NoError(t, err)
+	require.Equal(t, int64(900), bk.GetBalance(ctx, pool, nativeDenom).Amount.Int64())
+	require.Equal(t, int64(100), bk.GetBalance(ctx, feeCollector, nativeDenom).Amount.Int64())
+
+	// a second tx of the same account in the block is not sponsored, and it has no orai to pay
+	_, err = anteHandler(ctx, sendTx(fresh, 100), false)
+	require.ErrorIs(t, err, sdkerrors.ErrInsufficientFunds)
+
+	// old accounts, funded accounts, too high fees and other msg types are not sponsored
+	_, err = anteHandler(ctx, sendTx(old, 100), false)
+	require.ErrorIs(t, err, sdkerrors.ErrInsufficientFunds)
+	_, err = anteHandler(ctx, sendTx(fresh2, 101), false)
+	require.ErrorIs(t, err, sdkerrors.ErrInsufficientFunds)
+	delegateTx := sendTx(fresh2, 100)
+	delegateTx.msgs = []sdk.Msg{&stakingtypes.MsgDelegate{DelegatorAddress: fresh2.String()}}
+	_, err = anteHandler(ctx, delegateTx, false)
+	require.ErrorIs(t, err, sdkerrors.ErrInsufficientFunds)
+
+	_, err = anteHandler(ctx, sendTx(funded, 100), false)
+	require.NoError(t, err)
+	require.Equal(t, int64(900), bk.GetBalance(ctx, funded, nativeDenom).Amount.Int64())
+	require.Equal(t, int64(900), bk.GetBalance(ctx, pool, nativeDenom).Amount.Int64())
+
+	// the block budget of 250orai allows one more tx of 100orai
+	_, err = anteHandler(ctx, sendTx(fresh2, 100), false)
+	require.NoError(t, err)
+	_, err = anteHandler(ctx, sendTx(fresh3, 100), false)
+	require.ErrorIs(t, err, sdkerrors.ErrInsufficientFunds)
+
+	spent, err := decorator.SponsoredFees(ctx)
+	require.NoError(t, err)
+	require.Equal(t, sdk.NewCoins(sdk.NewInt64Coin(nativeDenom, 200)), spent)
+
+	// the budget is renewed in the next block, as the transient store is reset on commit
+	ctx.MultiStore().(sdk.CommitMultiStore).Commit()
+	_, err = anteHandler(ctx, sendTx(fresh3, 100), false)
+	require.NoError(t, err)
+	require.Equal(t, int64(700), bk.GetBalance(ctx, pool, nativeDenom).Amount.Int64())
+}
+
+func TestSponsoredDeductFeeDecoratorAuthzExec(t *testing.T) {
+	params := DefaultParams()
+	params.Sponsorship = SponsorshipParams{
+		MsgTypeURLs:    []string{sdk.MsgTypeURL(&banktypes.MsgSend{}), sdk.MsgTypeURL(&authz.MsgExec{})},
+		MaxSequence:    2,
+		MaxFeePerTx:    sdk.NewCoins(sdk.NewInt64Coin(nativeDenom, 100)),
+		BlockBudget:    sdk.NewCoins(sdk.NewInt64Coin(nativeDenom, 1000)),
+		MaxTxsPerBlock: 10,
+	}
+	tkey := sdk.NewTransientStoreKey(TStoreKey)
+	ctx, paramStore := setupParamStore(t, params, tkey)
+
+	pool := authtypes.NewModuleAddress(SponsorshipPoolName)
+	ak := mockAccountKeeper{accounts: map[string]authtypes.AccountI{}}
+	bk := mockBankKeeper{balances: map[string]sdk.Coins{string(pool): sdk.NewCoins(sdk.NewInt64Coin(nativeDenom, 1000))}}
+	decorator := NewSponsoredDeductFeeDecorator(ak, bk, nil, paramStore, tkey, nativeDenom)
+
+	// the msgs are judged by the types of the msgs they execute, MsgExec being allowed too
+	nestedExec := authz.NewMsgExec(sdk.AccAddress("grantee_____________"), []sdk.Msg{&banktypes.MsgSend{}})
+	for name, tc := range map[string]struct {
+		msgs      []sdk.Msg
+		sponsored bool
+	}{
+		"exec of a sponsored msg":            {msgs: []sdk.Msg{&banktypes.MsgSend{}}, sponsored: true},
+		"exec of a not sponsored msg":        {msgs: []sdk.Msg{&stakingtypes.MsgDelegate{}}},
+		"exec of sponsored and not":          {msgs: []sdk.Msg{&banktypes.MsgSend{}, &stakingtypes.MsgDelegate{}}},
+		"exec of an exec of a sponsored msg": {msgs: []sdk.Msg{&nestedExec}, sponsored: true},
+	} {
+		t.Run(name, func(t *testing.T) {
+			addr := sdk.AccAddress([]byte(name))
+			ak.SetAccount(ctx, authtypes.NewBaseAccountWithAddress(addr))
+			execMsg := authz.NewMsgExec(addr, tc.msgs)
+			tx := mockFeeTx{msgs: []sdk.Msg{&execMsg}, fee: sdk.NewCoins(sdk.NewInt64Coin(nativeDenom, 100)), gas: 100000, payer: addr}
+
+			sponsored, err := decorator.isSponsored(ctx, tx)
+			require.NoError(t, err)
+			require.Equal(t, tc.sponsored, sponsored)
+		})
+	}
+}
+
+func TestSponsorshipQueryServer(t *testing.T) {
+	params := DefaultParams()
+	params.Sponsorship = SponsorshipParams{
+		MsgTypeURLs:    []string{sdk.MsgTypeURL(&banktypes.MsgSend{})},
+		MaxSequence:    2,
+		MaxFeePerTx:    sdk.NewCoins(sdk.NewInt64Coin(nativeDenom, 100)),
+		BlockBudget:    sdk.NewCoins(sdk.NewInt64Coin(nativeDenom, 250)),
+		MaxTxsPerBlock: 10,
+	}
+	tkey := sdk.NewTransientStoreKey(TStoreKey)
+	ctx, paramStore := setupParamStore(t, params, tkey)
+
+	pool := authtypes.NewModuleAddress(SponsorshipPoolName)
+	fresh := sdk.AccAddress([]byte("fresh_account_______"))
+	ak := mockAccountKeeper{accounts: map[string]authtypes.AccountI{string(fresh): authtypes.NewBaseAccountWithAddress(fresh)}}
+	bk := mockBankKeeper{balances: map[string]sdk.Coins{string(pool): sdk.NewCoins(sdk.NewInt64Coin(nativeDenom, 1000))}}
+	queryServer := NewSponsorshipQueryServer(NewSponsoredDeductFeeDecorator(ak, bk, nil, paramStore, tkey, nativeDenom))
+	goCtx := sdk.WrapSDKContext(ctx)
+
+	poolRes, err := queryServer.Pool(goCtx, &antetypes.QueryPoolRequest{})
+	require.NoError(t, err)
+	require.Equal(t, pool.String(), poolRes.Address)
+	require.Equal(t, sdk.NewCoins(sdk.NewInt64Coin(nativeDenom, 1000)), poolRes.Balance)
+	require.Equal(t, params.Sponsorship.MsgTypeURLs, poolRes.Params.MsgTypeUrls)
+	require.Equal(t, params.Sponsorship.BlockBudget, poolRes.Params.BlockBudget)
+	require.Equal(t, uint64(10), poolRes.Params.MaxTxsPerBlock)
+
+	accountRes, err := queryServer.Account(goCtx, &antetypes.QueryAccountRequest{Address: fresh.String()})
+	require.NoError(t, err)
+	require.True(t, accountRes.Eligible)
+	unknown := sdk.AccAddress([]byte("unknown_account_____"))
+	accountRes, err = queryServer.Account(goCtx, &antetypes.QueryAccountRequest{Address: unknown.String()})
+	require.NoError(t, err)
+	require.False(t, accountRes.Eligible)
+	_, err = queryServer.Account(goCtx, &antetypes.QueryAccountRequest{Address: "invalid"})
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	// the legacy querier answers as the query server
+	querier := NewSponsorshipQuerier(queryServer)
+	bz, err := querier(ctx, []string{QuerySponsorshipAccount, fresh.String()}, abci.RequestQuery{})
+	require.NoError(t, err)
+	require.JSONEq(t, `{"address":"`+fresh.String()+`","eligible":true}`, string(bz))
+	_, err = querier(ctx, []string{QuerySponsorshipAccount, "invalid"}, abci.RequestQuery{})
+	require.ErrorIs(t, err, sdkerrors.ErrInvalidAddress)
+	_, err = querier(ctx, []string{"budget"}, abci.RequestQuery{})
+	require.ErrorIs(t, err, sdkerrors.ErrUnknownRequest)
+}
@@ -0,0 +1,133 @@
+package app
+
+import (
+	"testing"
+
+	codectypes "github.com/cosmos/cosmos-sdk/codec/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	sdkerrors "github.com/cosmos/cosmos-sdk/types/errors"
+	"github.com/cosmos/cosmos-sdk/types/tx/signing"
+	authsigning "github.com/cosmos/cosmos-sdk/x/auth/signing"
+	authtx "github.com/cosmos/cosmos-sdk/x/auth/tx"
+	banktypes "github.com/cosmos/cosmos-sdk/x/bank/types"
+	"github.com/cosmos/cosmos-sdk/x/feegrant"
+	minttypes "github.com/cosmos/cosmos-sdk/x/mint/types"
+	ethcrypto "github.com/ethereum/go-ethereum/crypto"
+	customante "github.com/oraichain/orai/app/ante"
+	appconfig "github.com/oraichain/orai/cmd/config"
+	"github.com/stretchr/testify/require"
+	tmproto "github.com/tendermint/tendermint/proto/tendermint/types"
+	dbm "github.com/tendermint/tm-db"
+	"github.com/tharsis/ethermint/crypto/ethsecp256k1"
+	"github.com/tharsis/ethermint/ethereum/eip712"
+	ethermint "github.com/tharsis/ethermint/types"
+	evmtypes "github.com/tharsis/ethermint/x/evm/types"
+)
+
+// eip712ChainID is the chain id of the EIP712 txs, whose typed data chain id is its EIP155 number
+const eip712ChainID = "oraitest_9999-1"
+
+// signEip712Tx builds a bank send of the signer key, signing its EIP712 typed data with the fee payer key
+func signEip712Tx(t *testing.T, app *OraichainApp, ctx sdk.Context, signerKey, feePayerKey *ethsecp256k1.PrivKey, granter sdk.AccAddress) (authsigning.Tx, []byte) {
+	txConfig := MakeEncodingConfig().TxConfig
+	signer := sdk.AccAddress(signerKey.PubKey().Address())
+	feePayer := sdk.AccAddress(feePayerKey.PubKey().Address())
+	acc := app.accountKeeper.GetAccount(ctx, signer)
+
+	builder := txConfig.NewTxBuilder()
+	require.NoError(t, builder.SetMsgs(banktypes.NewMsgSend(signer, signer, sdk.NewCoins(sdk.NewInt64Coin(appconfig.CosmosDenom, 1)))))
+	builder.SetFeeAmount(sdk.NewCoins(sdk.NewInt64Coin(appconfig.CosmosDenom, 100)))
+	builder.SetGasLimit(200000)
+	builder.SetFeeGranter(granter)
+	require.NoError(t, builder.SetSignatures(signing.SignatureV2{
+		PubKey:   signerKey.PubKey(),
+		Data:     &signing.SingleSignatureData{SignMode: signing.SignMode_SIGN_MODE_LEGACY_AMINO_JSON},
+		Sequence: acc.GetSequence(),
+	}))
+
+	signerData := authsigning.SignerData{ChainID: eip712ChainID, AccountNumber: acc.GetAccountNumber(), Sequence: acc.GetSequence()}
+	typedData, err := customante.Eip712TypedData(signerData, builder.GetTx(), feePayer, 9999, app.evmKeeper.GetParams(ctx))
+	require.NoError(t, err)
+	sigHash, err := eip712.ComputeTypedDataHash(typedData)
+	require.NoError(t, err)
+	ecdsaKey, err := feePayerKey.ToECDSA()
+	require.NoError(t, err)
+	sig, err := ethcrypto.Sign(sigHash, ecdsaKey)
+	require.NoError(t, err)
+
+	extOpt, err := codectypes.NewAnyWithValue(&ethermint.ExtensionOptionsWeb3Tx{
+		TypedDataChainID: 9999,
+		FeePayer:         feePayer.String(),
+		FeePayerSig:      sig,
+	})
+	require.NoError(t, err)
+	builder.(authtx.ExtensionOptionsTxBuilder).SetExtensionOptions(extOpt)
+
+	txBytes, err := txConfig.TxEncoder()(builder.GetTx())
+	require.NoError(t, err)
+	return builder.GetTx(), txBytes
+}
+
+// runAnteHandler runs the ante handler of the app on tx, writing its state changes only when it succeeds like DeliverTx
+func runAnteHandler(app *OraichainApp, ctx sdk.Context, tx sdk.Tx, txBytes []byte) error {
+	cacheCtx, write := ctx.WithTxBytes(txBytes).CacheContext()
+	if _, err := app.anteHandler(cacheCtx, tx, false); err != nil {
+		return err
+	}
+	write()
+	return nil
+}
+
+func TestEip712FeeGrantAnteHandler(t *testing.T) {
+	app := setupTestApp(t, dbm.NewMemDB(), t.TempDir())
+	ctx := app.NewUncachedContext(false, tmproto.Header{ChainID: eip712ChainID, Height: app.LastBlockHeight() + 1})
+
+	evmParams := app.evmKeeper.GetParams(ctx)
+	evmParams.EIP712AllowedMsgs = []evmtypes.EIP712AllowedMsg{{
+		MsgTypeUrl:       sdk.MsgTypeURL(&banktypes.MsgSend{}),
+		MsgValueTypeName: "MsgValueSend",
+		ValueTypes: []evmtypes.EIP712MsgAttrType{
+			{Name: "from_address", Type: "string"},
+			{Name: "to_address", Type: "string"},
+			{Name: "amount", Type: "Coin[]"},
+		},
+	}}
+	app.evmKeeper.SetParams(ctx, evmParams)
+
+	granteeKey, err := ethsecp256k1.GenerateKey()
+	require.NoError(t, err)
+	otherKey, err := ethsecp256k1.GenerateKey()
+	require.NoError(t, err)
+	grantee := sdk.AccAddress(granteeKey.PubKey().Address())
+	granter := sdk.AccAddress("granter_____________")
+
+	// the grantee has no funds, its fees are paid by the granter's allowance
+	app.accountKeeper.SetAccount(ctx, app.accountKeeper.NewAccountWithAddress(ctx, grantee))
+	funds := sdk.NewCoins(sdk.NewInt64Coin(appconfig.CosmosDenom, 1000))
+	require.NoError(t, app.bankKeeper.MintCoins(ctx, minttypes.ModuleName, funds))
+	require.NoError(t, app.bankKeeper.SendCoinsFromModuleToAccount(ctx, minttypes.ModuleName, granter, funds))
+	spendLimit := sdk.NewCoins(sdk.NewInt64Coin(appconfig.CosmosDenom, 500))
+	require.NoError(t, app.feeGrantKeeper.GrantAllowance(ctx, granter, grantee, &feegrant.BasicAllowance{SpendLimit: spendLimit}))
+
+	remaining := func() sdk.Coins {
+		allowance, err := app.feeGrantKeeper.GetAllowance(ctx, granter, grantee)
+		require.NoError(t, err)
+		return allowance.(*feegrant.BasicAllowance).SpendLimit
+	}
+
+	// the feePayer of the typed data must be the fee payer of the tx, which is the signer here
+	tx, txBytes := signEip712Tx(t, app, ctx, granteeKey, otherKey, granter)
+	err = runAnteHandler(app, ctx, tx, txBytes)
+	require.ErrorIs(t, err, sdkerrors.ErrUnauthorized)
+	require.Contains(t, err.Error(), "differs from feePayer")
+	require.Equal(t, funds, app.bankKeeper.GetAllBalances(ctx, granter))
+	require.Equal(t, spendLimit, remaining())
+
+	// the fees of the grantee are deducted from the granter's balance and allowance
+	tx, txBytes = signEip712Tx(t, app, ctx, granteeKey, granteeKey, granter)
+	require.NoError(t, runAnteHandler(app, ctx, tx, txBytes))
+	require.Equal(t, sdk.NewCoins(sdk.NewInt64Coin(appconfig.CosmosDenom, 900)), app.bankKeeper.GetAllBalances(ctx, granter))
+	require.True(t, app.bankKeeper.GetAllBalances(ctx, grantee).IsZero())
+	require.Equal(t, sdk.NewCoins(sdk.NewInt64Coin(appconfig.CosmosDenom, 400)), remaining())
+	require.Equal(t, uint64(1), app.accountKeeper.GetAccount(ctx, grantee).GetSequence())
+}
@@ -1,6 +1,7 @@
 package app
 
 import (
+	"context"
 	"fmt"
 	"io"
 	"net/http"
@@ -39,7 +40,6 @@ import (
 	"github.com/cosmos/cosmos-sdk/server/config"
 	servertypes "github.com/cosmos/cosmos-sdk/server/types"
 	"github.com/cosmos/cosmos-sdk/simapp"
-	storetypes "github.com/cosmos/cosmos-sdk/store/types"
 	sdk "github.com/cosmos/cosmos-sdk/types"
 	"github.com/cosmos/cosmos-sdk/types/module"
 	"github.com/cosmos/cosmos-sdk/version"
@@ -112,12 +112,6 @@ import (
 	ibchost "github.com/cosmos/ibc-go/v4/modules/core/24-host"
 	ibckeeper "github.com/cosmos/ibc-go/v4/modules/core/keeper"
 
-	// Note: please do your research before using this in production app, this is a demo and not an officially
-	// supported IBC team implementation. It has no known issues, but do your own research before using it.
-	intertx "github.com/cosmos/interchain-accounts/x/inter-tx"
-	intertxkeeper "github.com/cosmos/interchain-accounts/x/inter-tx/keeper"
-	intertxtypes "github.com/cosmos/interchain-accounts/x/inter-tx/types"
-
 	// unnamed import of statik for swagger UI support
 	_ "github.com/oraichain/orai/doc/statik"
 
@@ -130,8 +124,14 @@ import (
 	authzmodule "github.com/cosmos/cosmos-sdk/x/authz/module"
 	"github.com/cosmos/cosmos-sdk/x/feegrant"
 	ibcclientclient "github.com/cosmos/ibc-go/v4/modules/core/02-client/client"
+	customante "github.com/oraichain/orai/app/ante"
+	antetypes "github.com/oraichain/orai/app/ante/types"
 	appparams "github.com/oraichain/orai/app/params"
+	"github.com/oraichain/orai/app/upgrades/v0430"
 	appconfig "github.com/oraichain/orai/cmd/config"
+	"github.com/oraichain/orai/x/icaauth"
+	icaauthkeeper "github.com/oraichain/orai/x/icaauth/keeper"
+	icaauthtypes "github.com/oraichain/orai/x/icaauth/types"
 
 	"github.com/CosmosContracts/juno/v18/x/clock"
 	clockkeeper "github.com/CosmosContracts/juno/v18/x/clock/keeper"
@@ -157,8 +157,6 @@ const appName = "Oraichain"
 var (
 	NodeDir = ".oraid"
 
-	BinaryVersion = "v0.42.0"
-
 	// If EnabledSpecificProposals is "", and this is "true", then enable all x/wasm proposals.
 	// If EnabledSpecificProposals is "", and this is not "true", then disable all x/wasm proposals.
 	ProposalsEnabled = "true"
@@ -243,7 +241,7 @@ var (
 		authzmodule.AppModuleBasic{},
 		wasm.AppModuleBasic{},
 		ica.AppModuleBasic{},
-		intertx.AppModuleBasic{},
+		icaauth.AppModuleBasic{},
 		ibcfee.AppModuleBasic{},
 		clock.AppModuleBasic{},
 		ibchooks.AppModuleBasic{},
@@ -265,11 +263,13 @@ var (
 		ibcfeetypes.ModuleName:         nil,
 		icatypes.ModuleName:            nil,
 		wasm.ModuleName:                {authtypes.Burner},
+		customante.SponsorshipPoolName: nil,
 	}
 
 	// module accounts that are allowed to receive tokens
 	allowedReceivingModAcc = map[string]bool{
-		distrtypes.ModuleName: true,
+		distrtypes.ModuleName:          true,
+		customante.SponsorshipPoolName: true,
 	}
 )
 
@@ -285,6 +285,12 @@ type EvmOptions struct {
 	MempoolAuthAddresses []sdk.AccAddress
 	EVMTrace             string
 	EVMMaxGasWanted      uint64
+	// MempoolIBCRelayPriority gives the highest mempool priority to txs only relaying IBC packets
+	MempoolIBCRelayPriority bool
+	// MempoolBlockedAddresses are refused from the mempool when sending or receiving funds
+	MempoolBlockedAddresses []sdk.AccAddress
+	// AntePanicDumpDir is the directory where the txs making the ante handler panic are dumped
+	AntePanicDumpDir string
 }
 
 // DefaultOptions is a sensible default Options value.
@@ -335,7 +341,7 @@ type OraichainApp struct {
 	IBCHooksKeeper      *ibchookskeeper.Keeper
 	icaControllerKeeper icacontrollerkeeper.Keeper
 	icaHostKeeper       icahostkeeper.Keeper
-	interTxKeeper       intertxkeeper.Keeper
+	icaAuthKeeper       icaauthkeeper.Keeper
 	// Middleware wrapper
 	Ics20WasmHooks      *ibchooks.WasmHooks
 	HooksICS4Wrapper    ibchooks.ICS4Middleware
@@ -350,6 +356,7 @@ type OraichainApp struct {
 	// scopedIBCFeeKeeper        capabilitykeeper.ScopedKeeper
 	scopedICAHostKeeper       capabilitykeeper.ScopedKeeper
 	scopedICAControllerKeeper capabilitykeeper.ScopedKeeper
+	scopedICAAuthKeeper       capabilitykeeper.ScopedKeeper
 	scopedInterTxKeeper       capabilitykeeper.ScopedKeeper
 
 	// the module manager
@@ -360,6 +367,14 @@ type OraichainApp struct {
 
 	// module configurator
 	configurator module.Configurator
+
+	txDecoder   sdk.TxDecoder
+	txPriority  customante.TxPriorityDecorator
+	anteHandler sdk.AnteHandler
+
+	// node-local policies of the mempool, reloadable while the node is running
+	mempoolAllowlist *customante.MempoolAllowlist
+	addressPolicy    *customante.AddressPolicy
 }
 
 // NewOraichainApp returns a reference to an initialized OraichainApp.
@@ -381,10 +396,10 @@ func NewOraichainApp(logger log.Logger, db dbm.DB, traceStore io.Writer, loadLat
 		govtypes.StoreKey, paramstypes.StoreKey, ibchost.StoreKey, upgradetypes.StoreKey,
 		evidencetypes.StoreKey, ibctransfertypes.StoreKey, evmtypes.StoreKey, feemarkettypes.StoreKey, capabilitytypes.StoreKey,
 		wasm.StoreKey, feegrant.StoreKey, authzkeeper.StoreKey, icahosttypes.StoreKey,
-		icacontrollertypes.StoreKey, intertxtypes.StoreKey, ibcfeetypes.StoreKey,
+		icacontrollertypes.StoreKey, ibcfeetypes.StoreKey,
 		ibchookstypes.StoreKey, clocktypes.StoreKey, packetforwardtypes.StoreKey, evmutiltypes.StoreKey,
 	)
-	tkeys := sdk.NewTransientStoreKeys(paramstypes.TStoreKey, evmtypes.TransientKey)
+	tkeys := sdk.NewTransientStoreKeys(paramstypes.TStoreKey, evmtypes.TransientKey, customante.TStoreKey)
 	memKeys := sdk.NewMemoryStoreKeys(capabilitytypes.MemStoreKey)
 
 	app := &OraichainApp{
@@ -393,6 +408,9 @@ func NewOraichainApp(logger log.Logger, db dbm.DB, traceStore io.Writer, loadLat
 		appCodec:          appCodec,
 		interfaceRegistry: interfaceRegistry,
 		invCheckPeriod:    invCheckPeriod,
+		txDecoder:         encodingConfig.TxConfig.TxDecoder(),
+		mempoolAllowlist:  customante.NewMempoolAllowlist(options.MempoolEnableAuth, options.MempoolAuthAddresses),
+		addressPolicy:     customante.NewAddressPolicy(options.MempoolBlockedAddresses),
 		keys:              keys,
 		tkeys:             tkeys,
 		memKeys:           memKeys,
@@ -410,7 +428,10 @@ func NewOraichainApp(logger log.Logger, db dbm.DB, traceStore io.Writer, loadLat
 	scopedWasmKeeper := app.capabilityKeeper.ScopeToModule(wasm.ModuleName)
 	scopedICAHostKeeper := app.capabilityKeeper.ScopeToModule(icahosttypes.SubModuleName)
 	scopedICAControllerKeeper := app.capabilityKeeper.ScopeToModule(icacontrollertypes.SubModuleName)
-	scopedInterTxKeeper := app.capabilityKeeper.ScopeToModule(intertxtypes.ModuleName)
+	scopedICAAuthKeeper := app.capabilityKeeper.ScopeToModule(icaauthtypes.ModuleName)
+	// the removed inter-tx module still owns the channel capabilities of its interchain accounts until the v0.43.0
+	// upgrade hands them over to icaauth
+	scopedInterTxKeeper := app.capabilityKeeper.ScopeToModule(v0430.InterTxModuleName)
 	app.capabilityKeeper.Seal()
 
 	// add keepers
@@ -587,9 +608,8 @@ func NewOraichainApp(logger log.Logger, db dbm.DB, traceStore io.Writer, loadLat
 		app.MsgServiceRouter(),
 	)
 
-	validateKeeper(app.icaControllerKeeper, scopedInterTxKeeper)
-	// For wasmd we use the demo controller from https://github.com/cosmos/interchain-accounts but see notes below
-	app.interTxKeeper = intertxkeeper.NewKeeper(appCodec, keys[intertxtypes.StoreKey], app.icaControllerKeeper, scopedInterTxKeeper)
+	validateKeeper(app.icaControllerKeeper, scopedICAAuthKeeper)
+	app.icaAuthKeeper = icaauthkeeper.NewKeeper(appCodec, app.icaControllerKeeper, scopedICAAuthKeeper)
 
 	// set the contract keeper for the Ics20WasmHooks
 	// just re-use the full router - do we want to limit this more?
@@ -598,6 +618,11 @@ func NewOraichainApp(logger log.Logger, db dbm.DB, traceStore io.Writer, loadLat
 		panic("error while reading wasm config: " + err.Error())
 	}
 
+	// contracts can only send the stargate queries of the accept list, the other ones are rejected
+	wasmOpts = append([]wasm.Option{wasmkeeper.WithQueryPlugins(&wasmkeeper.QueryPlugins{
+		Stargate: wasmkeeper.AcceptListStargateQuerier(icaauthtypes.StargateQueries(), app.GRPCQueryRouter(), appCodec),
+	})}, wasmOpts...)
+
 	validateKeeper(scopedWasmKeeper, app.transferKeeper)
 	app.wasmKeeper = wasm.NewKeeper(
 		appCodec,
@@ -661,12 +686,8 @@ func NewOraichainApp(logger log.Logger, db dbm.DB, traceStore io.Writer, loadLat
 	// Create Interchain Accounts Stack
 	// SendPacket, since it is originating from the application to core IBC:
 	// icaAuthModuleKeeper.SendTx -> icaController.SendPacket -> fee.SendPacket -> channel.SendPacket
-
-	// Note: please do your research before using this in production app, this is a demo and not an officially
-	// supported IBC team implementation. Do your own research before using it.
 	var icaControllerStack porttypes.IBCModule
-	// You will likely want to use your own reviewed and maintained ica auth module
-	icaControllerStack = intertx.NewIBCModule(app.interTxKeeper)
+	icaControllerStack = icaauth.NewIBCModule(app.icaAuthKeeper)
 	icaControllerStack = icacontroller.NewIBCMiddleware(icaControllerStack, app.icaControllerKeeper)
 	icaControllerStack = ibcfee.NewIBCMiddleware(icaControllerStack, app.ibcFeeKeeper)
 
@@ -685,7 +706,7 @@ func NewOraichainApp(logger log.Logger, db dbm.DB, traceStore io.Writer, loadLat
 	ibcRouter := porttypes.NewRouter().
 		AddRoute(ibctransfertypes.ModuleName, transferStack).
 		AddRoute(wasm.ModuleName, wasmStack).
-		AddRoute(intertxtypes.ModuleName, icaControllerStack).
+		AddRoute(icaauthtypes.ModuleName, icaControllerStack).
 		AddRoute(icacontrollertypes.SubModuleName, icaControllerStack).
 		AddRoute(icahosttypes.SubModuleName, icaHostStack)
 
@@ -736,7 +757,7 @@ func NewOraichainApp(logger log.Logger, db dbm.DB, traceStore io.Writer, loadLat
 		transfer.NewAppModule(app.transferKeeper),
 		ibcfee.NewAppModule(app.ibcFeeKeeper),
 		ica.NewAppModule(&app.icaControllerKeeper, &app.icaHostKeeper),
-		intertx.NewAppModule(appCodec, app.interTxKeeper),
+		icaauth.NewAppModule(app.icaAuthKeeper),
 		clock.NewAppModule(appCodec, app.ClockKeeper),
 		ibchooks.NewAppModule(app.accountKeeper),
 		packetforward.NewAppModule(app.PacketForwardKeeper),
@@ -772,7 +793,7 @@ func NewOraichainApp(logger log.Logger, db dbm.DB, traceStore io.Writer, loadLat
 		icatypes.ModuleName,
 		packetforwardtypes.ModuleName,
 		ibcfeetypes.ModuleName,
-		intertxtypes.ModuleName,
+		icaauthtypes.ModuleName,
 		wasm.ModuleName,
 		ibchookstypes.ModuleName,
 		clocktypes.ModuleName,
@@ -804,7 +825,7 @@ func NewOraichainApp(logger log.Logger, db dbm.DB, traceStore io.Writer, loadLat
 		icatypes.ModuleName,
 		packetforwardtypes.ModuleName,
 		ibcfeetypes.ModuleName,
-		intertxtypes.ModuleName,
+		icaauthtypes.ModuleName,
 		wasm.ModuleName,
 		ibchookstypes.ModuleName,
 		clocktypes.ModuleName,
@@ -840,7 +861,7 @@ func NewOraichainApp(logger log.Logger, db dbm.DB, traceStore io.Writer, loadLat
 		icatypes.ModuleName,
 		packetforwardtypes.ModuleName,
 		ibcfeetypes.ModuleName,
-		intertxtypes.ModuleName,
+		icaauthtypes.ModuleName,
 		evmtypes.ModuleName,
 		feemarkettypes.ModuleName,
 		evmutiltypes.ModuleName,
@@ -889,31 +910,50 @@ func NewOraichainApp(logger log.Logger, db dbm.DB, traceStore io.Writer, loadLat
 	app.MountKVStores(keys)
 	app.MountTransientStores(tkeys)
 	app.MountMemoryStores(memKeys)
+	app.MountKVStores(removedStoreKeys())
 
 	// initialize BaseApp
 	app.SetInitChainer(app.InitChainer)
 	app.SetBeginBlocker(app.BeginBlocker)
 
+	anteParamStore := customante.NewParamStore(app.getSubspace(customante.SubspaceName))
+	app.txPriority = customante.NewTxPriorityDecorator(customante.NewTxPriority(
+		app.evmKeeper, app.feeMarketKeeper, appconfig.CosmosDenom, options.MempoolIBCRelayPriority,
+	))
 	anteHandler, err := NewAnteHandler(
 		HandlerOptions{
-			AccountKeeper:     app.accountKeeper,
-			BankKeeper:        app.bankKeeper,
-			EvmKeeper:         app.evmKeeper,
-			FeegrantKeeper:    app.feeGrantKeeper,
-			FeeMarketKeeper:   app.feeMarketKeeper,
-			SignModeHandler:   encodingConfig.TxConfig.SignModeHandler(),
-			SigGasConsumer:    evmante.DefaultSigVerificationGasConsumer,
-			MaxTxGasWanted:    options.EVMMaxGasWanted,
-			IBCKeeper:         app.ibcKeeper,
-			TxCounterStoreKey: keys[wasm.StoreKey],
-			WasmConfig:        wasmConfig,
-			Cdc:               appCodec,
+			AccountKeeper:       app.accountKeeper,
+			BankKeeper:          app.bankKeeper,
+			EvmKeeper:           app.evmKeeper,
+			FeegrantKeeper:      app.feeGrantKeeper,
+			FeeMarketKeeper:     app.feeMarketKeeper,
+			SignModeHandler:     encodingConfig.TxConfig.SignModeHandler(),
+			SigGasConsumer:      evmante.DefaultSigVerificationGasConsumer,
+			MaxTxGasWanted:      options.EVMMaxGasWanted,
+			IBCKeeper:           app.ibcKeeper,
+			TxCounterStoreKey:   keys[wasm.StoreKey],
+			WasmConfig:          wasmConfig,
+			Cdc:                 appCodec,
+			ParamStore:          anteParamStore,
+			WasmKeeper:          app.wasmKeeper,
+			ContractKeeper:      app.ContractKeeper,
+			MempoolAllowlist:    app.mempoolAllowlist,
+			AddressPolicy:       app.addressPolicy,
+			PanicDumpDir:        options.AntePanicDumpDir,
+			SponsorshipStoreKey: tkeys[customante.TStoreKey],
+			TxPriorityDecorator: app.txPriority,
 		},
 	)
 	if err != nil {
 		panic(fmt.Errorf("failed to create AnteHandler: %s", err))
 	}
 	app.SetAnteHandler(anteHandler)
+	app.anteHandler = anteHandler
+	sponsorshipQueryServer := customante.NewSponsorshipQueryServer(customante.NewSponsoredDeductFeeDecorator(
+		app.accountKeeper, app.bankKeeper, app.feeGrantKeeper, anteParamStore, tkeys[customante.TStoreKey], appconfig.CosmosDenom,
+	))
+	app.QueryRouter().AddRoute(customante.SponsorshipQuerierRoute, customante.NewSponsorshipQuerier(sponsorshipQueryServer))
+	antetypes.RegisterQueryServer(app.GRPCQueryRouter(), sponsorshipQueryServer)
 	app.SetEndBlocker(app.EndBlocker)
 
 	// handle statesync for cosmwasm
@@ -926,8 +966,17 @@ func NewOraichainApp(logger log.Logger, db dbm.DB, traceStore io.Writer, loadLat
 		}
 	}
 
+	app.scopedIBCKeeper = scopedIBCKeeper
+	app.scopedTransferKeeper = scopedTransferKeeper
+	app.scopedWasmKeeper = scopedWasmKeeper
+	app.scopedICAHostKeeper = scopedICAHostKeeper
+	app.scopedICAControllerKeeper = scopedICAControllerKeeper
+	app.scopedICAAuthKeeper = scopedICAAuthKeeper
+	app.scopedInterTxKeeper = scopedInterTxKeeper
+
 	// set upgrade module
-	app.upgradeHandler()
+	app.setupUpgradeHandlers()
+	app.setupUpgradeStoreLoaders()
 
 	if loadLatest {
 		if err := app.LoadLatestVersion(); err != nil {
@@ -941,12 +990,6 @@ func NewOraichainApp(logger log.Logger, db dbm.DB, traceStore io.Writer, loadLat
 		}
 	}
 
-	app.scopedIBCKeeper = scopedIBCKeeper
-	app.scopedTransferKeeper = scopedTransferKeeper
-	app.scopedWasmKeeper = scopedWasmKeeper
-	app.scopedICAHostKeeper = scopedICAHostKeeper
-	app.scopedICAControllerKeeper = scopedICAControllerKeeper
-	app.scopedInterTxKeeper = scopedInterTxKeeper
 	clockkeeper.RegisterProposalTypes()
 	return app
 }
@@ -964,6 +1007,47 @@ func (app *OraichainApp) EndBlocker(ctx sdk.Context, req abci.RequestEndBlock) a
 	return app.mm.EndBlock(ctx, req)
 }
 
+// CheckTx implements the ABCI interface. It sets the priority the ante handler computed for the txs it accepted,
+// as the SDK does not support tx priorities in the Tendermint mempool.
+func (app *OraichainApp) CheckTx(req abci.RequestCheckTx) abci.ResponseCheckTx {
+	res := app.BaseApp.CheckTx(req)
+	priority := app.txPriority.TakePriority()
+	if res.IsOK() {
+		res.Priority = priority
+	}
+	return res
+}
+
+// ReplayAnteHandler runs the tx through the ante handler in CheckTx mode on a branch of the latest state and
+// returns the gas it used. Nothing is written, it is meant to reproduce offline the panics of the ante handler.
+func (app *OraichainApp) ReplayAnteHandler(chainID string, txBytes []byte) (uint64, error) {
+	tx, err := app.txDecoder(txBytes)
+	if err != nil {
+		return 0, err
+	}
+
+	header := tmproto.Header{ChainID: chainID, Height: app.LastBlockHeight() + 1}
+	ctx, _ := app.NewUncachedContext(true, header).CacheContext()
+	ctx = ctx.WithTxBytes(txBytes)
+
+	newCtx, err := app.anteHandler(ctx, tx, false)
+	if newCtx.GasMeter() != nil {
+		return newCtx.GasMeter().GasConsumed(), err
+	}
+	return 0, err
+}
+
+// UpdateMempoolAllowlist replaces the addresses allowed to submit txs to the node's mempool.
+// The txs already in the mempool are rechecked against it after the next block.
+func (app *OraichainApp) UpdateMempoolAllowlist(enabled bool, addresses []sdk.AccAddress) {
+	app.mempoolAllowlist.Update(enabled, addresses)
+}
+
+// UpdateAddressPolicy replaces the addresses refused from the node's mempool
+func (app *OraichainApp) UpdateAddressPolicy(blocked []sdk.AccAddress) {
+	app.addressPolicy.Update(blocked)
+}
+
 // InitChainer application update at chain initialization
 func (app *OraichainApp) InitChainer(ctx sdk.Context, req abci.RequestInitChain) abci.ResponseInitChain {
 	var genesisState GenesisState
@@ -1039,6 +1123,10 @@ func (app *OraichainApp) RegisterAPIRoutes(apiSvr *api.Server, apiConfig config.
 	// Register legacy and grpc-gateway routes for all modules.
 	ModuleBasics.RegisterRESTRoutes(clientCtx, apiSvr.Router)
 	ModuleBasics.RegisterGRPCGatewayRoutes(clientCtx, apiSvr.GRPCGatewayRouter)
+	// Register the grpc-gateway routes of the sponsorship pool of the ante handler.
+	if err := antetypes.RegisterQueryHandlerClient(context.Background(), apiSvr.GRPCGatewayRouter, antetypes.NewQueryClient(clientCtx)); err != nil {
+		panic(err)
+	}
 
 	// register swagger API from root so that other applications can override easily
 	if apiConfig.Swagger {
@@ -1104,35 +1192,11 @@ func initParamsKeeper(appCodec codec.BinaryCodec, legacyAmino *codec.LegacyAmino
 	paramsKeeper.Subspace(evmtypes.ModuleName)
 	paramsKeeper.Subspace(feemarkettypes.ModuleName)
 	paramsKeeper.Subspace(evmutiltypes.ModuleName)
+	paramsKeeper.Subspace(customante.SubspaceName).WithKeyTable(customante.ParamKeyTable())
 
 	return paramsKeeper
 }
 
-func (app *OraichainApp) upgradeHandler() {
-	app.upgradeKeeper.SetUpgradeHandler(BinaryVersion, func(ctx sdk.Context, plan upgradetypes.Plan, fromVM module.VersionMap) (module.VersionMap, error) {
-		response, err := app.mm.RunMigrations(ctx, app.configurator, fromVM)
-		ctx.Logger().Info("Start updating evm params...")
-		defaultEvmParams := evmtypes.DefaultParams()
-		defaultEvmParams.EvmDenom = appconfig.EvmDenom // orai aka 10^-6
-		app.evmKeeper.SetParams(ctx, defaultEvmParams)
-
-		ctx.Logger().Info("Finished updating evm params...")
-		return response, err
-	})
-
-	upgradeInfo, err := app.upgradeKeeper.ReadUpgradeInfoFromDisk()
-	if err != nil {
-		panic(err)
-	}
-
-	if upgradeInfo.Name == BinaryVersion && !app.upgradeKeeper.IsSkipHeight(upgradeInfo.Height) {
-		// configure store loader that checks if version == upgradeHeight and applies store upgrades
-		app.SetStoreLoader(upgradetypes.UpgradeStoreLoader(upgradeInfo.Height, &storetypes.StoreUpgrades{
-			Added: []string{evmtypes.StoreKey, feemarkettypes.StoreKey, evmutiltypes.StoreKey},
-		}))
-	}
-}
-
 // AllCapabilities returns all capabilities available with the current wasmvm
 // See https://github.com/CosmWasm/cosmwasm/blob/main/docs/CAPABILITIES-BUILT-IN.md
 // This functionality is going to be moved upstream: https://github.com/CosmWasm/wasmvm/issues/425
@@ -0,0 +1,167 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+
+	wasmtypes "github.com/CosmWasm/wasmd/x/wasm/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	authtypes "github.com/cosmos/cosmos-sdk/x/auth/types"
+	crisistypes "github.com/cosmos/cosmos-sdk/x/crisis/types"
+	ibctransfertypes "github.com/cosmos/ibc-go/v4/modules/apps/transfer/types"
+	evmutilkeeper "github.com/kava-labs/kava/x/evmutil/keeper"
+	evmutiltypes "github.com/kava-labs/kava/x/evmutil/types"
+	appconfig "github.com/oraichain/orai/cmd/config"
+	tmproto "github.com/tendermint/tendermint/proto/tendermint/types"
+)
+
+// maxReportedItems is the number of offending items listed in the message of a broken invariant
+const maxReportedItems = 10
+
+// InvariantResult is the outcome of an invariant checked by CheckState
+type InvariantResult struct {
+	Module  string `json:"module"`
+	Route   string `json:"route"`
+	Broken  bool   `json:"broken"`
+	Message string `json:"message,omitempty"`
+}
+
+// StateReport is the outcome of all the invariants checked by CheckState
+type StateReport struct {
+	Height     int64             `json:"height"`
+	Invariants []InvariantResult `json:"invariants"`
+}
+
+// Broken returns the number of broken invariants
+func (r StateReport) Broken() int {
+	broken := 0
+	for _, result := range r.Invariants {
+		if result.Broken {
+			broken++
+		}
+	}
+	return broken
+}
+
+// CheckState runs the invariants registered in the crisis module and the ones of customInvariants against the latest
+// loaded state, reporting the broken ones instead of halting. Nothing is written to the state.
+func (app *OraichainApp) CheckState() StateReport {
+	ctx := app.NewUncachedContext(false, tmproto.Header{Height: app.LastBlockHeight()})
+	report := StateReport{Height: app.LastBlockHeight()}
+
+	routes := append(app.crisisKeeper.Routes(), app.customInvariants()...)
+	for _, route := range routes {
+		result := InvariantResult{Module: route.ModuleName, Route: route.Route}
+		cacheCtx, _ := ctx.CacheContext()
+		result.Message, result.Broken = runInvariant(cacheCtx, route.Invar)
+		if !result.Broken {
+			result.Message = ""
+		}
+		report.Invariants = append(report.Invariants, result)
+	}
+	return report
+}
+
+// runInvariant runs invariant, reporting a panic as a broken invariant
+func runInvariant(ctx sdk.Context, invariant sdk.Invariant) (message string, broken bool) {
+	defer func() {
+		if r := recover(); r != nil {
+			message, broken = fmt.Sprintf("invariant panicked: %v", r), true
+		}
+	}()
+	return invariant(ctx)
+}
+
+// customInvariants returns the invariants only checked by CheckState, as they are too expensive to run every
+// invCheckPeriod or cover state the registered invariants do not
+func (app *OraichainApp) customInvariants() []crisistypes.InvarRoute {
+	return []crisistypes.InvarRoute{
+		crisistypes.NewInvarRoute(evmutiltypes.ModuleName, "conversion-balance", app.evmutilConversionBalanceInvariant),
+		crisistypes.NewInvarRoute(wasmtypes.ModuleName, "contract-accounts", app.wasmContractAccountsInvariant),
+		crisistypes.NewInvarRoute(ibctransfertypes.ModuleName, "escrow-vouchers", app.ibcEscrowVouchersInvariant),
+	}
+}
+
+// evmutilConversionBalanceInvariant checks that the fractional balances of the evm denom are backed by the cosmos
+// denom held by the evmutil module. The fully-backed invariant registered by evmutil checks the denom of kava instead.
+func (app *OraichainApp) evmutilConversionBalanceInvariant(ctx sdk.Context) (string, bool) {
+	minorBalances := sdk.ZeroInt()
+	app.evmutilKeeper.IterateAllAccounts(ctx, func(account evmutiltypes.Account) bool {
+		minorBalances = minorBalances.Add(account.Balance)
+		return false
+	})
+
+	moduleBalance := app.bankKeeper.GetBalance(ctx, authtypes.NewModuleAddress(evmutiltypes.ModuleName), appconfig.CosmosDenom)
+	backing := moduleBalance.Amount.Mul(evmutilkeeper.ConversionMultiplier)
+	broken := minorBalances.GT(backing)
+
+	return sdk.FormatInvariant(evmutiltypes.ModuleName, "conversion balance", fmt.Sprintf(
+		"fractional balances of %s%s exceed the %s held by the module", minorBalances, appconfig.EvmDenom, moduleBalance,
+	)), broken
+}
+
+// wasmContractAccountsInvariant checks that every contract has an account
+func (app *OraichainApp) wasmContractAccountsInvariant(ctx sdk.Context) (string, bool) {
+	var missing []string
+	app.wasmKeeper.IterateContractInfo(ctx, func(addr sdk.AccAddress, _ wasmtypes.ContractInfo) bool {
+		if !app.accountKeeper.HasAccount(ctx, addr) {
+			missing = append(missing, addr.String())
+		}
+		return false
+	})
+
+	return sdk.FormatInvariant(wasmtypes.ModuleName, "contract accounts", fmt.Sprintf(
+		"%d contracts without an account: %s", len(missing), truncatedList(missing),
+	)), len(missing) > 0
+}
+
+// ibcEscrowVouchersInvariant checks the vouchers of the transfer channels: every voucher has a denom trace, and the
+// escrow accounts hold no vouchers of their own channel, which are burnt when sent back rather than escrowed. The
+// escrowed amounts are not checked, ibc-go v4 does not track the total escrowed per channel to check them against.
+func (app *OraichainApp) ibcEscrowVouchersInvariant(ctx sdk.Context) (string, bool) {
+	var problems []string
+
+	app.bankKeeper.IterateTotalSupply(ctx, func(coin sdk.Coin) bool {
+		if !strings.HasPrefix(coin.Denom, ibctransfertypes.DenomPrefix+"/") {
+			return false
+		}
+		hash, err := ibctransfertypes.ParseHexHash(strings.TrimPrefix(coin.Denom, ibctransfertypes.DenomPrefix+"/"))
+		if err != nil || !app.transferKeeper.HasDenomTrace(ctx, hash) {
+			problems = append(problems, fmt.Sprintf("voucher %s has no denom trace", coin.Denom))
+		}
+		return false
+	})
+
+	for _, channel := range app.ibcKeeper.ChannelKeeper.GetAllChannels(ctx) {
+		if channel.PortId != ibctransfertypes.PortID {
+			continue
+		}
+		escrow := app.bankKeeper.GetAllBalances(ctx, ibctransfertypes.GetEscrowAddress(channel.PortId, channel.ChannelId))
+
+		ownPrefix := ibctransfertypes.GetDenomPrefix(channel.PortId, channel.ChannelId)
+		for _, coin := range escrow {
+			if !strings.HasPrefix(coin.Denom, ibctransfertypes.DenomPrefix+"/") {
+				continue
+			}
+			hash, err := ibctransfertypes.ParseHexHash(strings.TrimPrefix(coin.Denom, ibctransfertypes.DenomPrefix+"/"))
+			if err != nil {
+				continue
+			}
+			if trace, found := app.transferKeeper.GetDenomTrace(ctx, hash); found && strings.HasPrefix(trace.GetFullDenomPath(), ownPrefix) {
+				problems = append(problems, fmt.Sprintf("escrow of %s holds %s%s received over the channel", channel.ChannelId, coin.Amount, trace.GetFullDenomPath()))
+			}
+		}
+	}
+
+	return sdk.FormatInvariant(ibctransfertypes.ModuleName, "escrow vouchers", fmt.Sprintf(
+		"%d inconsistencies of the vouchers: %s", len(problems), truncatedList(problems),
+	)), len(problems) > 0
+}
+
+// truncatedList joins the first maxReportedItems items
+func truncatedList(items []string) string {
+	if len(items) > maxReportedItems {
+		return strings.Join(items[:maxReportedItems], ", ") + fmt.Sprintf(" and %d more", len(items)-maxReportedItems)
+	}
+	return strings.Join(items, ", ")
+}
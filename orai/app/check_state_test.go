@@ -0,0 +1,63 @@
+package app
+
+import (
+	"testing"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	minttypes "github.com/cosmos/cosmos-sdk/x/mint/types"
+	ibctransfertypes "github.com/cosmos/ibc-go/v4/modules/apps/transfer/types"
+	channeltypes "github.com/cosmos/ibc-go/v4/modules/core/04-channel/types"
+	"github.com/stretchr/testify/require"
+	tmproto "github.com/tendermint/tendermint/proto/tendermint/types"
+	dbm "github.com/tendermint/tm-db"
+)
+
+// brokenRoutes returns the module/route of the broken invariants of report
+func brokenRoutes(report StateReport) []string {
+	var routes []string
+	for _, result := range report.Invariants {
+		if result.Broken {
+			routes = append(routes, result.Module+"/"+result.Route)
+		}
+	}
+	return routes
+}
+
+func TestCheckState(t *testing.T) {
+	app := setupTestApp(t, dbm.NewMemDB(), t.TempDir())
+	nextBlock(app)
+
+	report := app.CheckState()
+	require.Equal(t, app.LastBlockHeight(), report.Height)
+	require.Greater(t, len(report.Invariants), len(app.customInvariants()))
+	require.Zero(t, report.Broken(), "broken invariants: %v", brokenRoutes(report))
+
+	ctx := app.NewUncachedContext(false, tmproto.Header{Height: app.LastBlockHeight()})
+
+	// fractional balances the evmutil module does not back
+	require.NoError(t, app.evmutilKeeper.SetBalance(ctx, sdk.AccAddress("holder______________"), sdk.NewInt(1)))
+
+	// vouchers without a denom trace
+	voucher := ibctransfertypes.ParseDenomTrace("transfer/channel-0/uatom").IBCDenom()
+	require.NoError(t, app.bankKeeper.MintCoins(ctx, minttypes.ModuleName, sdk.NewCoins(sdk.NewInt64Coin(voucher, 10))))
+
+	// vouchers of a channel escrowed by the channel, instead of being burnt when sent back
+	trace := ibctransfertypes.ParseDenomTrace("transfer/channel-1/uosmo")
+	app.transferKeeper.SetDenomTrace(ctx, trace)
+	app.ibcKeeper.ChannelKeeper.SetChannel(ctx, ibctransfertypes.PortID, "channel-1", channeltypes.NewChannel(
+		channeltypes.OPEN, channeltypes.UNORDERED, channeltypes.NewCounterparty(ibctransfertypes.PortID, "channel-7"),
+		[]string{"connection-0"}, ibctransfertypes.Version,
+	))
+	escrowed := sdk.NewCoins(sdk.NewInt64Coin(trace.IBCDenom(), 5))
+	require.NoError(t, app.bankKeeper.MintCoins(ctx, minttypes.ModuleName, escrowed))
+	require.NoError(t, app.bankKeeper.SendCoinsFromModuleToAccount(ctx, minttypes.ModuleName, ibctransfertypes.GetEscrowAddress(ibctransfertypes.PortID, "channel-1"), escrowed))
+
+	report = app.CheckState()
+	require.ElementsMatch(t, []string{"evmutil/fully-backed", "evmutil/conversion-balance", "transfer/escrow-vouchers"}, brokenRoutes(report))
+	for _, result := range report.Invariants {
+		if result.Route == "escrow-vouchers" {
+			require.Contains(t, result.Message, voucher+" has no denom trace")
+			require.Contains(t, result.Message, "escrow of channel-1 holds 5transfer/channel-1/uosmo")
+		}
+	}
+}
@@ -2,12 +2,15 @@ package app
 
 import (
 	"encoding/json"
-	"log"
+	"errors"
+	"fmt"
 
+	"github.com/tendermint/tendermint/libs/log"
 	tmproto "github.com/tendermint/tendermint/proto/tendermint/types"
 
 	servertypes "github.com/cosmos/cosmos-sdk/server/types"
 	sdk "github.com/cosmos/cosmos-sdk/types"
+	distrtypes "github.com/cosmos/cosmos-sdk/x/distribution/types"
 	slashingtypes "github.com/cosmos/cosmos-sdk/x/slashing/types"
 	"github.com/cosmos/cosmos-sdk/x/staking"
 	stakingtypes "github.com/cosmos/cosmos-sdk/x/staking/types"
@@ -26,7 +29,11 @@ func (app *OraichainApp) ExportAppStateAndValidators(
 	height := app.LastBlockHeight() + 1
 	if forZeroHeight {
 		height = 0
-		app.prepForZeroHeightGenesis(ctx, jailAllowedAddrs)
+		report, err := app.prepForZeroHeightGenesis(ctx, jailAllowedAddrs)
+		if err != nil {
+			return servertypes.ExportedApp{}, err
+		}
+		report.log(app.Logger())
 	}
 
 	genState := app.mm.ExportGenesis(ctx, app.appCodec)
@@ -44,10 +51,56 @@ func (app *OraichainApp) ExportAppStateAndValidators(
 	}, err
 }
 
+// ZeroHeightReport summarizes the changes made to the state by a zero height export
+type ZeroHeightReport struct {
+	Height int64 `json:"height"`
+	// JailedValidators are the validators jailed for not being in the jail allowed addresses
+	JailedValidators    []JailedValidator `json:"jailed_validators"`
+	CommissionWithdrawn sdk.Coins         `json:"commission_withdrawn"`
+	RewardsWithdrawn    sdk.Coins         `json:"rewards_withdrawn"`
+	// CommunityPoolScraps are the reward fractions left to the validators, donated to the community pool
+	CommunityPoolScraps sdk.DecCoins `json:"community_pool_scraps"`
+}
+
+// JailedValidator is a validator jailed by a zero height export
+type JailedValidator struct {
+	OperatorAddress string  `json:"operator_address"`
+	Moniker         string  `json:"moniker"`
+	Status          string  `json:"status"`
+	Tokens          sdk.Int `json:"tokens"`
+	Delegators      int     `json:"delegators"`
+}
+
+func (r *ZeroHeightReport) log(logger log.Logger) {
+	logger.Info("prepared zero height genesis",
+		"jailed_validators", len(r.JailedValidators),
+		"commission_withdrawn", r.CommissionWithdrawn.String(),
+		"rewards_withdrawn", r.RewardsWithdrawn.String(),
+		"community_pool_scraps", r.CommunityPoolScraps.String(),
+	)
+}
+
+// ZeroHeightReport returns the report of a zero height export with jailAllowedAddrs without exporting the state
+func (app *OraichainApp) ZeroHeightReport(jailAllowedAddrs []string) (*ZeroHeightReport, error) {
+	ctx := app.NewContext(true, tmproto.Header{Height: app.LastBlockHeight()})
+	cacheCtx, _ := ctx.CacheContext()
+	return app.prepForZeroHeightGenesis(cacheCtx, jailAllowedAddrs)
+}
+
 // prepare for fresh start at zero height
 // NOTE zero height genesis is a temporary feature which will be deprecated
 //      in favour of export at a block height
-func (app *OraichainApp) prepForZeroHeightGenesis(ctx sdk.Context, jailAllowedAddrs []string) {
+//
+// Validators not in jailAllowedAddrs are jailed when it is not empty. The changes made to the distribution and staking
+// state are summarized in the returned report.
+func (app *OraichainApp) prepForZeroHeightGenesis(ctx sdk.Context, jailAllowedAddrs []string) (*ZeroHeightReport, error) {
+	report := &ZeroHeightReport{
+		Height:              ctx.BlockHeight(),
+		JailedValidators:    []JailedValidator{},
+		CommissionWithdrawn: sdk.NewCoins(),
+		RewardsWithdrawn:    sdk.NewCoins(),
+		CommunityPoolScraps: sdk.NewDecCoins(),
+	}
 	applyAllowedAddrs := false
 
 	// check if there is a allowed address list
@@ -58,37 +111,59 @@ func (app *OraichainApp) prepForZeroHeightGenesis(ctx sdk.Context, jailAllowedAd
 	allowedAddrsMap := make(map[string]bool)
 
 	for _, addr := range jailAllowedAddrs {
-		_, err := sdk.ValAddressFromBech32(addr)
+		valAddr, err := sdk.ValAddressFromBech32(addr)
 		if err != nil {
-			log.Fatal(err)
+			return nil, fmt.Errorf("invalid jail allowed address %s: %w", addr, err)
+		}
+		if _, found := app.stakingKeeper.GetValidator(ctx, valAddr); !found {
+			return nil, fmt.Errorf("validator %s of the jail allowed addresses not found", addr)
 		}
 		allowedAddrsMap[addr] = true
 	}
 
 	/* Just to be safe, assert the invariants on current state. */
-	app.crisisKeeper.AssertInvariants(ctx)
+	for _, route := range app.crisisKeeper.Routes() {
+		if message, broken := runInvariant(ctx, route.Invar); broken {
+			return nil, fmt.Errorf("invariant %s broken: %s", route.FullRoute(), message)
+		}
+	}
 
 	/* Handle fee distribution state. */
 
 	// withdraw all validator commission
+	var err error
 	app.stakingKeeper.IterateValidators(ctx, func(_ int64, val stakingtypes.ValidatorI) (stop bool) {
-		_, _ = app.distrKeeper.WithdrawValidatorCommission(ctx, val.GetOperator())
+		commission, withdrawErr := app.distrKeeper.WithdrawValidatorCommission(ctx, val.GetOperator())
+		if withdrawErr != nil && !errors.Is(withdrawErr, distrtypes.ErrNoValidatorCommission) {
+			err = fmt.Errorf("withdrawing the commission of %s: %w", val.GetOperator(), withdrawErr)
+			return true
+		}
+		report.CommissionWithdrawn = report.CommissionWithdrawn.Add(commission...)
 		return false
 	})
+	if err != nil {
+		return nil, err
+	}
 
 	// withdraw all delegator rewards
 	dels := app.stakingKeeper.GetAllDelegations(ctx)
+	delegators := make(map[string]int)
 	for _, delegation := range dels {
 		valAddr, err := sdk.ValAddressFromBech32(delegation.ValidatorAddress)
 		if err != nil {
-			panic(err)
+			return nil, err
 		}
 
 		delAddr, err := sdk.AccAddressFromBech32(delegation.DelegatorAddress)
 		if err != nil {
-			panic(err)
+			return nil, err
+		}
+		rewards, err := app.distrKeeper.WithdrawDelegationRewards(ctx, delAddr, valAddr)
+		if err != nil {
+			return nil, fmt.Errorf("withdrawing the rewards of %s from %s: %w", delAddr, valAddr, err)
 		}
-		_, _ = app.distrKeeper.WithdrawDelegationRewards(ctx, delAddr, valAddr)
+		report.RewardsWithdrawn = report.RewardsWithdrawn.Add(rewards...)
+		delegators[delegation.ValidatorAddress]++
 	}
 
 	// clear validator slash events
@@ -105,6 +180,7 @@ func (app *OraichainApp) prepForZeroHeightGenesis(ctx sdk.Context, jailAllowedAd
 	app.stakingKeeper.IterateValidators(ctx, func(_ int64, val stakingtypes.ValidatorI) (stop bool) {
 		// donate any unwithdrawn outstanding reward fraction tokens to the community pool
 		scraps := app.distrKeeper.GetValidatorOutstandingRewardsCoins(ctx, val.GetOperator())
+		report.CommunityPoolScraps = report.CommunityPoolScraps.Add(scraps...)
 		feePool := app.distrKeeper.GetFeePool(ctx)
 		feePool.CommunityPool = feePool.CommunityPool.Add(scraps...)
 		app.distrKeeper.SetFeePool(ctx, feePool)
@@ -117,11 +193,11 @@ func (app *OraichainApp) prepForZeroHeightGenesis(ctx sdk.Context, jailAllowedAd
 	for _, del := range dels {
 		valAddr, err := sdk.ValAddressFromBech32(del.ValidatorAddress)
 		if err != nil {
-			panic(err)
+			return nil, err
 		}
 		delAddr, err := sdk.AccAddressFromBech32(del.DelegatorAddress)
 		if err != nil {
-			panic(err)
+			return nil, err
 		}
 		app.distrKeeper.Hooks().BeforeDelegationCreated(ctx, delAddr, valAddr)
 		app.distrKeeper.Hooks().AfterDelegationModified(ctx, delAddr, valAddr)
@@ -157,15 +233,25 @@ func (app *OraichainApp) prepForZeroHeightGenesis(ctx sdk.Context, jailAllowedAd
 	counter := int16(0)
 
 	for ; iter.Valid(); iter.Next() {
-		addr := sdk.ValAddress(iter.Key()[1:])
+		addr := sdk.ValAddress(stakingtypes.AddressFromValidatorsKey(iter.Key()))
 		validator, found := app.stakingKeeper.GetValidator(ctx, addr)
 		if !found {
-			panic("expected validator, not found")
+			iter.Close()
+			return nil, fmt.Errorf("expected validator %s, not found", addr)
 		}
 
 		validator.UnbondingHeight = 0
-		if applyAllowedAddrs && !allowedAddrsMap[addr.String()] {
+		if applyAllowedAddrs && !allowedAddrsMap[addr.String()] && !validator.Jailed {
+			// as the staking keeper jails, jailed validators must not be left in the power index
+			app.stakingKeeper.DeleteValidatorByPowerIndex(ctx, validator)
 			validator.Jailed = true
+			report.JailedValidators = append(report.JailedValidators, JailedValidator{
+				OperatorAddress: validator.OperatorAddress,
+				Moniker:         validator.GetMoniker(),
+				Status:          validator.GetStatus().String(),
+				Tokens:          validator.Tokens,
+				Delegators:      delegators[validator.OperatorAddress],
+			})
 		}
 
 		app.stakingKeeper.SetValidator(ctx, validator)
@@ -174,9 +260,8 @@ func (app *OraichainApp) prepForZeroHeightGenesis(ctx sdk.Context, jailAllowedAd
 
 	iter.Close()
 
-	_, err := app.stakingKeeper.ApplyAndReturnValidatorSetUpdates(ctx)
-	if err != nil {
-		log.Fatal(err)
+	if _, err := app.stakingKeeper.ApplyAndReturnValidatorSetUpdates(ctx); err != nil {
+		return nil, err
 	}
 
 	/* Handle slashing state. */
@@ -190,4 +275,6 @@ func (app *OraichainApp) prepForZeroHeightGenesis(ctx sdk.Context, jailAllowedAd
 			return false
 		},
 	)
+
+	return report, nil
 }
@@ -0,0 +1,280 @@
+package app
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+
+	wasmtypes "github.com/CosmWasm/wasmd/x/wasm/types"
+	"github.com/cosmos/cosmos-sdk/codec"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/x/staking"
+	tmjson "github.com/tendermint/tendermint/libs/json"
+	tmproto "github.com/tendermint/tendermint/proto/tendermint/types"
+	tmtypes "github.com/tendermint/tendermint/types"
+)
+
+// GenesisExportOptions selects the state written by ExportGenesisStream
+type GenesisExportOptions struct {
+	ForZeroHeight    bool
+	JailAllowedAddrs []string
+	// Modules restricts the export to the given modules, all of them when empty. The result is then only meant for
+	// debugging as it cannot be imported.
+	Modules []string
+	Wasm    WasmExportFilter
+	// Testnet turns the exported state into the one of a local testnet when set
+	Testnet *TestnetConfig
+}
+
+// WasmExportFilter selects the codes and contracts of the wasm genesis. Include lists take all the codes or contracts
+// when empty. The contracts of the codes left out are left out too, as they could not be imported without them.
+type WasmExportFilter struct {
+	IncludeCodes     []uint64
+	ExcludeCodes     []uint64
+	IncludeContracts []string
+	ExcludeContracts []string
+}
+
+// Validate checks that the contract addresses of the filter are valid
+func (f WasmExportFilter) Validate() error {
+	for _, addr := range append(append([]string{}, f.IncludeContracts...), f.ExcludeContracts...) {
+		if _, err := sdk.AccAddressFromBech32(addr); err != nil {
+			return fmt.Errorf("invalid contract address %s: %w", addr, err)
+		}
+	}
+	return nil
+}
+
+func (f WasmExportFilter) includesCode(codeID uint64) bool {
+	return (len(f.IncludeCodes) == 0 || containsCodeID(f.IncludeCodes, codeID)) && !containsCodeID(f.ExcludeCodes, codeID)
+}
+
+func (f WasmExportFilter) includesContract(addr string, codeID uint64) bool {
+	return f.includesCode(codeID) &&
+		(len(f.IncludeContracts) == 0 || containsAddress(f.IncludeContracts, addr)) && !containsAddress(f.ExcludeContracts, addr)
+}
+
+// ExportGenesisStream writes to w the genesis document genDoc updated with the state of the application, one module
+// after the other so that the whole application state is never held in memory. The codes and contracts of the wasm
+// module are written one by one. The report of the changes made to the state is returned for zero height exports.
+func (app *OraichainApp) ExportGenesisStream(w io.Writer, genDoc *tmtypes.GenesisDoc, opts GenesisExportOptions) (*ZeroHeightReport, error) {
+	for _, name := range opts.Modules {
+		if _, found := app.mm.Modules[name]; !found {
+			return nil, fmt.Errorf("unknown module %s", name)
+		}
+	}
+	if err := opts.Wasm.Validate(); err != nil {
+		return nil, err
+	}
+
+	// as if they could withdraw from the start of the next block
+	ctx := app.NewContext(true, tmproto.Header{Height: app.LastBlockHeight()})
+
+	// We export at last height + 1, because that's the height at which
+	// Tendermint will start InitChain.
+	height := app.LastBlockHeight() + 1
+	var report *ZeroHeightReport
+	if opts.ForZeroHeight {
+		height = 0
+		var err error
+		if report, err = app.prepForZeroHeightGenesis(ctx, opts.JailAllowedAddrs); err != nil {
+			return nil, err
+		}
+	}
+	if opts.Testnet != nil {
+		// the validators jailed for the testnet start unbonding at its genesis time
+		ctx = ctx.WithBlockTime(genDoc.GenesisTime)
+		if err := app.prepTestnetGenesis(ctx, *opts.Testnet); err != nil {
+			return nil, err
+		}
+	}
+
+	validators, err := staking.WriteValidators(ctx, app.stakingKeeper)
+	if err != nil {
+		return nil, err
+	}
+	consensusParams := app.BaseApp.GetConsensusParams(ctx)
+
+	genDoc.AppState = nil
+	genDoc.Validators = validators
+	genDoc.InitialHeight = height
+	genDoc.ConsensusParams = &tmproto.ConsensusParams{
+		Block: tmproto.BlockParams{
+			MaxBytes:   consensusParams.Block.MaxBytes,
+			MaxGas:     consensusParams.Block.MaxGas,
+			TimeIotaMs: genDoc.ConsensusParams.Block.TimeIotaMs,
+		},
+		Evidence: tmproto.EvidenceParams{
+			MaxAgeNumBlocks: consensusParams.Evidence.MaxAgeNumBlocks,
+			MaxAgeDuration:  consensusParams.Evidence.MaxAgeDuration,
+			MaxBytes:        consensusParams.Evidence.MaxBytes,
+		},
+		Validator: tmproto.ValidatorParams{
+			PubKeyTypes: consensusParams.Validator.PubKeyTypes,
+		},
+	}
+
+	// NOTE: Tendermint uses a custom JSON decoder for GenesisDoc, the app state left empty is omitted and appended
+	// after it
+	header, err := tmjson.Marshal(genDoc)
+	if err != nil {
+		return nil, err
+	}
+
+	bw := bufio.NewWriter(w)
+	sw := &stickyWriter{w: bw}
+	sw.Write(bytes.TrimSuffix(header, []byte("}")))
+	sw.WriteString(`,"app_state":{`)
+
+	modules := opts.Modules
+	if len(modules) == 0 {
+		for name := range app.mm.Modules {
+			modules = append(modules, name)
+		}
+	}
+	sort.Strings(modules)
+
+	for i, name := range modules {
+		if i > 0 {
+			sw.WriteString(",")
+		}
+		key, _ := json.Marshal(name)
+		sw.Write(key)
+		sw.WriteString(":")
+
+		if name == wasmtypes.ModuleName {
+			if err := app.exportWasmGenesis(ctx, sw, opts.Wasm); err != nil {
+				return nil, err
+			}
+		} else if state := app.mm.Modules[name].ExportGenesis(ctx, app.appCodec); state != nil {
+			sw.Write(state)
+		} else {
+			// modules without genesis state export nil, marshalled as null by ExportAppStateAndValidators
+			sw.WriteString("null")
+		}
+		if sw.err != nil {
+			return nil, sw.err
+		}
+	}
+
+	sw.WriteString("}}\n")
+	if sw.err != nil {
+		return nil, sw.err
+	}
+	return report, bw.Flush()
+}
+
+// exportWasmGenesis writes the genesis state of the wasm module as its ExportGenesis would, filtering the codes and
+// contracts and marshalling them one by one
+func (app *OraichainApp) exportWasmGenesis(ctx sdk.Context, sw *stickyWriter, filter WasmExportFilter) error {
+	writeMessage := func(msg codec.ProtoMarshaler) {
+		if sw.err != nil {
+			return
+		}
+		bz, err := app.appCodec.MarshalJSON(msg)
+		if err != nil {
+			sw.err = err
+			return
+		}
+		sw.Write(bz)
+	}
+
+	params := app.wasmKeeper.GetParams(ctx)
+	sw.WriteString(`{"params":`)
+	writeMessage(&params)
+
+	sw.WriteString(`,"codes":[`)
+	count := 0
+	app.wasmKeeper.IterateCodeInfos(ctx, func(codeID uint64, info wasmtypes.CodeInfo) bool {
+		if !filter.includesCode(codeID) {
+			return false
+		}
+		bytecode, err := app.wasmKeeper.GetByteCode(ctx, codeID)
+		if err != nil {
+			sw.err = err
+			return true
+		}
+		if count > 0 {
+			sw.WriteString(",")
+		}
+		count++
+		writeMessage(&wasmtypes.Code{
+			CodeID:    codeID,
+			CodeInfo:  info,
+			CodeBytes: bytecode,
+			Pinned:    app.wasmKeeper.IsPinnedCode(ctx, codeID),
+		})
+		return sw.err != nil
+	})
+
+	sw.WriteString(`],"contracts":[`)
+	count = 0
+	app.wasmKeeper.IterateContractInfo(ctx, func(addr sdk.AccAddress, contract wasmtypes.ContractInfo) bool {
+		if !filter.includesContract(addr.String(), contract.CodeID) {
+			return false
+		}
+		var state []wasmtypes.Model
+		app.wasmKeeper.IterateContractState(ctx, addr, func(key, value []byte) bool {
+			state = append(state, wasmtypes.Model{Key: key, Value: value})
+			return false
+		})
+		if count > 0 {
+			sw.WriteString(",")
+		}
+		count++
+		writeMessage(&wasmtypes.Contract{
+			ContractAddress:     addr.String(),
+			ContractInfo:        contract,
+			ContractState:       state,
+			ContractCodeHistory: app.wasmKeeper.GetContractHistory(ctx, addr),
+		})
+		return sw.err != nil
+	})
+
+	sw.WriteString(`],"sequences":[`)
+	for i, key := range [][]byte{wasmtypes.KeyLastCodeID, wasmtypes.KeyLastInstanceID} {
+		if i > 0 {
+			sw.WriteString(",")
+		}
+		writeMessage(&wasmtypes.Sequence{IDKey: key, Value: app.wasmKeeper.PeekAutoIncrementID(ctx, key)})
+	}
+	sw.WriteString(`]}`)
+	return sw.err
+}
+
+// stickyWriter keeps the first error met writing to w and skips the writes following it
+type stickyWriter struct {
+	w   io.Writer
+	err error
+}
+
+func (sw *stickyWriter) Write(bz []byte) {
+	if sw.err == nil {
+		_, sw.err = sw.w.Write(bz)
+	}
+}
+
+func (sw *stickyWriter) WriteString(s string) {
+	sw.Write([]byte(s))
+}
+
+func containsCodeID(ids []uint64, id uint64) bool {
+	for _, item := range ids {
+		if item == id {
+			return true
+		}
+	}
+	return false
+}
+
+func containsAddress(addrs []string, addr string) bool {
+	for _, item := range addrs {
+		if item == addr {
+			return true
+		}
+	}
+	return false
+}
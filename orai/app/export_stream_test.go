@@ -0,0 +1,119 @@
+package app
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"testing"
+	"time"
+
+	wasmkeeper "github.com/CosmWasm/wasmd/x/wasm/keeper"
+	wasmtypes "github.com/CosmWasm/wasmd/x/wasm/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/stretchr/testify/require"
+	tmjson "github.com/tendermint/tendermint/libs/json"
+	tmproto "github.com/tendermint/tendermint/proto/tendermint/types"
+	tmtypes "github.com/tendermint/tendermint/types"
+	dbm "github.com/tendermint/tm-db"
+)
+
+// setupWasmExportApp returns an app holding two codes, with two contracts instantiated from the first one
+func setupWasmExportApp(t *testing.T) (*OraichainApp, []sdk.AccAddress) {
+	app := setupTestApp(t, dbm.NewMemDB(), t.TempDir())
+	ctx := app.NewUncachedContext(false, tmproto.Header{Height: app.LastBlockHeight(), Time: time.Unix(app.LastBlockHeight(), 0).UTC()})
+	contractKeeper := wasmkeeper.NewDefaultPermissionKeeper(app.wasmKeeper)
+	creator := sdk.AccAddress("creator_____________")
+
+	wasmCode, err := os.ReadFile("../scripts/wasm_file/cw-clock-example.wasm")
+	require.NoError(t, err)
+	codeID, _, err := contractKeeper.Create(ctx, creator, wasmCode, nil)
+	require.NoError(t, err)
+	_, _, err = contractKeeper.Create(ctx, creator, wasmCode, nil)
+	require.NoError(t, err)
+
+	var contracts []sdk.AccAddress
+	for _, label := range []string{"first", "second"} {
+		addr, _, err := contractKeeper.Instantiate(ctx, codeID, creator, nil, []byte("{}"), label, nil)
+		require.NoError(t, err)
+		contracts = append(contracts, addr)
+	}
+	return app, contracts
+}
+
+// exportGenesisStream exports the genesis of app with opts and decodes it
+func exportGenesisStream(t *testing.T, app *OraichainApp, opts GenesisExportOptions) (*tmtypes.GenesisDoc, GenesisState) {
+	var buf bytes.Buffer
+	genDoc := &tmtypes.GenesisDoc{ChainID: testChainID, ConsensusParams: tmtypes.DefaultConsensusParams()}
+	_, err := app.ExportGenesisStream(&buf, genDoc, opts)
+	require.NoError(t, err)
+
+	var exported tmtypes.GenesisDoc
+	require.NoError(t, tmjson.Unmarshal(buf.Bytes(), &exported))
+	var genState GenesisState
+	require.NoError(t, json.Unmarshal(exported.AppState, &genState))
+	return &exported, genState
+}
+
+func TestExportGenesisStream(t *testing.T) {
+	app, _ := setupWasmExportApp(t)
+
+	exported, genState := exportGenesisStream(t, app, GenesisExportOptions{})
+	require.Equal(t, testChainID, exported.ChainID)
+	require.Equal(t, app.LastBlockHeight()+1, exported.InitialHeight)
+
+	expected, err := app.ExportAppStateAndValidators(false, nil)
+	require.NoError(t, err)
+	var expectedState GenesisState
+	require.NoError(t, json.Unmarshal(expected.AppState, &expectedState))
+
+	require.Equal(t, len(expectedState), len(genState))
+	for name, state := range expectedState {
+		require.JSONEq(t, string(state), string(genState[name]), name)
+	}
+	require.NoError(t, ModuleBasics.ValidateGenesis(app.appCodec, MakeEncodingConfig().TxConfig, genState))
+}
+
+func TestExportGenesisStreamFilters(t *testing.T) {
+	app, contracts := setupWasmExportApp(t)
+
+	wasmGenesis := func(genState GenesisState) wasmtypes.GenesisState {
+		var wasmGenesis wasmtypes.GenesisState
+		app.appCodec.MustUnmarshalJSON(genState[wasmtypes.ModuleName], &wasmGenesis)
+		require.NoError(t, wasmGenesis.ValidateBasic())
+		return wasmGenesis
+	}
+
+	_, genState := exportGenesisStream(t, app, GenesisExportOptions{Modules: []string{wasmtypes.ModuleName, "bank"}})
+	require.Len(t, genState, 2)
+	all := wasmGenesis(genState)
+	require.Len(t, all.Codes, 2)
+	require.Len(t, all.Contracts, 2)
+	require.Len(t, all.Sequences, 2)
+
+	// the contracts of an excluded code are left out too
+	_, genState = exportGenesisStream(t, app, GenesisExportOptions{Wasm: WasmExportFilter{ExcludeCodes: []uint64{1}}})
+	filtered := wasmGenesis(genState)
+	require.Len(t, filtered.Codes, 1)
+	require.Equal(t, uint64(2), filtered.Codes[0].CodeID)
+	require.Empty(t, filtered.Contracts)
+	require.Equal(t, all.Sequences, filtered.Sequences)
+
+	_, genState = exportGenesisStream(t, app, GenesisExportOptions{Wasm: WasmExportFilter{IncludeCodes: []uint64{1}, ExcludeContracts: []string{contracts[0].String()}}})
+	filtered = wasmGenesis(genState)
+	require.Len(t, filtered.Codes, 1)
+	require.Len(t, filtered.Contracts, 1)
+	require.Equal(t, contracts[1].String(), filtered.Contracts[0].ContractAddress)
+
+	_, genState = exportGenesisStream(t, app, GenesisExportOptions{Wasm: WasmExportFilter{IncludeContracts: []string{contracts[0].String()}}})
+	filtered = wasmGenesis(genState)
+	require.Len(t, filtered.Codes, 2)
+	require.Len(t, filtered.Contracts, 1)
+	require.Equal(t, contracts[0].String(), filtered.Contracts[0].ContractAddress)
+
+	var buf bytes.Buffer
+	genDoc := &tmtypes.GenesisDoc{ChainID: testChainID, ConsensusParams: tmtypes.DefaultConsensusParams()}
+	_, err := app.ExportGenesisStream(&buf, genDoc, GenesisExportOptions{Modules: []string{"unknown"}})
+	require.ErrorContains(t, err, "unknown module unknown")
+	_, err = app.ExportGenesisStream(&buf, genDoc, GenesisExportOptions{Wasm: WasmExportFilter{ExcludeContracts: []string{"invalid"}}})
+	require.ErrorContains(t, err, "invalid contract address invalid")
+}
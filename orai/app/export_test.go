@@ -0,0 +1,85 @@
+package app
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/stretchr/testify/require"
+	abci "github.com/tendermint/tendermint/abci/types"
+	tmproto "github.com/tendermint/tendermint/proto/tendermint/types"
+	tmtypes "github.com/tendermint/tendermint/types"
+)
+
+// signedBlocks runs count blocks whose last commit is signed by all the bonded validators, so that they earn rewards
+func signedBlocks(t *testing.T, app *OraichainApp, count int) {
+	ctx := app.NewUncachedContext(false, tmproto.Header{})
+	var votes []abci.VoteInfo
+	for _, validator := range app.stakingKeeper.GetBondedValidatorsByPower(ctx) {
+		consAddr, err := validator.GetConsAddr()
+		require.NoError(t, err)
+		votes = append(votes, abci.VoteInfo{
+			Validator:       abci.Validator{Address: consAddr, Power: validator.ConsensusPower(sdk.DefaultPowerReduction)},
+			SignedLastBlock: true,
+		})
+	}
+
+	for i := 0; i < count; i++ {
+		height := app.LastBlockHeight() + 1
+		header := tmproto.Header{ChainID: testChainID, Height: height, Time: time.Unix(height*5, 0).UTC()}
+		app.BeginBlock(abci.RequestBeginBlock{Header: header, LastCommitInfo: abci.LastCommitInfo{Votes: votes}})
+		app.EndBlock(abci.RequestEndBlock{Height: height})
+		app.Commit()
+	}
+}
+
+func TestZeroHeightReport(t *testing.T) {
+	app, operators := setupValidatorsApp(t, 10, 30, 20)
+	signedBlocks(t, app, 3)
+
+	report, err := app.ZeroHeightReport([]string{operators[1].String()})
+	require.NoError(t, err)
+	require.Equal(t, app.LastBlockHeight(), report.Height)
+	require.True(t, report.RewardsWithdrawn.IsAllPositive())
+	require.True(t, report.CommunityPoolScraps.IsAllPositive())
+
+	var jailed []string
+	for _, validator := range report.JailedValidators {
+		jailed = append(jailed, validator.OperatorAddress)
+		require.Equal(t, 1, validator.Delegators)
+		require.True(t, validator.Tokens.IsPositive())
+	}
+	require.ElementsMatch(t, []string{operators[0].String(), operators[2].String()}, jailed)
+
+	// the dry run leaves the state untouched
+	ctx := app.NewUncachedContext(false, tmproto.Header{})
+	for _, operator := range operators {
+		validator, found := app.stakingKeeper.GetValidator(ctx, operator)
+		require.True(t, found)
+		require.False(t, validator.IsJailed())
+	}
+
+	// and reports what the export does
+	genDoc := &tmtypes.GenesisDoc{ChainID: testChainID, ConsensusParams: tmtypes.DefaultConsensusParams()}
+	exported, err := app.ExportGenesisStream(&bytes.Buffer{}, genDoc, GenesisExportOptions{
+		ForZeroHeight:    true,
+		JailAllowedAddrs: []string{operators[1].String()},
+	})
+	require.NoError(t, err)
+	require.Equal(t, report, exported)
+}
+
+func TestZeroHeightReportInvalidAllowedAddrs(t *testing.T) {
+	app, _ := setupValidatorsApp(t, 10)
+
+	_, err := app.ZeroHeightReport([]string{"invalid"})
+	require.ErrorContains(t, err, "invalid jail allowed address invalid")
+
+	unknown := sdk.ValAddress("unknown_____________").String()
+	_, err = app.ZeroHeightReport([]string{unknown})
+	require.ErrorContains(t, err, "validator "+unknown+" of the jail allowed addresses not found")
+
+	_, err = app.ExportAppStateAndValidators(true, []string{unknown})
+	require.ErrorContains(t, err, "not found")
+}
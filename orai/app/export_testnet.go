@@ -0,0 +1,166 @@
+package app
+
+import (
+	"fmt"
+	"time"
+
+	codectypes "github.com/cosmos/cosmos-sdk/codec/types"
+	cryptotypes "github.com/cosmos/cosmos-sdk/crypto/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	banktypes "github.com/cosmos/cosmos-sdk/x/bank/types"
+	minttypes "github.com/cosmos/cosmos-sdk/x/mint/types"
+	slashingtypes "github.com/cosmos/cosmos-sdk/x/slashing/types"
+	"github.com/cosmos/cosmos-sdk/x/staking"
+	stakingtypes "github.com/cosmos/cosmos-sdk/x/staking/types"
+	tmproto "github.com/tendermint/tendermint/proto/tendermint/types"
+	tmtypes "github.com/tendermint/tendermint/types"
+)
+
+// TestnetConfig turns the exported state into the one of a local testnet
+type TestnetConfig struct {
+	// ConsensusKeys replace the consensus keys of Validators, which are left as the only bonded validators
+	ConsensusKeys []cryptotypes.PubKey
+	// Validators are the operator addresses of the validators taking ConsensusKeys, the first bonded validators by
+	// power when empty
+	Validators []sdk.ValAddress
+	// Funds are minted to their address
+	Funds         []banktypes.Balance
+	VotingPeriod  time.Duration
+	UnbondingTime time.Duration
+}
+
+// prepTestnetGenesis applies cfg to the state of ctx: the local consensus keys replace the ones of the chosen
+// validators, the other validators are jailed and the validator set is updated through the staking module so that the
+// pools and last powers stay consistent
+func (app *OraichainApp) prepTestnetGenesis(ctx sdk.Context, cfg TestnetConfig) error {
+	if len(cfg.ConsensusKeys) == 0 {
+		return fmt.Errorf("no consensus keys for the testnet validators")
+	}
+
+	stakingParams := app.stakingKeeper.GetParams(ctx)
+	if cfg.UnbondingTime > 0 {
+		stakingParams.UnbondingTime = cfg.UnbondingTime
+	}
+	app.stakingKeeper.SetParams(ctx, stakingParams)
+
+	if cfg.VotingPeriod > 0 {
+		votingParams := app.govKeeper.GetVotingParams(ctx)
+		votingParams.VotingPeriod = cfg.VotingPeriod
+		app.govKeeper.SetVotingParams(ctx, votingParams)
+	}
+
+	validators, err := app.testnetValidators(ctx, cfg)
+	if err != nil {
+		return err
+	}
+
+	local := make(map[string]bool, len(validators))
+	for i, validator := range validators {
+		if err := app.replaceConsensusKey(ctx, validator, cfg.ConsensusKeys[i]); err != nil {
+			return err
+		}
+		local[validator.OperatorAddress] = true
+	}
+	// the rewards of the last block go to a local validator rather than to a jailed one
+	app.distrKeeper.SetPreviousProposerConsAddr(ctx, sdk.ConsAddress(cfg.ConsensusKeys[0].Address()))
+
+	for _, validator := range app.stakingKeeper.GetAllValidators(ctx) {
+		if local[validator.OperatorAddress] || validator.IsJailed() {
+			continue
+		}
+		consAddr, err := validator.GetConsAddr()
+		if err != nil {
+			return err
+		}
+		app.stakingKeeper.Jail(ctx, consAddr)
+	}
+	if _, err := app.stakingKeeper.ApplyAndReturnValidatorSetUpdates(ctx); err != nil {
+		return err
+	}
+
+	for _, balance := range cfg.Funds {
+		addr, err := sdk.AccAddressFromBech32(balance.Address)
+		if err != nil {
+			return err
+		}
+		if err := app.bankKeeper.MintCoins(ctx, minttypes.ModuleName, balance.Coins); err != nil {
+			return err
+		}
+		if err := app.bankKeeper.SendCoinsFromModuleToAccount(ctx, minttypes.ModuleName, addr, balance.Coins); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// InitInPlaceTestnet applies cfg to the latest state of the application, the changes being committed with the next
+// block at blockTime. It returns the resulting validator set, which the consensus state of the node must be given.
+func (app *OraichainApp) InitInPlaceTestnet(blockTime time.Time, cfg TestnetConfig) ([]tmtypes.GenesisValidator, error) {
+	ctx := app.NewUncachedContext(false, tmproto.Header{Height: app.LastBlockHeight(), Time: blockTime})
+	if err := app.prepTestnetGenesis(ctx, cfg); err != nil {
+		return nil, err
+	}
+	return staking.WriteValidators(ctx, app.stakingKeeper)
+}
+
+// testnetValidators returns the validators taking the consensus keys of cfg
+func (app *OraichainApp) testnetValidators(ctx sdk.Context, cfg TestnetConfig) ([]stakingtypes.Validator, error) {
+	if len(cfg.Validators) == 0 {
+		validators := app.stakingKeeper.GetBondedValidatorsByPower(ctx)
+		if len(validators) < len(cfg.ConsensusKeys) {
+			return nil, fmt.Errorf("%d consensus keys for %d bonded validators", len(cfg.ConsensusKeys), len(validators))
+		}
+		return validators[:len(cfg.ConsensusKeys)], nil
+	}
+
+	if len(cfg.Validators) != len(cfg.ConsensusKeys) {
+		return nil, fmt.Errorf("%d consensus keys for %d validators", len(cfg.ConsensusKeys), len(cfg.Validators))
+	}
+	validators := make([]stakingtypes.Validator, len(cfg.Validators))
+	for i, operator := range cfg.Validators {
+		validator, found := app.stakingKeeper.GetValidator(ctx, operator)
+		if !found {
+			return nil, fmt.Errorf("validator %s not found", operator)
+		}
+		if validator.IsJailed() || !validator.IsBonded() {
+			return nil, fmt.Errorf("validator %s must be bonded and not jailed", operator)
+		}
+		validators[i] = validator
+	}
+	return validators, nil
+}
+
+// replaceConsensusKey sets the consensus key of validator to pubKey, moving its consensus address index and its
+// signing info to the new consensus address
+func (app *OraichainApp) replaceConsensusKey(ctx sdk.Context, validator stakingtypes.Validator, pubKey cryptotypes.PubKey) error {
+	oldConsAddr, err := validator.GetConsAddr()
+	if err != nil {
+		return err
+	}
+	newConsAddr := sdk.ConsAddress(pubKey.Address())
+	if other, found := app.stakingKeeper.GetValidatorByConsAddr(ctx, newConsAddr); found {
+		if other.OperatorAddress == validator.OperatorAddress {
+			return nil
+		}
+		return fmt.Errorf("consensus key %s is already used by validator %s", newConsAddr, other.OperatorAddress)
+	}
+
+	validator.ConsensusPubkey, err = codectypes.NewAnyWithValue(pubKey)
+	if err != nil {
+		return err
+	}
+	ctx.KVStore(app.keys[stakingtypes.StoreKey]).Delete(stakingtypes.GetValidatorByConsAddrKey(oldConsAddr))
+	app.stakingKeeper.SetValidator(ctx, validator)
+	if err := app.stakingKeeper.SetValidatorByConsAddr(ctx, validator); err != nil {
+		return err
+	}
+
+	signingInfo, found := app.slashingKeeper.GetValidatorSigningInfo(ctx, oldConsAddr)
+	if !found {
+		signingInfo = slashingtypes.NewValidatorSigningInfo(newConsAddr, ctx.BlockHeight(), 0, time.Unix(0, 0), false, 0)
+	}
+	signingInfo.Address = newConsAddr.String()
+	signingInfo.IndexOffset, signingInfo.MissedBlocksCounter = 0, 0
+	app.slashingKeeper.SetValidatorSigningInfo(ctx, newConsAddr, signingInfo)
+	return app.slashingKeeper.AddPubkey(ctx, pubKey)
+}
@@ -0,0 +1,170 @@
+package app
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/cosmos/cosmos-sdk/crypto/keys/ed25519"
+	cryptotypes "github.com/cosmos/cosmos-sdk/crypto/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	banktypes "github.com/cosmos/cosmos-sdk/x/bank/types"
+	minttypes "github.com/cosmos/cosmos-sdk/x/mint/types"
+	stakingkeeper "github.com/cosmos/cosmos-sdk/x/staking/keeper"
+	stakingtypes "github.com/cosmos/cosmos-sdk/x/staking/types"
+	appconfig "github.com/oraichain/orai/cmd/config"
+	"github.com/stretchr/testify/require"
+	abci "github.com/tendermint/tendermint/abci/types"
+	tmjson "github.com/tendermint/tendermint/libs/json"
+	tmproto "github.com/tendermint/tendermint/proto/tendermint/types"
+	tmtypes "github.com/tendermint/tendermint/types"
+	dbm "github.com/tendermint/tm-db"
+)
+
+// setupValidatorsApp returns an app with a bonded validator for each of powers
+func setupValidatorsApp(t *testing.T, powers ...int64) (*OraichainApp, []sdk.ValAddress) {
+	app := setupTestApp(t, dbm.NewMemDB(), t.TempDir())
+	ctx := app.NewUncachedContext(false, tmproto.Header{Height: app.LastBlockHeight(), Time: time.Unix(app.LastBlockHeight(), 0).UTC()})
+	msgServer := stakingkeeper.NewMsgServerImpl(app.stakingKeeper)
+
+	var operators []sdk.ValAddress
+	for i, power := range powers {
+		operator := sdk.AccAddress(bytes.Repeat([]byte{byte(i + 1)}, 20))
+		stake := sdk.NewCoin(appconfig.CosmosDenom, sdk.TokensFromConsensusPower(power, sdk.DefaultPowerReduction))
+		require.NoError(t, app.bankKeeper.MintCoins(ctx, minttypes.ModuleName, sdk.NewCoins(stake)))
+		require.NoError(t, app.bankKeeper.SendCoinsFromModuleToAccount(ctx, minttypes.ModuleName, operator, sdk.NewCoins(stake)))
+
+		msg, err := stakingtypes.NewMsgCreateValidator(
+			sdk.ValAddress(operator), ed25519.GenPrivKey().PubKey(), stake, stakingtypes.Description{Moniker: "validator"},
+			stakingtypes.NewCommissionRates(sdk.NewDecWithPrec(1, 1), sdk.NewDecWithPrec(2, 1), sdk.NewDecWithPrec(1, 2)), sdk.OneInt(),
+		)
+		require.NoError(t, err)
+		_, err = msgServer.CreateValidator(sdk.WrapSDKContext(ctx), msg)
+		require.NoError(t, err)
+		operators = append(operators, sdk.ValAddress(operator))
+	}
+	nextBlock(app)
+	require.Len(t, app.stakingKeeper.GetLastValidators(app.NewUncachedContext(false, tmproto.Header{})), len(powers))
+	return app, operators
+}
+
+func TestExportTestnetGenesis(t *testing.T) {
+	app, operators := setupValidatorsApp(t, 10, 30, 20)
+	localKey := ed25519.GenPrivKey().PubKey()
+	funded := sdk.AccAddress("funded______________")
+	funds := sdk.NewCoins(sdk.NewInt64Coin(appconfig.CosmosDenom, 1000))
+
+	var buf bytes.Buffer
+	genDoc := &tmtypes.GenesisDoc{ChainID: "local-testnet", GenesisTime: time.Unix(100, 0).UTC(), ConsensusParams: tmtypes.DefaultConsensusParams()}
+	_, err := app.ExportGenesisStream(&buf, genDoc, GenesisExportOptions{Testnet: &TestnetConfig{
+		ConsensusKeys: []cryptotypes.PubKey{localKey},
+		Funds:         []banktypes.Balance{{Address: funded.String(), Coins: funds}},
+		VotingPeriod:  time.Minute,
+		UnbondingTime: time.Hour,
+	}})
+	require.NoError(t, err)
+
+	var exported tmtypes.GenesisDoc
+	require.NoError(t, tmjson.Unmarshal(buf.Bytes(), &exported))
+	require.Equal(t, "local-testnet", exported.ChainID)
+	require.Len(t, exported.Validators, 1)
+	require.Equal(t, localKey.Address().Bytes(), exported.Validators[0].Address.Bytes())
+	require.Equal(t, int64(30), exported.Validators[0].Power)
+
+	// the exported genesis starts a chain signed by the local key only
+	testnet := newTestApp(t, dbm.NewMemDB(), t.TempDir())
+	res := testnet.InitChain(abci.RequestInitChain{
+		ChainId:         exported.ChainID,
+		InitialHeight:   exported.InitialHeight,
+		ConsensusParams: &abci.ConsensusParams{Block: &abci.BlockParams{MaxBytes: 200000, MaxGas: -1}},
+		AppStateBytes:   exported.AppState,
+	})
+	require.Len(t, res.Validators, 1)
+
+	header := tmproto.Header{ChainID: exported.ChainID, Height: exported.InitialHeight, Time: exported.GenesisTime.Add(time.Second)}
+	testnet.BeginBlock(abci.RequestBeginBlock{Header: header, LastCommitInfo: abci.LastCommitInfo{Votes: []abci.VoteInfo{{
+		Validator:       abci.Validator{Address: localKey.Address(), Power: 30},
+		SignedLastBlock: true,
+	}}}})
+	testnet.EndBlock(abci.RequestEndBlock{Height: header.Height})
+	testnet.Commit()
+
+	ctx := testnet.NewUncachedContext(false, header)
+	require.Equal(t, time.Hour, testnet.stakingKeeper.UnbondingTime(ctx))
+	require.Equal(t, time.Minute, testnet.govKeeper.GetVotingParams(ctx).VotingPeriod)
+	require.Equal(t, funds, testnet.bankKeeper.GetAllBalances(ctx, funded))
+
+	local, found := testnet.stakingKeeper.GetValidator(ctx, operators[1])
+	require.True(t, found)
+	require.True(t, local.IsBonded())
+	for _, operator := range []sdk.ValAddress{operators[0], operators[2]} {
+		validator, found := testnet.stakingKeeper.GetValidator(ctx, operator)
+		require.True(t, found)
+		require.True(t, validator.IsJailed())
+		require.False(t, validator.IsBonded())
+	}
+	require.Zero(t, testnet.CheckState().Broken())
+}
+
+func TestExportTestnetGenesisInvalidConfig(t *testing.T) {
+	app, operators := setupValidatorsApp(t, 10)
+	genDoc := &tmtypes.GenesisDoc{ChainID: "local-testnet", ConsensusParams: tmtypes.DefaultConsensusParams()}
+	key := ed25519.GenPrivKey().PubKey()
+
+	for _, tc := range []struct {
+		cfg TestnetConfig
+		err string
+	}{
+		{TestnetConfig{}, "no consensus keys"},
+		{TestnetConfig{ConsensusKeys: []cryptotypes.PubKey{key, ed25519.GenPrivKey().PubKey()}}, "2 consensus keys for 1 bonded validators"},
+		{TestnetConfig{ConsensusKeys: []cryptotypes.PubKey{key}, Validators: []sdk.ValAddress{sdk.ValAddress("unknown_____________")}}, "not found"},
+	} {
+		cfg := tc.cfg
+		_, err := app.ExportGenesisStream(&bytes.Buffer{}, genDoc, GenesisExportOptions{Testnet: &cfg})
+		require.ErrorContains(t, err, tc.err)
+	}
+
+	_, err := app.ExportGenesisStream(&bytes.Buffer{}, genDoc, GenesisExportOptions{Testnet: &TestnetConfig{ConsensusKeys: []cryptotypes.PubKey{key}, Validators: operators}})
+	require.NoError(t, err)
+}
+
+func TestInitInPlaceTestnet(t *testing.T) {
+	app, operators := setupValidatorsApp(t, 10, 30, 20)
+	localKey := ed25519.GenPrivKey().PubKey()
+	lastHeight := app.LastBlockHeight()
+	blockTime := time.Unix(lastHeight, 0).UTC()
+
+	validators, err := app.InitInPlaceTestnet(blockTime, TestnetConfig{
+		ConsensusKeys: []cryptotypes.PubKey{localKey},
+		Validators:    []sdk.ValAddress{operators[0]},
+		UnbondingTime: time.Hour,
+	})
+	require.NoError(t, err)
+	require.Len(t, validators, 1)
+	require.Equal(t, localKey.Address().Bytes(), validators[0].Address.Bytes())
+	require.Equal(t, int64(10), validators[0].Power)
+	require.Equal(t, lastHeight, app.LastBlockHeight())
+
+	// the next block, whose last commit is signed by the local key only, commits the changes
+	header := tmproto.Header{ChainID: testChainID, Height: lastHeight + 1, Time: blockTime.Add(time.Second)}
+	app.BeginBlock(abci.RequestBeginBlock{Header: header, LastCommitInfo: abci.LastCommitInfo{Votes: []abci.VoteInfo{{
+		Validator:       abci.Validator{Address: localKey.Address(), Power: 10},
+		SignedLastBlock: true,
+	}}}})
+	res := app.EndBlock(abci.RequestEndBlock{Height: header.Height})
+	require.Empty(t, res.ValidatorUpdates)
+	app.Commit()
+
+	ctx := app.NewUncachedContext(false, header)
+	require.Equal(t, time.Hour, app.stakingKeeper.UnbondingTime(ctx))
+	local, found := app.stakingKeeper.GetValidatorByConsAddr(ctx, sdk.ConsAddress(localKey.Address()))
+	require.True(t, found)
+	require.Equal(t, operators[0].String(), local.OperatorAddress)
+	require.True(t, local.IsBonded())
+	for _, operator := range operators[1:] {
+		validator, found := app.stakingKeeper.GetValidator(ctx, operator)
+		require.True(t, found)
+		require.True(t, validator.IsJailed())
+	}
+	require.Zero(t, app.CheckState().Broken())
+}
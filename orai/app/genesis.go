@@ -2,6 +2,9 @@ package app
 
 import (
 	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
 	"time"
 
 	"github.com/cosmos/cosmos-sdk/codec"
@@ -9,65 +12,233 @@ import (
 	crisis "github.com/cosmos/cosmos-sdk/x/crisis/types"
 	gov "github.com/cosmos/cosmos-sdk/x/gov/types"
 	mint "github.com/cosmos/cosmos-sdk/x/mint/types"
+	slashing "github.com/cosmos/cosmos-sdk/x/slashing/types"
 	staking "github.com/cosmos/cosmos-sdk/x/staking/types"
 	appconfig "github.com/oraichain/orai/cmd/config"
 	evm "github.com/tharsis/ethermint/x/evm/types"
 	feemarket "github.com/tharsis/ethermint/x/feemarket/types"
 )
 
+// Names of the genesis profiles
+const (
+	MainnetProfile = "mainnet"
+	TestnetProfile = "testnet"
+	LocalProfile   = "local"
+)
+
+// MainnetChainIDs are the chain ids expected to use the params of the mainnet profile
+var MainnetChainIDs = []string{"Oraichain"}
+
 // GenesisState default state for the application
 type GenesisState map[string]json.RawMessage
 
+// Duration is a time.Duration read from and written to json as a string such as "336h"
+type Duration time.Duration
+
+// MarshalJSON implements json.Marshaler
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+// UnmarshalJSON implements json.Unmarshaler
+func (d *Duration) UnmarshalJSON(bz []byte) error {
+	var s string
+	if err := json.Unmarshal(bz, &s); err != nil {
+		return err
+	}
+	duration, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	*d = Duration(duration)
+	return nil
+}
+
+// GenesisProfile holds the params of the default genesis state that differ between networks
+type GenesisProfile struct {
+	Staking struct {
+		UnbondingTime     Duration `json:"unbonding_time"`
+		MaxValidators     uint32   `json:"max_validators"`
+		HistoricalEntries uint32   `json:"historical_entries"`
+	} `json:"staking"`
+	Gov struct {
+		MinDeposit       sdk.Coins `json:"min_deposit"`
+		MaxDepositPeriod Duration  `json:"max_deposit_period"`
+		VotingPeriod     Duration  `json:"voting_period"`
+	} `json:"gov"`
+	Mint struct {
+		BlocksPerYear uint64 `json:"blocks_per_year"`
+	} `json:"mint"`
+	Slashing struct {
+		SignedBlocksWindow      int64    `json:"signed_blocks_window"`
+		MinSignedPerWindow      sdk.Dec  `json:"min_signed_per_window"`
+		DowntimeJailDuration    Duration `json:"downtime_jail_duration"`
+		SlashFractionDoubleSign sdk.Dec  `json:"slash_fraction_double_sign"`
+		SlashFractionDowntime   sdk.Dec  `json:"slash_fraction_downtime"`
+	} `json:"slashing"`
+	Crisis struct {
+		ConstantFee sdk.Coin `json:"constant_fee"`
+	} `json:"crisis"`
+	Evm struct {
+		EvmDenom string `json:"evm_denom"`
+	} `json:"evm"`
+	Feemarket struct {
+		NoBaseFee                bool    `json:"no_base_fee"`
+		BaseFee                  sdk.Int `json:"base_fee"`
+		BaseFeeChangeDenominator uint32  `json:"base_fee_change_denominator"`
+	} `json:"feemarket"`
+}
+
+// GenesisProfiles are the named profiles of the genesis state
+var GenesisProfiles = map[string]GenesisProfile{
+	MainnetProfile: newGenesisProfile(func(p *GenesisProfile) {
+		p.Staking.UnbondingTime = Duration(time.Hour * 24 * 14)
+		p.Gov.MaxDepositPeriod = Duration(time.Hour * 24 * 2)
+		p.Gov.VotingPeriod = Duration(time.Hour * 24 * 3)
+		p.Slashing.SignedBlocksWindow = 30000 // approximately 1 day
+		p.Slashing.MinSignedPerWindow = sdk.NewDecWithPrec(5, 2)
+		p.Slashing.DowntimeJailDuration = Duration(time.Minute * 10)
+		p.Slashing.SlashFractionDoubleSign = sdk.NewDecWithPrec(5, 2)
+		p.Slashing.SlashFractionDowntime = sdk.NewDecWithPrec(1, 4)
+	}),
+	TestnetProfile: newGenesisProfile(func(p *GenesisProfile) {
+		p.Staking.UnbondingTime = Duration(time.Hour * 2)
+		p.Gov.VotingPeriod = Duration(time.Second * 30)
+	}),
+	LocalProfile: newGenesisProfile(func(p *GenesisProfile) {
+		p.Staking.UnbondingTime = Duration(time.Minute * 10)
+		p.Gov.MaxDepositPeriod = Duration(time.Minute * 10)
+		p.Gov.VotingPeriod = Duration(time.Second * 30)
+		p.Slashing.SignedBlocksWindow = 100
+		p.Slashing.DowntimeJailDuration = Duration(time.Minute)
+	}),
+}
+
+// newGenesisProfile returns the values shared by the profiles, updated by update
+func newGenesisProfile(update func(p *GenesisProfile)) GenesisProfile {
+	var p GenesisProfile
+	stakingParams := staking.DefaultParams()
+	govDepositParams := gov.DefaultDepositParams()
+	slashingParams := slashing.DefaultParams()
+
+	p.Staking.UnbondingTime = Duration(stakingParams.UnbondingTime)
+	p.Staking.MaxValidators = 100
+	p.Staking.HistoricalEntries = 1000
+	p.Gov.MinDeposit = sdk.NewCoins(sdk.NewCoin(appconfig.CosmosDenom, sdk.TokensFromConsensusPower(10, sdk.NewInt(1000000))))
+	p.Gov.MaxDepositPeriod = Duration(govDepositParams.MaxDepositPeriod)
+	p.Gov.VotingPeriod = Duration(gov.DefaultPeriod)
+	p.Mint.BlocksPerYear = 6311200 // target 5-second block time
+	p.Slashing.SignedBlocksWindow = slashingParams.SignedBlocksWindow
+	p.Slashing.MinSignedPerWindow = slashingParams.MinSignedPerWindow
+	p.Slashing.DowntimeJailDuration = Duration(slashingParams.DowntimeJailDuration)
+	p.Slashing.SlashFractionDoubleSign = slashingParams.SlashFractionDoubleSign
+	p.Slashing.SlashFractionDowntime = slashingParams.SlashFractionDowntime
+	p.Crisis.ConstantFee = sdk.NewCoin(appconfig.CosmosDenom, sdk.TokensFromConsensusPower(10, sdk.NewInt(1000000)))
+	p.Evm.EvmDenom = appconfig.EvmDenom
+	p.Feemarket.NoBaseFee = true
+	p.Feemarket.BaseFee = sdk.NewInt(1)
+	p.Feemarket.BaseFeeChangeDenominator = 2
+
+	update(&p)
+	return p
+}
+
+// GenesisProfileNames returns the sorted names of the genesis profiles
+func GenesisProfileNames() []string {
+	names := make([]string, 0, len(GenesisProfiles))
+	for name := range GenesisProfiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// LoadGenesisProfile returns the named profile, with the values of the json file at path overriding its own when path
+// is not empty
+func LoadGenesisProfile(name, path string) (GenesisProfile, error) {
+	profile, found := GenesisProfiles[name]
+	if !found {
+		return profile, fmt.Errorf("unknown genesis profile %s, expected one of %v", name, GenesisProfileNames())
+	}
+	if path == "" {
+		return profile, nil
+	}
+
+	bz, err := os.ReadFile(path)
+	if err != nil {
+		return profile, err
+	}
+
+	// the ints and coins of the named profile would be updated in place by the values of the file, so they are
+	// unmarshalled into a copy of it
+	profileBz, err := json.Marshal(profile)
+	if err != nil {
+		return profile, err
+	}
+	var custom GenesisProfile
+	if err := json.Unmarshal(profileBz, &custom); err != nil {
+		return profile, err
+	}
+	if err := json.Unmarshal(bz, &custom); err != nil {
+		return profile, fmt.Errorf("failed to parse genesis profile %s: %w", path, err)
+	}
+	return custom, nil
+}
+
 // NewDefaultGenesisState generates the default state for the application.
 func NewDefaultGenesisState(cdc codec.Codec) GenesisState {
+	return NewGenesisStateFromProfile(cdc, GenesisProfiles[TestnetProfile])
+}
+
+// NewGenesisStateFromProfile generates the default state for the application with the params of profile.
+func NewGenesisStateFromProfile(cdc codec.Codec, profile GenesisProfile) GenesisState {
 	genesisState := make(GenesisState)
 	// Get default genesis states of the modules we are to override.
 	stakingGenesis := staking.DefaultGenesisState()
 	mintGenesis := mint.DefaultGenesisState()
 	govGenesis := gov.DefaultGenesisState()
+	slashingGenesis := slashing.DefaultGenesisState()
 	crisisGenesis := crisis.DefaultGenesisState()
 	evmGenesis := evm.DefaultGenesisState()
 	feemarketGenesis := feemarket.DefaultGenesisState()
 
-	stakingGenesis.Params.BondDenom = appconfig.Bech32Prefix
-	stakingGenesis.Params.HistoricalEntries = 1000
-
-	// TODO: testnet figures only
-	stakingGenesis.Params.UnbondingTime = time.Hour * 2
-	stakingGenesis.Params.MaxValidators = 100
+	stakingGenesis.Params.BondDenom = appconfig.CosmosDenom
+	stakingGenesis.Params.HistoricalEntries = profile.Staking.HistoricalEntries
+	stakingGenesis.Params.UnbondingTime = time.Duration(profile.Staking.UnbondingTime)
+	stakingGenesis.Params.MaxValidators = profile.Staking.MaxValidators
 	genesisState[staking.ModuleName] = cdc.MustMarshalJSON(stakingGenesis)
 
-	mintGenesis.Params.BlocksPerYear = 6311200 // target 5-second block time
-	mintGenesis.Params.MintDenom = appconfig.Bech32Prefix
+	mintGenesis.Params.BlocksPerYear = profile.Mint.BlocksPerYear
+	mintGenesis.Params.MintDenom = appconfig.CosmosDenom
 	genesisState[mint.ModuleName] = cdc.MustMarshalJSON(mintGenesis)
 
-	govGenesis.DepositParams.MinDeposit = sdk.NewCoins(sdk.NewCoin(appconfig.Bech32Prefix, sdk.TokensFromConsensusPower(10, sdk.NewInt(1000000))))
-	// TODO: testing
-	govGenesis.VotingParams.VotingPeriod = time.Second * 30 // test for 10 mins voting period
+	govGenesis.DepositParams.MinDeposit = profile.Gov.MinDeposit
+	govGenesis.DepositParams.MaxDepositPeriod = time.Duration(profile.Gov.MaxDepositPeriod)
+	govGenesis.VotingParams.VotingPeriod = time.Duration(profile.Gov.VotingPeriod)
 	genesisState[gov.ModuleName] = cdc.MustMarshalJSON(govGenesis)
 
-	crisisGenesis.ConstantFee = sdk.NewCoin(appconfig.Bech32Prefix, sdk.TokensFromConsensusPower(10, sdk.NewInt(1000000)))
+	slashingGenesis.Params.SignedBlocksWindow = profile.Slashing.SignedBlocksWindow
+	slashingGenesis.Params.MinSignedPerWindow = profile.Slashing.MinSignedPerWindow
+	slashingGenesis.Params.DowntimeJailDuration = time.Duration(profile.Slashing.DowntimeJailDuration)
+	slashingGenesis.Params.SlashFractionDoubleSign = profile.Slashing.SlashFractionDoubleSign
+	slashingGenesis.Params.SlashFractionDowntime = profile.Slashing.SlashFractionDowntime
+	genesisState[slashing.ModuleName] = cdc.MustMarshalJSON(slashingGenesis)
+
+	crisisGenesis.ConstantFee = profile.Crisis.ConstantFee
 	genesisState[crisis.ModuleName] = cdc.MustMarshalJSON(crisisGenesis)
 
 	// update default evm denom of evm module
-	evmGenesis.Params.EvmDenom = appconfig.EvmDenom
+	evmGenesis.Params.EvmDenom = profile.Evm.EvmDenom
 	genesisState[evm.ModuleName] = cdc.MustMarshalJSON(evmGenesis)
 
-	feemarketGenesis.Params.BaseFee = sdk.NewInt(1)
-	feemarketGenesis.Params.BaseFeeChangeDenominator = 2
-	feemarketGenesis.Params.NoBaseFee = true
+	feemarketGenesis.Params.BaseFee = profile.Feemarket.BaseFee
+	feemarketGenesis.Params.BaseFeeChangeDenominator = profile.Feemarket.BaseFeeChangeDenominator
+	feemarketGenesis.Params.NoBaseFee = profile.Feemarket.NoBaseFee
 	genesisState[feemarket.ModuleName] = cdc.MustMarshalJSON(feemarketGenesis)
 
-	// slashingGenesis.Params.SignedBlocksWindow = 30000                         // approximately 1 day
-	// slashingGenesis.Params.MinSignedPerWindow = sdk.NewDecWithPrec(5, 2)      // 5%
-	// slashingGenesis.Params.DowntimeJailDuration = 60 * 10 * time.Second       // 10 minutes
-	// slashingGenesis.Params.SlashFractionDoubleSign = sdk.NewDecWithPrec(5, 2) // 5%
-	// slashingGenesis.Params.SlashFractionDowntime = sdk.NewDecWithPrec(1, 4)   // 0.01%
 	// Add your modules here for the genesis states
-
 	for _, b := range ModuleBasics {
-		name := b.Name()
-		if name == staking.ModuleName || name == mint.ModuleName || name == gov.ModuleName || name == crisis.ModuleName || name == evm.ModuleName || name == feemarket.ModuleName {
+		if _, found := genesisState[b.Name()]; found {
 			continue
 		}
 		genesisState[b.Name()] = b.DefaultGenesis(cdc)
@@ -75,3 +246,52 @@ func NewDefaultGenesisState(cdc codec.Codec) GenesisState {
 
 	return genesisState
 }
+
+// IsMainnetChainID returns whether chainID is one of MainnetChainIDs
+func IsMainnetChainID(chainID string) bool {
+	for _, id := range MainnetChainIDs {
+		if chainID == id {
+			return true
+		}
+	}
+	return false
+}
+
+// MainnetProfileWarnings returns the params of genesisState weaker than the ones of the mainnet profile, such as the
+// short unbonding and voting periods of the testnet profile
+func MainnetProfileWarnings(cdc codec.JSONCodec, genesisState GenesisState) []string {
+	mainnet := GenesisProfiles[MainnetProfile]
+	var warnings []string
+	warn := func(param string, value, expected interface{}) {
+		warnings = append(warnings, fmt.Sprintf("%s is %v, below the %v of the %s profile", param, value, expected, MainnetProfile))
+	}
+
+	var stakingGenesis staking.GenesisState
+	if bz, found := genesisState[staking.ModuleName]; found && cdc.UnmarshalJSON(bz, &stakingGenesis) == nil {
+		if stakingGenesis.Params.UnbondingTime < time.Duration(mainnet.Staking.UnbondingTime) {
+			warn("staking unbonding_time", stakingGenesis.Params.UnbondingTime, time.Duration(mainnet.Staking.UnbondingTime))
+		}
+	}
+
+	var govGenesis gov.GenesisState
+	if bz, found := genesisState[gov.ModuleName]; found && cdc.UnmarshalJSON(bz, &govGenesis) == nil {
+		if govGenesis.VotingParams.VotingPeriod < time.Duration(mainnet.Gov.VotingPeriod) {
+			warn("gov voting_period", govGenesis.VotingParams.VotingPeriod, time.Duration(mainnet.Gov.VotingPeriod))
+		}
+		if govGenesis.DepositParams.MaxDepositPeriod < time.Duration(mainnet.Gov.MaxDepositPeriod) {
+			warn("gov max_deposit_period", govGenesis.DepositParams.MaxDepositPeriod, time.Duration(mainnet.Gov.MaxDepositPeriod))
+		}
+	}
+
+	var slashingGenesis slashing.GenesisState
+	if bz, found := genesisState[slashing.ModuleName]; found && cdc.UnmarshalJSON(bz, &slashingGenesis) == nil {
+		if slashingGenesis.Params.SignedBlocksWindow < mainnet.Slashing.SignedBlocksWindow {
+			warn("slashing signed_blocks_window", slashingGenesis.Params.SignedBlocksWindow, mainnet.Slashing.SignedBlocksWindow)
+		}
+		if slashingGenesis.Params.DowntimeJailDuration < time.Duration(mainnet.Slashing.DowntimeJailDuration) {
+			warn("slashing downtime_jail_duration", slashingGenesis.Params.DowntimeJailDuration, time.Duration(mainnet.Slashing.DowntimeJailDuration))
+		}
+	}
+
+	return warnings
+}
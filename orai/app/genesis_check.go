@@ -0,0 +1,284 @@
+package app
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	wasm "github.com/CosmWasm/wasmd/x/wasm/types"
+	"github.com/cosmos/cosmos-sdk/client"
+	"github.com/cosmos/cosmos-sdk/codec"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/types/module"
+	auth "github.com/cosmos/cosmos-sdk/x/auth/types"
+	vesting "github.com/cosmos/cosmos-sdk/x/auth/vesting/exported"
+	bank "github.com/cosmos/cosmos-sdk/x/bank/types"
+	staking "github.com/cosmos/cosmos-sdk/x/staking/types"
+	transfer "github.com/cosmos/ibc-go/v4/modules/apps/transfer/types"
+	appconfig "github.com/oraichain/orai/cmd/config"
+	evm "github.com/tharsis/ethermint/x/evm/types"
+)
+
+// GenesisCheck is the result of a consistency check of the genesis state across modules
+type GenesisCheck struct {
+	Name   string   `json:"name"`
+	Passed bool     `json:"passed"`
+	Errors []string `json:"errors,omitempty"`
+}
+
+// GenesisReport reports the validation of the genesis state of each module and the consistency checks across modules
+type GenesisReport struct {
+	Valid        bool              `json:"valid"`
+	ModuleErrors map[string]string `json:"module_errors,omitempty"`
+	Checks       []GenesisCheck    `json:"checks"`
+	Warnings     []string          `json:"warnings,omitempty"`
+}
+
+// genesisAccounts is the state of the auth and bank modules the checks are run against, at the genesis time
+type genesisAccounts struct {
+	accounts    map[string]auth.GenesisAccount
+	balances    map[string]sdk.Coins
+	bank        bank.GenesisState
+	genesisTime time.Time
+}
+
+// genesisChecks are the consistency checks run on a genesis state, by name
+var genesisChecks = []struct {
+	name  string
+	check func(cdc codec.Codec, genesisState GenesisState, accounts genesisAccounts) ([]string, error)
+}{
+	{"bank_supply", checkBankSupply},
+	{"staking_pools", checkStakingPools},
+	{"evm_denom", checkEvmDenom},
+	{"ibc_denom_traces", checkIBCDenomTraces},
+	{"wasm_contract_accounts", checkWasmContractAccounts},
+	{"vesting_balances", checkVestingBalances},
+}
+
+// ValidateGenesisState validates the genesis state of each module of mbm, then checks the consistency of genesisState
+// across modules at genesisTime
+func ValidateGenesisState(mbm module.BasicManager, cdc codec.Codec, txConfig client.TxEncodingConfig, genesisState GenesisState, genesisTime time.Time) *GenesisReport {
+	report := &GenesisReport{}
+	for name, b := range mbm {
+		if err := b.ValidateGenesis(cdc, txConfig, genesisState[name]); err != nil {
+			if report.ModuleErrors == nil {
+				report.ModuleErrors = make(map[string]string)
+			}
+			report.ModuleErrors[name] = err.Error()
+		}
+	}
+	report.Checks = CheckGenesisConsistency(cdc, genesisState, genesisTime)
+
+	report.Valid = len(report.ModuleErrors) == 0
+	for _, check := range report.Checks {
+		report.Valid = report.Valid && check.Passed
+	}
+	return report
+}
+
+// CheckGenesisConsistency runs the consistency checks across modules on genesisState at genesisTime, the ones of the
+// modules of an undecodable state failing
+func CheckGenesisConsistency(cdc codec.Codec, genesisState GenesisState, genesisTime time.Time) []GenesisCheck {
+	checks := make([]GenesisCheck, len(genesisChecks))
+	accounts, accountsErr := newGenesisAccounts(cdc, genesisState, genesisTime)
+	for i, c := range genesisChecks {
+		checks[i].Name = c.name
+		if accountsErr != nil {
+			checks[i].Errors = []string{accountsErr.Error()}
+			continue
+		}
+		errs, err := c.check(cdc, genesisState, accounts)
+		if err != nil {
+			errs = append(errs, err.Error())
+		}
+		checks[i].Errors = errs
+		checks[i].Passed = len(errs) == 0
+	}
+	return checks
+}
+
+func newGenesisAccounts(cdc codec.Codec, genesisState GenesisState, genesisTime time.Time) (genesisAccounts, error) {
+	accounts := genesisAccounts{
+		accounts:    make(map[string]auth.GenesisAccount),
+		balances:    make(map[string]sdk.Coins),
+		genesisTime: genesisTime,
+	}
+
+	var authGenesis auth.GenesisState
+	if err := unmarshalModuleGenesis(cdc, genesisState, auth.ModuleName, &authGenesis); err != nil {
+		return accounts, err
+	}
+	unpacked, err := auth.UnpackAccounts(authGenesis.Accounts)
+	if err != nil {
+		return accounts, fmt.Errorf("invalid %s genesis: %w", auth.ModuleName, err)
+	}
+	for _, account := range unpacked {
+		accounts.accounts[account.GetAddress().String()] = account
+	}
+
+	if err := unmarshalModuleGenesis(cdc, genesisState, bank.ModuleName, &accounts.bank); err != nil {
+		return accounts, err
+	}
+	for _, balance := range accounts.bank.Balances {
+		accounts.balances[balance.Address] = accounts.balances[balance.Address].Add(balance.Coins...)
+	}
+	return accounts, nil
+}
+
+// unmarshalModuleGenesis decodes the genesis state of module, which must be present
+func unmarshalModuleGenesis(cdc codec.JSONCodec, genesisState GenesisState, module string, state codec.ProtoMarshaler) error {
+	bz, found := genesisState[module]
+	if !found {
+		return fmt.Errorf("no %s genesis", module)
+	}
+	if err := cdc.UnmarshalJSON(bz, state); err != nil {
+		return fmt.Errorf("invalid %s genesis: %w", module, err)
+	}
+	return nil
+}
+
+// checkBankSupply checks that the supply equals the sum of the balances, unless empty as it is then computed from them
+func checkBankSupply(_ codec.Codec, _ GenesisState, accounts genesisAccounts) ([]string, error) {
+	if accounts.bank.Supply.Empty() {
+		return nil, nil
+	}
+	var total sdk.Coins
+	for _, balance := range accounts.bank.Balances {
+		total = total.Add(balance.Coins...)
+	}
+
+	var errs []string
+	for _, denom := range coinDenoms(accounts.bank.Supply, total) {
+		if supply, sum := accounts.bank.Supply.AmountOf(denom), total.AmountOf(denom); !supply.Equal(sum) {
+			errs = append(errs, fmt.Sprintf("supply of %s is %s but the balances sum to %s", denom, supply, sum))
+		}
+	}
+	return errs, nil
+}
+
+// checkStakingPools checks that the bonded and not bonded pools hold the tokens of the validators and of the unbonding
+// delegations, as required by the init genesis of staking
+func checkStakingPools(cdc codec.Codec, genesisState GenesisState, accounts genesisAccounts) ([]string, error) {
+	var stakingGenesis staking.GenesisState
+	if err := unmarshalModuleGenesis(cdc, genesisState, staking.ModuleName, &stakingGenesis); err != nil {
+		return nil, err
+	}
+	bondDenom := stakingGenesis.Params.BondDenom
+
+	bonded, notBonded := sdk.ZeroInt(), sdk.ZeroInt()
+	for _, validator := range stakingGenesis.Validators {
+		if validator.IsBonded() {
+			bonded = bonded.Add(validator.Tokens)
+		} else {
+			notBonded = notBonded.Add(validator.Tokens)
+		}
+	}
+	for _, ubd := range stakingGenesis.UnbondingDelegations {
+		for _, entry := range ubd.Entries {
+			notBonded = notBonded.Add(entry.Balance)
+		}
+	}
+
+	var errs []string
+	for _, pool := range []struct {
+		name   string
+		tokens sdk.Int
+	}{{staking.BondedPoolName, bonded}, {staking.NotBondedPoolName, notBonded}} {
+		balance := accounts.balances[auth.NewModuleAddress(pool.name).String()].AmountOf(bondDenom)
+		if !balance.Equal(pool.tokens) {
+			errs = append(errs, fmt.Sprintf("%s holds %s%s but the staking state accounts for %s%s", pool.name, balance, bondDenom, pool.tokens, bondDenom))
+		}
+	}
+	return errs, nil
+}
+
+// checkEvmDenom checks that the evm denom is the one the evm bank keeper of the app converts from the cosmos denom
+func checkEvmDenom(cdc codec.Codec, genesisState GenesisState, _ genesisAccounts) ([]string, error) {
+	var evmGenesis evm.GenesisState
+	if err := unmarshalModuleGenesis(cdc, genesisState, evm.ModuleName, &evmGenesis); err != nil {
+		return nil, err
+	}
+	if evmGenesis.Params.EvmDenom != appconfig.EvmDenom {
+		return []string{fmt.Sprintf("evm_denom is %s instead of %s", evmGenesis.Params.EvmDenom, appconfig.EvmDenom)}, nil
+	}
+	return nil, nil
+}
+
+// checkIBCDenomTraces checks that the IBC vouchers of the balances and the supply have a denom trace
+func checkIBCDenomTraces(cdc codec.Codec, genesisState GenesisState, accounts genesisAccounts) ([]string, error) {
+	var transferGenesis transfer.GenesisState
+	if err := unmarshalModuleGenesis(cdc, genesisState, transfer.ModuleName, &transferGenesis); err != nil {
+		return nil, err
+	}
+	traces := make(map[string]bool)
+	for _, trace := range transferGenesis.DenomTraces {
+		traces[trace.IBCDenom()] = true
+	}
+
+	coins := accounts.bank.Supply
+	for _, balance := range accounts.bank.Balances {
+		coins = coins.Add(balance.Coins...)
+	}
+	var errs []string
+	for _, coin := range coins {
+		if strings.HasPrefix(coin.Denom, transfer.DenomPrefix+"/") && !traces[coin.Denom] {
+			errs = append(errs, fmt.Sprintf("%s has no denom trace", coin.Denom))
+		}
+	}
+	return errs, nil
+}
+
+// checkWasmContractAccounts checks that the wasm contracts have an account
+func checkWasmContractAccounts(cdc codec.Codec, genesisState GenesisState, accounts genesisAccounts) ([]string, error) {
+	var wasmGenesis wasm.GenesisState
+	if err := unmarshalModuleGenesis(cdc, genesisState, wasm.ModuleName, &wasmGenesis); err != nil {
+		return nil, err
+	}
+	var errs []string
+	for _, contract := range wasmGenesis.Contracts {
+		if _, found := accounts.accounts[contract.ContractAddress]; !found {
+			errs = append(errs, fmt.Sprintf("contract %s has no account", contract.ContractAddress))
+		}
+	}
+	return errs, nil
+}
+
+// checkVestingBalances checks that the balances of the vesting accounts hold their coins locked at the genesis time,
+// the ones still vesting and not delegated, as the bank keeper requires for spending
+func checkVestingBalances(_ codec.Codec, _ GenesisState, accounts genesisAccounts) ([]string, error) {
+	addresses := make([]string, 0, len(accounts.accounts))
+	for address := range accounts.accounts {
+		addresses = append(addresses, address)
+	}
+	sort.Strings(addresses)
+
+	var errs []string
+	for _, address := range addresses {
+		account, ok := accounts.accounts[address].(vesting.VestingAccount)
+		if !ok {
+			continue
+		}
+		locked := account.LockedCoins(accounts.genesisTime)
+		if balance := accounts.balances[address]; !locked.IsAllLTE(balance) {
+			errs = append(errs, fmt.Sprintf("vesting account %s has %s locked at genesis time but a balance of %s", address, locked, balance))
+		}
+	}
+	return errs, nil
+}
+
+// coinDenoms returns the sorted denoms of coins
+func coinDenoms(coins ...sdk.Coins) []string {
+	seen := make(map[string]bool)
+	var denoms []string
+	for _, c := range coins {
+		for _, coin := range c {
+			if !seen[coin.Denom] {
+				seen[coin.Denom] = true
+				denoms = append(denoms, coin.Denom)
+			}
+		}
+	}
+	sort.Strings(denoms)
+	return denoms
+}
@@ -0,0 +1,170 @@
+package app
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	wasmkeeper "github.com/CosmWasm/wasmd/x/wasm/keeper"
+	"github.com/cosmos/cosmos-sdk/codec"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	authtypes "github.com/cosmos/cosmos-sdk/x/auth/types"
+	vestingtypes "github.com/cosmos/cosmos-sdk/x/auth/vesting/types"
+	banktypes "github.com/cosmos/cosmos-sdk/x/bank/types"
+	minttypes "github.com/cosmos/cosmos-sdk/x/mint/types"
+	stakingkeeper "github.com/cosmos/cosmos-sdk/x/staking/keeper"
+	stakingtypes "github.com/cosmos/cosmos-sdk/x/staking/types"
+	transfertypes "github.com/cosmos/ibc-go/v4/modules/apps/transfer/types"
+	appconfig "github.com/oraichain/orai/cmd/config"
+	"github.com/stretchr/testify/require"
+	tmproto "github.com/tendermint/tendermint/proto/tendermint/types"
+	evmtypes "github.com/tharsis/ethermint/x/evm/types"
+)
+
+// updateModuleGenesis decodes the genesis state of module into state, applies update and encodes it back
+func updateModuleGenesis(t *testing.T, cdc codec.Codec, genesisState GenesisState, module string, state codec.ProtoMarshaler, update func()) {
+	require.NoError(t, cdc.UnmarshalJSON(genesisState[module], state))
+	update()
+	bz, err := cdc.MarshalJSON(state)
+	require.NoError(t, err)
+	genesisState[module] = bz
+}
+
+// failedChecks returns the errors of the failed checks, by name
+func failedChecks(checks []GenesisCheck) map[string][]string {
+	failed := make(map[string][]string)
+	for _, check := range checks {
+		if !check.Passed {
+			failed[check.Name] = check.Errors
+		}
+	}
+	return failed
+}
+
+func TestValidateGenesisState(t *testing.T) {
+	app, contracts := setupWasmExportApp(t)
+	_, genState := exportGenesisStream(t, app, GenesisExportOptions{})
+	cdc := app.appCodec
+
+	report := ValidateGenesisState(ModuleBasics, cdc, MakeEncodingConfig().TxConfig, genState, time.Time{})
+	require.True(t, report.Valid)
+	require.Empty(t, report.ModuleErrors)
+	require.Len(t, report.Checks, len(genesisChecks))
+	require.Empty(t, failedChecks(report.Checks))
+
+	voucher := transfertypes.ParseDenomTrace("transfer/channel-0/uatom").IBCDenom()
+	vestingAddr := sdk.AccAddress("vesting_____________")
+	var bank banktypes.GenesisState
+	updateModuleGenesis(t, cdc, genState, banktypes.ModuleName, &bank, func() {
+		bank.Balances = append(bank.Balances, banktypes.Balance{
+			Address: vestingAddr.String(),
+			Coins:   sdk.NewCoins(sdk.NewInt64Coin("orai", 100), sdk.NewInt64Coin(voucher, 1)),
+		})
+		// the app has no validator, so the bonded pool holds no tokens
+		bank.Balances = append(bank.Balances, banktypes.Balance{
+			Address: authtypes.NewModuleAddress(stakingtypes.BondedPoolName).String(),
+			Coins:   sdk.NewCoins(sdk.NewInt64Coin(appconfig.CosmosDenom, 1)),
+		})
+		bank.Supply = bank.Supply.Add(sdk.NewInt64Coin(voucher, 1))
+	})
+	var auth authtypes.GenesisState
+	updateModuleGenesis(t, cdc, genState, authtypes.ModuleName, &auth, func() {
+		accounts, err := authtypes.UnpackAccounts(auth.Accounts)
+		require.NoError(t, err)
+		var kept authtypes.GenesisAccounts
+		for _, account := range accounts {
+			if !account.GetAddress().Equals(contracts[0]) {
+				kept = append(kept, account)
+			}
+		}
+		kept = append(kept, vestingtypes.NewContinuousVestingAccount(authtypes.NewBaseAccountWithAddress(vestingAddr),
+			sdk.NewCoins(sdk.NewInt64Coin("orai", 1000)), 0, 1000))
+		auth.Accounts, err = authtypes.PackAccounts(kept)
+		require.NoError(t, err)
+	})
+	var evm evmtypes.GenesisState
+	updateModuleGenesis(t, cdc, genState, evmtypes.ModuleName, &evm, func() {
+		evm.Params.EvmDenom = "uorai"
+	})
+
+	report = ValidateGenesisState(ModuleBasics, cdc, MakeEncodingConfig().TxConfig, genState, time.Time{})
+	require.False(t, report.Valid)
+	failed := failedChecks(report.Checks)
+	require.Len(t, failed, len(genesisChecks))
+	require.Equal(t, []string{"supply of orai is 0 but the balances sum to 101"}, failed["bank_supply"])
+	require.Len(t, failed["staking_pools"], 1)
+	require.Contains(t, failed["staking_pools"][0], stakingtypes.BondedPoolName)
+	require.Equal(t, []string{"evm_denom is uorai instead of aorai"}, failed["evm_denom"])
+	require.Equal(t, []string{voucher + " has no denom trace"}, failed["ibc_denom_traces"])
+	require.Equal(t, []string{"contract " + contracts[0].String() + " has no account"}, failed["wasm_contract_accounts"])
+	require.Len(t, failed["vesting_balances"], 1)
+	require.Contains(t, failed["vesting_balances"][0], vestingAddr.String())
+
+	// the checks of an undecodable state fail instead of panicking
+	delete(genState, banktypes.ModuleName)
+	report = ValidateGenesisState(ModuleBasics, cdc, MakeEncodingConfig().TxConfig, genState, time.Time{})
+	require.False(t, report.Valid)
+	require.Contains(t, report.ModuleErrors, banktypes.ModuleName)
+	for _, check := range report.Checks {
+		require.Equal(t, []string{"no bank genesis"}, check.Errors)
+	}
+}
+
+func TestValidateExportedGenesisState(t *testing.T) {
+	app, operators := setupValidatorsApp(t, 10, 20)
+	// the vesting account half vested at the export, the vested half being spent
+	genesisTime := time.Unix(500, 0).UTC()
+	ctx := app.NewUncachedContext(false, tmproto.Header{Height: app.LastBlockHeight(), Time: genesisTime})
+
+	// an unbonding delegation, for the not bonded pool to hold tokens
+	operator := sdk.AccAddress(operators[0])
+	_, err := stakingkeeper.NewMsgServerImpl(app.stakingKeeper).Undelegate(sdk.WrapSDKContext(ctx),
+		stakingtypes.NewMsgUndelegate(operator, operators[0], sdk.NewCoin(appconfig.CosmosDenom, sdk.TokensFromConsensusPower(1, sdk.DefaultPowerReduction))))
+	require.NoError(t, err)
+
+	vestingAddr := sdk.AccAddress("vesting_____________")
+	vestingCoins := sdk.NewCoins(sdk.NewInt64Coin(appconfig.CosmosDenom, 100))
+	app.accountKeeper.SetAccount(ctx, vestingtypes.NewContinuousVestingAccount(
+		app.accountKeeper.NewAccountWithAddress(ctx, vestingAddr).(*authtypes.BaseAccount), vestingCoins, 0, 1000))
+	require.NoError(t, app.bankKeeper.MintCoins(ctx, minttypes.ModuleName, vestingCoins))
+	require.NoError(t, app.bankKeeper.SendCoinsFromModuleToAccount(ctx, minttypes.ModuleName, vestingAddr, vestingCoins))
+	require.NoError(t, app.bankKeeper.SendCoins(ctx, vestingAddr, operator, sdk.NewCoins(sdk.NewInt64Coin(appconfig.CosmosDenom, 50))))
+
+	// an IBC voucher with its trace
+	trace := transfertypes.ParseDenomTrace("transfer/channel-0/uatom")
+	app.transferKeeper.SetDenomTrace(ctx, trace)
+	vouchers := sdk.NewCoins(sdk.NewInt64Coin(trace.IBCDenom(), 10))
+	require.NoError(t, app.bankKeeper.MintCoins(ctx, minttypes.ModuleName, vouchers))
+	require.NoError(t, app.bankKeeper.SendCoinsFromModuleToAccount(ctx, minttypes.ModuleName, operator, vouchers))
+
+	// a wasm contract
+	wasmCode, err := os.ReadFile("../scripts/wasm_file/cw-clock-example.wasm")
+	require.NoError(t, err)
+	contractKeeper := wasmkeeper.NewDefaultPermissionKeeper(app.wasmKeeper)
+	codeID, _, err := contractKeeper.Create(ctx, operator, wasmCode, nil)
+	require.NoError(t, err)
+	_, _, err = contractKeeper.Instantiate(ctx, codeID, operator, nil, []byte("{}"), "contract", nil)
+	require.NoError(t, err)
+	nextBlock(app)
+
+	_, genState := exportGenesisStream(t, app, GenesisExportOptions{})
+	report := ValidateGenesisState(ModuleBasics, app.appCodec, MakeEncodingConfig().TxConfig, genState, genesisTime)
+	require.Empty(t, report.ModuleErrors)
+	require.Empty(t, failedChecks(report.Checks))
+	require.True(t, report.Valid)
+
+	// the checks see what they check
+	var bank banktypes.GenesisState
+	app.appCodec.MustUnmarshalJSON(genState[banktypes.ModuleName], &bank)
+	require.False(t, bank.Supply.AmountOf(trace.IBCDenom()).IsZero())
+	require.Contains(t, bank.Balances, banktypes.Balance{Address: vestingAddr.String(), Coins: sdk.NewCoins(sdk.NewInt64Coin(appconfig.CosmosDenom, 50))})
+	var staking stakingtypes.GenesisState
+	app.appCodec.MustUnmarshalJSON(genState[stakingtypes.ModuleName], &staking)
+	require.Len(t, staking.UnbondingDelegations, 1)
+
+	// before half of it vested, the balance of the vesting account is too low
+	failed := failedChecks(CheckGenesisConsistency(app.appCodec, genState, time.Unix(400, 0)))
+	require.Len(t, failed, 1)
+	require.Len(t, failed["vesting_balances"], 1)
+	require.Contains(t, failed["vesting_balances"][0], "has 60orai locked at genesis time but a balance of 50orai")
+}
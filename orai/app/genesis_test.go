@@ -0,0 +1,73 @@
+package app
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	slashingtypes "github.com/cosmos/cosmos-sdk/x/slashing/types"
+	stakingtypes "github.com/cosmos/cosmos-sdk/x/staking/types"
+	"github.com/stretchr/testify/require"
+	feemarkettypes "github.com/tharsis/ethermint/x/feemarket/types"
+)
+
+func TestGenesisProfilesAreValid(t *testing.T) {
+	cdc := MakeEncodingConfig().Codec
+	for _, name := range GenesisProfileNames() {
+		t.Run(name, func(t *testing.T) {
+			profile, err := LoadGenesisProfile(name, "")
+			require.NoError(t, err)
+			require.NoError(t, ModuleBasics.ValidateGenesis(cdc, MakeEncodingConfig().TxConfig, NewGenesisStateFromProfile(cdc, profile)))
+		})
+	}
+
+	_, err := LoadGenesisProfile("devnet", "")
+	require.ErrorContains(t, err, "unknown genesis profile devnet")
+}
+
+func TestLoadGenesisProfileFile(t *testing.T) {
+	cdc := MakeEncodingConfig().Codec
+	path := filepath.Join(t.TempDir(), "profile.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{
+		"staking": {"unbonding_time": "336h"},
+		"slashing": {"slash_fraction_downtime": "0.001"},
+		"feemarket": {"base_fee": "1000"}
+	}`), 0o600))
+
+	profile, err := LoadGenesisProfile(MainnetProfile, path)
+	require.NoError(t, err)
+	genesisState := NewGenesisStateFromProfile(cdc, profile)
+
+	var stakingGenesis stakingtypes.GenesisState
+	cdc.MustUnmarshalJSON(genesisState[stakingtypes.ModuleName], &stakingGenesis)
+	require.Equal(t, 336*time.Hour, stakingGenesis.Params.UnbondingTime)
+	require.Equal(t, uint32(100), stakingGenesis.Params.MaxValidators)
+
+	var slashingGenesis slashingtypes.GenesisState
+	cdc.MustUnmarshalJSON(genesisState[slashingtypes.ModuleName], &slashingGenesis)
+	require.Equal(t, sdk.NewDecWithPrec(1, 3), slashingGenesis.Params.SlashFractionDowntime)
+	require.Equal(t, int64(30000), slashingGenesis.Params.SignedBlocksWindow)
+
+	var feemarketGenesis feemarkettypes.GenesisState
+	cdc.MustUnmarshalJSON(genesisState[feemarkettypes.ModuleName], &feemarketGenesis)
+	require.Equal(t, sdk.NewInt(1000), feemarketGenesis.Params.BaseFee)
+
+	// the named profile is left unchanged
+	require.Equal(t, sdk.NewInt(1), GenesisProfiles[MainnetProfile].Feemarket.BaseFee)
+	require.Equal(t, sdk.NewDecWithPrec(1, 4), GenesisProfiles[MainnetProfile].Slashing.SlashFractionDowntime)
+}
+
+func TestMainnetProfileWarnings(t *testing.T) {
+	cdc := MakeEncodingConfig().Codec
+
+	require.Empty(t, MainnetProfileWarnings(cdc, NewGenesisStateFromProfile(cdc, GenesisProfiles[MainnetProfile])))
+
+	warnings := MainnetProfileWarnings(cdc, NewDefaultGenesisState(cdc))
+	require.Equal(t, []string{
+		"staking unbonding_time is 2h0m0s, below the 336h0m0s of the mainnet profile",
+		"gov voting_period is 30s, below the 72h0m0s of the mainnet profile",
+		"slashing signed_blocks_window is 100, below the 30000 of the mainnet profile",
+	}, warnings)
+}
@@ -0,0 +1,143 @@
+package app
+
+import (
+	"fmt"
+
+	storetypes "github.com/cosmos/cosmos-sdk/store/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/types/module"
+	authtypes "github.com/cosmos/cosmos-sdk/x/auth/types"
+	upgradetypes "github.com/cosmos/cosmos-sdk/x/upgrade/types"
+	"github.com/oraichain/orai/app/upgrades"
+	"github.com/oraichain/orai/app/upgrades/v0420"
+	"github.com/oraichain/orai/app/upgrades/v0430"
+)
+
+// Upgrades are the software upgrades of the chain, oldest first. New releases append their upgrade to the list.
+var Upgrades = []upgrades.Upgrade{
+	v0420.Upgrade,
+	v0430.Upgrade,
+}
+
+// appKeepers returns the keepers passed to the upgrade handlers
+func (app *OraichainApp) appKeepers() *upgrades.AppKeepers {
+	return &upgrades.AppKeepers{
+		AccountKeeper:       app.accountKeeper,
+		BankKeeper:          app.bankKeeper,
+		CapabilityKeeper:    app.capabilityKeeper,
+		DistrKeeper:         app.distrKeeper,
+		EvmKeeper:           app.evmKeeper,
+		IBCKeeper:           app.ibcKeeper,
+		ICAAuthKeeper:       app.icaAuthKeeper,
+		ScopedInterTxKeeper: app.scopedInterTxKeeper,
+	}
+}
+
+// setupUpgradeHandlers registers the handlers of all the upgrades
+func (app *OraichainApp) setupUpgradeHandlers() {
+	for _, upgrade := range Upgrades {
+		app.upgradeKeeper.SetUpgradeHandler(
+			upgrade.UpgradeName,
+			app.checkRemovedModules(upgrade, upgrade.CreateUpgradeHandler(app.mm, app.configurator, app.appKeepers())),
+		)
+	}
+}
+
+// checkRemovedModules wraps the handler of upgrade, failing the upgrade if a module account of the deleted stores
+// still holds funds once the handler ran, and dropping their modules from the version map. The handler runs on a
+// branch of the pre-upgrade state that is only written once the check passed, and the data of the deleted stores is
+// only dropped by the root multistore in the commit of the upgrade block, so a failed upgrade leaves the state of the
+// node untouched.
+func (app *OraichainApp) checkRemovedModules(upgrade upgrades.Upgrade, handler upgradetypes.UpgradeHandler) upgradetypes.UpgradeHandler {
+	return func(ctx sdk.Context, plan upgradetypes.Plan, fromVM module.VersionMap) (module.VersionMap, error) {
+		cacheCtx, write := ctx.CacheContext()
+		versionMap, err := handler(cacheCtx, plan, fromVM)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, name := range upgrade.StoreUpgrades.Deleted {
+			addr := authtypes.NewModuleAddress(name)
+			if balances := app.bankKeeper.GetAllBalances(cacheCtx, addr); !balances.IsZero() {
+				return nil, fmt.Errorf("module account %s of removed module %s still holds %s", addr, name, balances)
+			}
+			delete(versionMap, name)
+		}
+		app.deleteModuleVersions(cacheCtx, upgrade.StoreUpgrades.Deleted)
+
+		write()
+		ctx.EventManager().EmitEvents(cacheCtx.EventManager().Events())
+		return versionMap, nil
+	}
+}
+
+// deleteModuleVersions removes the versions of the modules from the version map of the upgrade module, which only
+// ever sets the versions of the modules that still exist
+func (app *OraichainApp) deleteModuleVersions(ctx sdk.Context, names []string) {
+	store := ctx.KVStore(app.keys[upgradetypes.StoreKey])
+	for _, name := range names {
+		store.Delete(append([]byte{upgradetypes.VersionMapByte}, name...))
+	}
+}
+
+// removedStoreKeys returns the keys of the stores deleted by the upgrades. The app keeps mounting them, empty once
+// their upgrade ran, as the root multistore only deletes the data of the stores it mounts and commits every store it
+// mounts: nodes restarted after the upgrade have to commit the same stores as the ones that ran it.
+func removedStoreKeys() map[string]*sdk.KVStoreKey {
+	keys := make(map[string]*sdk.KVStoreKey)
+	for _, upgrade := range Upgrades {
+		for _, name := range upgrade.StoreUpgrades.Deleted {
+			keys[name] = sdk.NewKVStoreKey(name)
+		}
+	}
+	return keys
+}
+
+// setupUpgradeStoreLoaders applies the store upgrades of the plan the node halted at, if any
+func (app *OraichainApp) setupUpgradeStoreLoaders() {
+	upgradeInfo, err := app.upgradeKeeper.ReadUpgradeInfoFromDisk()
+	if err != nil {
+		panic(err)
+	}
+
+	if app.upgradeKeeper.IsSkipHeight(upgradeInfo.Height) {
+		return
+	}
+
+	for _, upgrade := range Upgrades {
+		if upgradeInfo.Name == upgrade.UpgradeName {
+			storeUpgrades := upgrade.StoreUpgrades
+			if err := app.validateStoreUpgrades(&storeUpgrades); err != nil {
+				panic(fmt.Errorf("invalid store upgrades of %s: %w", upgrade.UpgradeName, err))
+			}
+			// configure store loader that checks if version == upgradeHeight and applies store upgrades
+			app.SetStoreLoader(upgradetypes.UpgradeStoreLoader(upgradeInfo.Height, &storeUpgrades))
+		}
+	}
+}
+
+// validateStoreUpgrades checks the store upgrades against the stores mounted by the app
+func (app *OraichainApp) validateStoreUpgrades(storeUpgrades *storetypes.StoreUpgrades) error {
+	for _, name := range storeUpgrades.Added {
+		if _, ok := app.keys[name]; !ok {
+			return fmt.Errorf("added store %s is not mounted", name)
+		}
+	}
+	for _, rename := range storeUpgrades.Renamed {
+		if _, ok := app.keys[rename.NewKey]; !ok {
+			return fmt.Errorf("store %s renamed from %s is not mounted", rename.NewKey, rename.OldKey)
+		}
+		if _, ok := app.keys[rename.OldKey]; ok {
+			return fmt.Errorf("store %s renamed to %s is still mounted", rename.OldKey, rename.NewKey)
+		}
+	}
+	for _, name := range storeUpgrades.Deleted {
+		if _, ok := app.keys[name]; ok {
+			return fmt.Errorf("deleted store %s is still mounted", name)
+		}
+		if _, ok := app.mm.Modules[name]; ok {
+			return fmt.Errorf("module %s of deleted store is still registered", name)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,45 @@
+package upgrades
+
+import (
+	storetypes "github.com/cosmos/cosmos-sdk/store/types"
+	"github.com/cosmos/cosmos-sdk/types/module"
+	authkeeper "github.com/cosmos/cosmos-sdk/x/auth/keeper"
+	bankkeeper "github.com/cosmos/cosmos-sdk/x/bank/keeper"
+	capabilitykeeper "github.com/cosmos/cosmos-sdk/x/capability/keeper"
+	distrkeeper "github.com/cosmos/cosmos-sdk/x/distribution/keeper"
+	upgradetypes "github.com/cosmos/cosmos-sdk/x/upgrade/types"
+	ibckeeper "github.com/cosmos/ibc-go/v4/modules/core/keeper"
+	icaauthkeeper "github.com/oraichain/orai/x/icaauth/keeper"
+	evmkeeper "github.com/tharsis/ethermint/x/evm/keeper"
+)
+
+// AppKeepers are the keepers of the app that the upgrade handlers may use
+type AppKeepers struct {
+	AccountKeeper    authkeeper.AccountKeeper
+	BankKeeper       bankkeeper.Keeper
+	CapabilityKeeper *capabilitykeeper.Keeper
+	DistrKeeper      distrkeeper.Keeper
+	EvmKeeper        *evmkeeper.Keeper
+	IBCKeeper        *ibckeeper.Keeper
+	ICAAuthKeeper    icaauthkeeper.Keeper
+
+	// ScopedInterTxKeeper is the capability scope of the removed inter-tx module
+	ScopedInterTxKeeper capabilitykeeper.ScopedKeeper
+}
+
+// Upgrade defines a software upgrade of the chain. Each release adds its own Upgrade to the registry of the app
+// instead of replacing the previous one, so that nodes replaying old heights or testnets running older plans still
+// find the handler of the plan.
+type Upgrade struct {
+	// UpgradeName is the name of the upgrade plan
+	UpgradeName string
+
+	// CreateUpgradeHandler creates the handler run when the chain reaches the plan height
+	CreateUpgradeHandler func(*module.Manager, module.Configurator, *AppKeepers) upgradetypes.UpgradeHandler
+
+	// StoreUpgrades are the stores added, renamed or deleted at the plan height. The app must mount the added stores
+	// and the new names of the renamed ones, and must no longer mount the old names nor register the modules of the
+	// deleted stores, which the app keeps mounting empty. The module account named after a deleted store must be
+	// emptied by the handler, or the upgrade fails.
+	StoreUpgrades storetypes.StoreUpgrades
+}
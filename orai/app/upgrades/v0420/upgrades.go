@@ -0,0 +1,39 @@
+package v0420
+
+import (
+	storetypes "github.com/cosmos/cosmos-sdk/store/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/types/module"
+	upgradetypes "github.com/cosmos/cosmos-sdk/x/upgrade/types"
+	evmutiltypes "github.com/kava-labs/kava/x/evmutil/types"
+	"github.com/oraichain/orai/app/upgrades"
+	appconfig "github.com/oraichain/orai/cmd/config"
+	evmtypes "github.com/tharsis/ethermint/x/evm/types"
+	feemarkettypes "github.com/tharsis/ethermint/x/feemarket/types"
+)
+
+// UpgradeName is the name of the upgrade adding the EVM modules
+const UpgradeName = "v0.42.0"
+
+// Upgrade adds the evm, feemarket and evmutil modules
+var Upgrade = upgrades.Upgrade{
+	UpgradeName:          UpgradeName,
+	CreateUpgradeHandler: CreateUpgradeHandler,
+	StoreUpgrades: storetypes.StoreUpgrades{
+		Added: []string{evmtypes.StoreKey, feemarkettypes.StoreKey, evmutiltypes.StoreKey},
+	},
+}
+
+// CreateUpgradeHandler runs the migrations, which initialize the added modules, and sets the evm denom
+func CreateUpgradeHandler(mm *module.Manager, configurator module.Configurator, keepers *upgrades.AppKeepers) upgradetypes.UpgradeHandler {
+	return func(ctx sdk.Context, plan upgradetypes.Plan, fromVM module.VersionMap) (module.VersionMap, error) {
+		response, err := mm.RunMigrations(ctx, configurator, fromVM)
+		ctx.Logger().Info("Start updating evm params...")
+		defaultEvmParams := evmtypes.DefaultParams()
+		defaultEvmParams.EvmDenom = appconfig.EvmDenom // orai aka 10^-6
+		keepers.EvmKeeper.SetParams(ctx, defaultEvmParams)
+
+		ctx.Logger().Info("Finished updating evm params...")
+		return response, err
+	}
+}
@@ -0,0 +1,82 @@
+package v0430
+
+import (
+	"strings"
+
+	storetypes "github.com/cosmos/cosmos-sdk/store/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/types/module"
+	authtypes "github.com/cosmos/cosmos-sdk/x/auth/types"
+	upgradetypes "github.com/cosmos/cosmos-sdk/x/upgrade/types"
+	icatypes "github.com/cosmos/ibc-go/v4/modules/apps/27-interchain-accounts/types"
+	host "github.com/cosmos/ibc-go/v4/modules/core/24-host"
+	"github.com/oraichain/orai/app/upgrades"
+)
+
+const (
+	// UpgradeName is the name of the upgrade replacing the inter-tx module with icaauth
+	UpgradeName = "v0.43.0"
+
+	// InterTxModuleName is the name of the removed inter-tx module, which was also the name of its store
+	InterTxModuleName = "intertx"
+)
+
+// Upgrade removes the demo inter-tx module, handing the interchain accounts registered through it over to icaauth
+var Upgrade = upgrades.Upgrade{
+	UpgradeName:          UpgradeName,
+	CreateUpgradeHandler: CreateUpgradeHandler,
+	StoreUpgrades: storetypes.StoreUpgrades{
+		Deleted: []string{InterTxModuleName},
+	},
+}
+
+// CreateUpgradeHandler moves the channel capabilities owned by inter-tx to icaauth and runs the migrations, which
+// initialize icaauth
+func CreateUpgradeHandler(mm *module.Manager, configurator module.Configurator, keepers *upgrades.AppKeepers) upgradetypes.UpgradeHandler {
+	return func(ctx sdk.Context, plan upgradetypes.Plan, fromVM module.VersionMap) (module.VersionMap, error) {
+		if err := MigrateInterTxChannels(ctx, keepers); err != nil {
+			return nil, err
+		}
+
+		// inter-tx never had a module account, anything sent to its address goes to the community pool
+		interTxAddr := authtypes.NewModuleAddress(InterTxModuleName)
+		if balances := keepers.BankKeeper.GetAllBalances(ctx, interTxAddr); !balances.IsZero() {
+			if err := keepers.DistrKeeper.FundCommunityPool(ctx, balances, interTxAddr); err != nil {
+				return nil, err
+			}
+		}
+
+		return mm.RunMigrations(ctx, configurator, fromVM)
+	}
+}
+
+// MigrateInterTxChannels hands the capabilities of the interchain accounts controller channels owned by inter-tx over
+// to icaauth, so that their owners keep sending txs through the accounts and their packets are routed to icaauth
+func MigrateInterTxChannels(ctx sdk.Context, keepers *upgrades.AppKeepers) error {
+	// the upgrade runs before the begin blocker of the capability module, which initializes the memory store of the
+	// capabilities on the first block of the node
+	keepers.CapabilityKeeper.InitMemStore(ctx)
+
+	migrated := 0
+	for _, channel := range keepers.IBCKeeper.ChannelKeeper.GetAllChannels(ctx) {
+		if !strings.HasPrefix(channel.PortId, icatypes.PortPrefix) {
+			continue
+		}
+
+		name := host.ChannelCapabilityPath(channel.PortId, channel.ChannelId)
+		capability, found := keepers.ScopedInterTxKeeper.GetCapability(ctx, name)
+		if !found {
+			continue
+		}
+		if err := keepers.ICAAuthKeeper.ClaimCapability(ctx, capability, name); err != nil {
+			return err
+		}
+		if err := keepers.ScopedInterTxKeeper.ReleaseCapability(ctx, capability); err != nil {
+			return err
+		}
+		migrated++
+	}
+
+	ctx.Logger().Info("migrated inter-tx interchain accounts channels", "channels", migrated)
+	return nil
+}
@@ -0,0 +1,428 @@
+package app
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/CosmWasm/wasmd/x/wasm"
+	"github.com/cosmos/cosmos-sdk/store/prefix"
+	"github.com/cosmos/cosmos-sdk/store/rootmulti"
+	storetypes "github.com/cosmos/cosmos-sdk/store/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/types/module"
+	authtypes "github.com/cosmos/cosmos-sdk/x/auth/types"
+	banktypes "github.com/cosmos/cosmos-sdk/x/bank/types"
+	minttypes "github.com/cosmos/cosmos-sdk/x/mint/types"
+	upgradetypes "github.com/cosmos/cosmos-sdk/x/upgrade/types"
+	"github.com/cosmos/iavl"
+	icatypes "github.com/cosmos/ibc-go/v4/modules/apps/27-interchain-accounts/types"
+	channeltypes "github.com/cosmos/ibc-go/v4/modules/core/04-channel/types"
+	host "github.com/cosmos/ibc-go/v4/modules/core/24-host"
+	"github.com/oraichain/orai/app/upgrades"
+	"github.com/oraichain/orai/app/upgrades/v0430"
+	appconfig "github.com/oraichain/orai/cmd/config"
+	icaauthtypes "github.com/oraichain/orai/x/icaauth/types"
+	"github.com/stretchr/testify/require"
+	abci "github.com/tendermint/tendermint/abci/types"
+	"github.com/tendermint/tendermint/libs/log"
+	tmproto "github.com/tendermint/tendermint/proto/tendermint/types"
+	dbm "github.com/tendermint/tm-db"
+	evmtypes "github.com/tharsis/ethermint/x/evm/types"
+	feemarkettypes "github.com/tharsis/ethermint/x/feemarket/types"
+)
+
+const testChainID = "Oraichain"
+
+// newTestApp creates an app on db, loading its latest version
+func newTestApp(t *testing.T, db dbm.DB, home string) *OraichainApp {
+	return NewOraichainApp(
+		log.NewNopLogger(), db, nil, true, map[int64]bool{}, home, 0, MakeEncodingConfig(),
+		wasm.EnableAllProposals, EmptyAppOptions{}, emptyWasmOpts, EvmOptions{},
+	)
+}
+
+// setupTestApp creates an app initialized with the default genesis and commits the first block
+func setupTestApp(t *testing.T, db dbm.DB, home string) *OraichainApp {
+	app := newTestApp(t, db, home)
+
+	stateBytes, err := json.Marshal(NewDefaultGenesisState(app.appCodec))
+	require.NoError(t, err)
+	app.InitChain(abci.RequestInitChain{
+		ChainId:    testChainID,
+		Validators: []abci.ValidatorUpdate{},
+		// block params are read by the feemarket begin blocker once the base fee is enabled
+		ConsensusParams: &abci.ConsensusParams{Block: &abci.BlockParams{MaxBytes: 200000, MaxGas: -1}},
+		AppStateBytes:   stateBytes,
+	})
+	app.Commit()
+	return app
+}
+
+// runUpgrade runs the handler of the upgrade against the latest state of the app and returns the new version map
+func runUpgrade(t *testing.T, app *OraichainApp, upgrade upgrades.Upgrade) module.VersionMap {
+	header := tmproto.Header{ChainID: testChainID, Height: app.LastBlockHeight() + 1}
+	ctx := app.NewUncachedContext(false, header)
+
+	plan := upgradetypes.Plan{Name: upgrade.UpgradeName, Height: header.Height}
+	handler := upgrade.CreateUpgradeHandler(app.mm, app.configurator, app.appKeepers())
+	versionMap, err := handler(ctx, plan, app.upgradeKeeper.GetModuleVersionMap(ctx))
+	require.NoError(t, err)
+	return versionMap
+}
+
+// nextBlock runs an empty block on top of the latest committed state of the app
+func nextBlock(app *OraichainApp) {
+	height := app.LastBlockHeight() + 1
+	header := tmproto.Header{ChainID: testChainID, Height: height, Time: time.Unix(height, 0).UTC()}
+	app.BeginBlock(abci.RequestBeginBlock{Header: header})
+	app.EndBlock(abci.RequestEndBlock{Height: height})
+	app.Commit()
+}
+
+// commitInfo reads the commit info of the root multistore at version from db
+func commitInfo(t *testing.T, db dbm.DB, version int64) *storetypes.CommitInfo {
+	bz, err := db.Get([]byte(fmt.Sprintf("s/%d", version)))
+	require.NoError(t, err)
+	require.NotNil(t, bz, "no commit info at version %d", version)
+
+	info := &storetypes.CommitInfo{}
+	require.NoError(t, info.Unmarshal(bz))
+	return info
+}
+
+// stripStores removes the given stores from the commit info at version and deletes their data from db,
+// leaving db as if it was written by a binary that never mounted them
+func stripStores(t *testing.T, db dbm.DB, version int64, names []string) {
+	strip := make(map[string]bool, len(names))
+	for _, name := range names {
+		strip[name] = true
+	}
+
+	info := commitInfo(t, db, version)
+	storeInfos := info.StoreInfos[:0]
+	for _, storeInfo := range info.StoreInfos {
+		if !strip[storeInfo.Name] {
+			storeInfos = append(storeInfos, storeInfo)
+		}
+	}
+	info.StoreInfos = storeInfos
+	bz, err := info.Marshal()
+	require.NoError(t, err)
+	require.NoError(t, db.Set([]byte(fmt.Sprintf("s/%d", version)), bz))
+
+	for name := range strip {
+		storePrefix := []byte("s/k:" + name + "/")
+		it, err := dbm.IteratePrefix(db, storePrefix)
+		require.NoError(t, err)
+		var keys [][]byte
+		for ; it.Valid(); it.Next() {
+			keys = append(keys, it.Key())
+		}
+		require.NoError(t, it.Close())
+		for _, key := range keys {
+			require.NoError(t, db.Delete(key))
+		}
+	}
+}
+
+// plantStore adds a store holding kvs to the commit info at version, leaving db as if it was written by a binary
+// that mounted it
+func plantStore(t *testing.T, db dbm.DB, version int64, name string, kvs map[string]string) {
+	tree, err := iavl.NewMutableTreeWithOpts(dbm.NewPrefixDB(db, []byte("s/k:"+name+"/")), 0, &iavl.Options{InitialVersion: uint64(version)}, false)
+	require.NoError(t, err)
+	for key, value := range kvs {
+		_, err := tree.Set([]byte(key), []byte(value))
+		require.NoError(t, err)
+	}
+	hash, treeVersion, err := tree.SaveVersion()
+	require.NoError(t, err)
+	require.Equal(t, version, treeVersion)
+
+	info := commitInfo(t, db, version)
+	info.StoreInfos = append(info.StoreInfos, storetypes.StoreInfo{Name: name, CommitId: storetypes.CommitID{Version: version, Hash: hash}})
+	bz, err := info.Marshal()
+	require.NoError(t, err)
+	require.NoError(t, db.Set([]byte(fmt.Sprintf("s/%d", version)), bz))
+}
+
+// haltForUpgrade runs a binary without upgrade until it halts at the plan height and returns its db and home. The
+// binary is the current app with only the other upgrades registered, without the stores added by upgrade and their
+// modules, and with the stores it deletes holding some data. prepare, if set, runs on the state of the binary
+// before the plan is scheduled.
+func haltForUpgrade(t *testing.T, upgrade upgrades.Upgrade, prepare func(sdk.Context, *OraichainApp)) (dbm.DB, string) {
+	db, home := dbm.NewMemDB(), t.TempDir()
+
+	registered := Upgrades
+	Upgrades = nil
+	for _, other := range registered {
+		if other.UpgradeName != upgrade.UpgradeName {
+			Upgrades = append(Upgrades, other)
+		}
+	}
+	oldApp := setupTestApp(t, db, home)
+	Upgrades = registered
+
+	added := append([]string{}, upgrade.StoreUpgrades.Added...)
+	removed := append([]string{}, upgrade.StoreUpgrades.Deleted...)
+	for _, rename := range upgrade.StoreUpgrades.Renamed {
+		added = append(added, rename.NewKey)
+		removed = append(removed, rename.OldKey)
+	}
+
+	// the modules of the added stores do not exist before the upgrade, so RunMigrations has to init their genesis
+	ctx := oldApp.NewUncachedContext(false, tmproto.Header{ChainID: testChainID, Height: oldApp.LastBlockHeight() + 1})
+	versionMap := prefix.NewStore(ctx.KVStore(oldApp.keys[upgradetypes.StoreKey]), []byte{upgradetypes.VersionMapByte})
+	for _, name := range added {
+		versionMap.Delete([]byte(name))
+	}
+	if prepare != nil {
+		prepare(ctx, oldApp)
+	}
+
+	plan := upgradetypes.Plan{Name: upgrade.UpgradeName, Height: ctx.BlockHeight() + 2}
+	require.NoError(t, oldApp.upgradeKeeper.ScheduleUpgrade(ctx, plan))
+	for oldApp.LastBlockHeight() < plan.Height-1 {
+		nextBlock(oldApp)
+	}
+	require.Panics(t, func() { nextBlock(oldApp) }, "old binary did not halt at the upgrade height")
+
+	stripStores(t, db, oldApp.LastBlockHeight(), added)
+	for _, name := range removed {
+		if _, ok := oldApp.keys[name]; !ok {
+			plantStore(t, db, oldApp.LastBlockHeight(), name, map[string]string{"key": "value"})
+		}
+	}
+	return db, home
+}
+
+// simulateUpgrade runs upgrade the way a live chain does, fully in memory: a binary without the upgrade halts at the
+// plan height (see haltForUpgrade), the new binary is restarted on the same db and home, loading the stores through
+// the upgrade store loader, and produces the plan height again, running the upgrade handler and its migrations.
+//
+// It returns the upgraded app and its db, with the upgrade block committed.
+func simulateUpgrade(t *testing.T, upgrade upgrades.Upgrade, prepare func(sdk.Context, *OraichainApp)) (*OraichainApp, dbm.DB) {
+	db, home := haltForUpgrade(t, upgrade, prepare)
+
+	app := newTestApp(t, db, home)
+	nextBlock(app)
+	require.Equal(t, app.upgradeKeeper.GetDoneHeight(app.NewUncachedContext(false, tmproto.Header{}), upgrade.UpgradeName), app.LastBlockHeight())
+	return app, db
+}
+
+// registerUpgrade adds upgrade to the registry for the duration of the test
+func registerUpgrade(t *testing.T, upgrade upgrades.Upgrade) {
+	registered := Upgrades
+	Upgrades = append(append([]upgrades.Upgrade{}, registered...), upgrade)
+	t.Cleanup(func() { Upgrades = registered })
+}
+
+// copyDB returns an in-memory copy of db
+func copyDB(t *testing.T, db dbm.DB) dbm.DB {
+	copied := dbm.NewMemDB()
+	it, err := db.Iterator(nil, nil)
+	require.NoError(t, err)
+	defer it.Close()
+	for ; it.Valid(); it.Next() {
+		require.NoError(t, copied.Set(it.Key(), it.Value()))
+	}
+	return copied
+}
+
+// storeData returns the number of keys of the store committed by the app
+func storeData(t *testing.T, app *OraichainApp, name string) int {
+	store, ok := app.CommitMultiStore().(*rootmulti.Store).GetStoreByName(name).(storetypes.KVStore)
+	require.True(t, ok, "store %s is not mounted", name)
+	it := store.Iterator(nil, nil)
+	defer it.Close()
+
+	count := 0
+	for ; it.Valid(); it.Next() {
+		count++
+	}
+	return count
+}
+
+func TestUpgradesRegistry(t *testing.T) {
+	names := make(map[string]bool)
+	for _, upgrade := range Upgrades {
+		require.NotEmpty(t, upgrade.UpgradeName)
+		require.False(t, names[upgrade.UpgradeName], "duplicate upgrade %s", upgrade.UpgradeName)
+		names[upgrade.UpgradeName] = true
+	}
+
+	app := newTestApp(t, dbm.NewMemDB(), t.TempDir())
+	for _, upgrade := range Upgrades {
+		require.True(t, app.upgradeKeeper.HasHandler(upgrade.UpgradeName))
+	}
+}
+
+func TestUpgradeHandlers(t *testing.T) {
+	for _, upgrade := range Upgrades {
+		t.Run(upgrade.UpgradeName, func(t *testing.T) {
+			app := setupTestApp(t, dbm.NewMemDB(), t.TempDir())
+			versionMap := runUpgrade(t, app, upgrade)
+			require.Equal(t, app.mm.GetVersionMap(), versionMap)
+		})
+	}
+}
+
+func TestUpgradeV0420SetsEvmDenom(t *testing.T) {
+	app := setupTestApp(t, dbm.NewMemDB(), t.TempDir())
+	ctx := app.NewUncachedContext(false, tmproto.Header{ChainID: testChainID})
+	params := app.evmKeeper.GetParams(ctx)
+	params.EvmDenom = "stake"
+	app.evmKeeper.SetParams(ctx, params)
+
+	runUpgrade(t, app, Upgrades[0])
+	require.Equal(t, appconfig.EvmDenom, app.evmKeeper.GetParams(ctx).EvmDenom)
+}
+
+func TestSimulateUpgrades(t *testing.T) {
+	for _, upgrade := range Upgrades {
+		t.Run(upgrade.UpgradeName, func(t *testing.T) {
+			app, db := simulateUpgrade(t, upgrade, nil)
+			ctx := app.NewUncachedContext(false, tmproto.Header{ChainID: testChainID, Height: app.LastBlockHeight()})
+
+			require.Equal(t, app.mm.GetVersionMap(), app.upgradeKeeper.GetModuleVersionMap(ctx))
+
+			stores := make(map[string]int64)
+			for _, storeInfo := range commitInfo(t, db, app.LastBlockHeight()).StoreInfos {
+				stores[storeInfo.Name] = storeInfo.CommitId.Version
+			}
+			for _, name := range upgrade.StoreUpgrades.Added {
+				require.Equal(t, app.LastBlockHeight(), stores[name], "store %s not committed", name)
+			}
+
+			// the upgraded state has to load without the upgrade store loader
+			restarted := newTestApp(t, db, t.TempDir())
+			require.Equal(t, app.LastBlockHeight(), restarted.LastBlockHeight())
+			nextBlock(restarted)
+		})
+	}
+}
+
+func TestSimulateUpgradeV0420(t *testing.T) {
+	app, _ := simulateUpgrade(t, Upgrades[0], nil)
+	ctx := app.NewUncachedContext(false, tmproto.Header{ChainID: testChainID, Height: app.LastBlockHeight()})
+
+	require.Equal(t, appconfig.EvmDenom, app.evmKeeper.GetParams(ctx).EvmDenom)
+	// the base fee itself already moved in the begin blocker of the upgrade block
+	feemarketParams, defaultFeemarketParams := app.feeMarketKeeper.GetParams(ctx), feemarkettypes.DefaultParams()
+	feemarketParams.BaseFee = defaultFeemarketParams.BaseFee
+	require.Equal(t, defaultFeemarketParams, feemarketParams)
+}
+
+// removedModuleUpgrade deletes the store of a module that is no longer part of the app, moving the funds of its module
+// account to the community pool when migrate is set
+func removedModuleUpgrade(migrate bool) upgrades.Upgrade {
+	return upgrades.Upgrade{
+		UpgradeName: "remove-legacy",
+		CreateUpgradeHandler: func(mm *module.Manager, configurator module.Configurator, keepers *upgrades.AppKeepers) upgradetypes.UpgradeHandler {
+			return func(ctx sdk.Context, plan upgradetypes.Plan, fromVM module.VersionMap) (module.VersionMap, error) {
+				addr := authtypes.NewModuleAddress("legacy")
+				if balances := keepers.BankKeeper.GetAllBalances(ctx, addr); migrate && !balances.IsZero() {
+					if err := keepers.DistrKeeper.FundCommunityPool(ctx, balances, addr); err != nil {
+						return nil, err
+					}
+				}
+				return mm.RunMigrations(ctx, configurator, fromVM)
+			}
+		},
+		StoreUpgrades: storetypes.StoreUpgrades{Deleted: []string{"legacy"}},
+	}
+}
+
+// fundLegacyModule sends funds to the module account of the removed module
+func fundLegacyModule(ctx sdk.Context, app *OraichainApp) {
+	coins := sdk.NewCoins(sdk.NewInt64Coin(appconfig.CosmosDenom, 1000))
+	if err := app.bankKeeper.MintCoins(ctx, minttypes.ModuleName, coins); err != nil {
+		panic(err)
+	}
+	if err := app.bankKeeper.SendCoinsFromModuleToAccount(ctx, minttypes.ModuleName, authtypes.NewModuleAddress("legacy"), coins); err != nil {
+		panic(err)
+	}
+	app.upgradeKeeper.SetModuleVersionMap(ctx, module.VersionMap{"legacy": 1})
+}
+
+func TestSimulateUpgradeDeletedStore(t *testing.T) {
+	upgrade := removedModuleUpgrade(true)
+	registerUpgrade(t, upgrade)
+
+	app, db := simulateUpgrade(t, upgrade, fundLegacyModule)
+	ctx := app.NewUncachedContext(false, tmproto.Header{ChainID: testChainID, Height: app.LastBlockHeight()})
+
+	// the data of the store is deleted in the commit of the upgrade block
+	require.Zero(t, storeData(t, app, "legacy"))
+	stores := make(map[string]int64)
+	for _, storeInfo := range commitInfo(t, db, app.LastBlockHeight()).StoreInfos {
+		stores[storeInfo.Name] = storeInfo.CommitId.Version
+	}
+	require.Equal(t, app.LastBlockHeight(), stores["legacy"])
+	require.True(t, app.bankKeeper.GetAllBalances(ctx, authtypes.NewModuleAddress("legacy")).IsZero())
+	require.NotContains(t, app.upgradeKeeper.GetModuleVersionMap(ctx), "legacy")
+
+	// a node restarted after the upgrade commits the same state as the node that ran it
+	restarted := newTestApp(t, copyDB(t, db), t.TempDir())
+	require.Zero(t, storeData(t, restarted, "legacy"))
+	nextBlock(app)
+	nextBlock(restarted)
+	require.Equal(t, app.LastCommitID(), restarted.LastCommitID())
+}
+
+func TestSimulateUpgradeDeletedStoreUnmigratedFunds(t *testing.T) {
+	upgrade := removedModuleUpgrade(false)
+	registerUpgrade(t, upgrade)
+
+	db, home := haltForUpgrade(t, upgrade, fundLegacyModule)
+	app := newTestApp(t, db, home)
+	require.PanicsWithError(t, fmt.Sprintf("module account %s of removed module legacy still holds 1000%s", authtypes.NewModuleAddress("legacy"), appconfig.CosmosDenom), func() { nextBlock(app) })
+}
+
+func TestValidateStoreUpgrades(t *testing.T) {
+	app := newTestApp(t, dbm.NewMemDB(), t.TempDir())
+
+	require.NoError(t, app.validateStoreUpgrades(&storetypes.StoreUpgrades{
+		Added:   []string{evmtypes.StoreKey},
+		Renamed: []storetypes.StoreRename{{OldKey: "legacy", NewKey: banktypes.StoreKey}},
+		Deleted: []string{"legacy"},
+	}))
+	require.Error(t, app.validateStoreUpgrades(&storetypes.StoreUpgrades{Added: []string{"legacy"}}))
+	require.Error(t, app.validateStoreUpgrades(&storetypes.StoreUpgrades{Deleted: []string{banktypes.StoreKey}}))
+	require.Error(t, app.validateStoreUpgrades(&storetypes.StoreUpgrades{
+		Renamed: []storetypes.StoreRename{{OldKey: banktypes.StoreKey, NewKey: evmtypes.StoreKey}},
+	}))
+	require.Error(t, app.validateStoreUpgrades(&storetypes.StoreUpgrades{
+		Renamed: []storetypes.StoreRename{{OldKey: "legacy", NewKey: "other"}},
+	}))
+}
+
+func TestUpgradeV0430MigratesInterTxChannels(t *testing.T) {
+	app := setupTestApp(t, dbm.NewMemDB(), t.TempDir())
+	ctx := app.NewUncachedContext(false, tmproto.Header{ChainID: testChainID})
+
+	portID, err := icatypes.NewControllerPortID(sdk.AccAddress("owner").String())
+	require.NoError(t, err)
+	channelID := "channel-0"
+	app.ibcKeeper.ChannelKeeper.SetChannel(ctx, portID, channelID, channeltypes.NewChannel(
+		channeltypes.OPEN, channeltypes.ORDERED, channeltypes.NewCounterparty(icatypes.PortID, "channel-1"), []string{"connection-0"}, icatypes.Version,
+	))
+	name := host.ChannelCapabilityPath(portID, channelID)
+	capability, err := app.scopedIBCKeeper.NewCapability(ctx, name)
+	require.NoError(t, err)
+	require.NoError(t, app.scopedInterTxKeeper.ClaimCapability(ctx, capability, name))
+
+	runUpgrade(t, app, v0430.Upgrade)
+
+	_, found := app.scopedInterTxKeeper.GetCapability(ctx, name)
+	require.False(t, found)
+	migrated, found := app.scopedICAAuthKeeper.GetCapability(ctx, name)
+	require.True(t, found)
+	require.Equal(t, capability, migrated)
+
+	module, _, err := app.ibcKeeper.ChannelKeeper.LookupModuleByChannel(ctx, portID, channelID)
+	require.NoError(t, err)
+	require.Equal(t, icaauthtypes.ModuleName, module)
+	require.True(t, app.ibcKeeper.Router.HasRoute(module))
+}
@@ -0,0 +1,146 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/cosmos/cosmos-sdk/baseapp"
+	"github.com/cosmos/cosmos-sdk/client/flags"
+	"github.com/cosmos/cosmos-sdk/server"
+	"github.com/cosmos/cosmos-sdk/store/rootmulti"
+	"github.com/oraichain/orai/app"
+	"github.com/spf13/cobra"
+	"github.com/syndtr/goleveldb/leveldb/opt"
+	"github.com/tendermint/tendermint/libs/cli"
+	"github.com/tendermint/tendermint/types"
+	dbm "github.com/tendermint/tm-db"
+)
+
+const flagHeight = "height"
+
+// ReplayTxCmd returns a command replaying a tx dumped by the ante handler against the latest state of the node
+func ReplayTxCmd(ac appCreator) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "replay-tx [tx-file]",
+		Short: "Replay through the ante handler a tx dumped on panic, against the latest state of the node",
+		Long: `Replay through the ante handler a tx dumped in the ante.panic-dump-dir directory, against the latest
+state of the node. The node must be stopped, nothing is written to its state.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			serverCtx := server.GetServerContextFromCmd(cmd)
+			config := serverCtx.Config
+
+			txBytes, err := os.ReadFile(args[0])
+			if err != nil {
+				return err
+			}
+
+			chainID, _ := cmd.Flags().GetString(flags.FlagChainID)
+			if chainID == "" {
+				genDoc, err := types.GenesisDocFromFile(config.GenesisFile())
+				if err != nil {
+					return err
+				}
+				chainID = genDoc.ChainID
+			}
+
+			db, err := openApplicationDB(config)
+			if err != nil {
+				return err
+			}
+			defer db.Close()
+
+			oraichainApp := app.NewOraichainApp(
+				serverCtx.Logger, db, nil, true, map[int64]bool{}, config.RootDir, 0,
+				ac.encCfg, app.GetEnabledProposals(), serverCtx.Viper, nil, app.DefaultEvmOptions,
+			)
+
+			gasUsed, err := oraichainApp.ReplayAnteHandler(chainID, txBytes)
+			if err != nil {
+				return fmt.Errorf("ante handler failed at height %d after %d gas: %w", oraichainApp.LastBlockHeight(), gasUsed, err)
+			}
+			cmd.Printf("ante handler passed at height %d using %d gas\n", oraichainApp.LastBlockHeight(), gasUsed)
+			return nil
+		},
+	}
+
+	cmd.Flags().String(flags.FlagChainID, "", "The network chain ID, read from the genesis file by default")
+	return cmd
+}
+
+// CheckStateCmd returns a command running the invariants against the state of the node at a given height
+func CheckStateCmd(ac appCreator) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "check-state",
+		Short: "Run all the invariants against the state of the node and report the broken ones",
+		Long: `Run the invariants registered in the crisis module, along with checks of the evmutil conversion balances,
+the wasm contract accounts and the IBC vouchers held by the escrows, against the state of the node at the given height, the latest
+one by default. Broken invariants are reported instead of halting the node. The node must be stopped, its database is
+opened read-only with the goleveldb backend.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			serverCtx := server.GetServerContextFromCmd(cmd)
+			config := serverCtx.Config
+
+			height, _ := cmd.Flags().GetInt64(flagHeight)
+			output, _ := cmd.Flags().GetString(cli.OutputFlag)
+			if output != "text" && output != "json" {
+				return fmt.Errorf("unsupported output %s, expected text or json", output)
+			}
+
+			var db dbm.DB
+			var err error
+			// only goleveldb can be opened read-only
+			if dbm.BackendType(config.DBBackend) == dbm.GoLevelDBBackend {
+				db, err = dbm.NewGoLevelDBWithOpts("application", config.DBDir(), &opt.Options{ReadOnly: true})
+			} else {
+				db, err = openApplicationDB(config)
+			}
+			if err != nil {
+				return err
+			}
+			defer db.Close()
+
+			oraichainApp := app.NewOraichainApp(
+				serverCtx.Logger, db, nil, false, map[int64]bool{}, config.RootDir, 0,
+				ac.encCfg, app.GetEnabledProposals(), serverCtx.Viper, nil, app.DefaultEvmOptions,
+				baseapp.SetIAVLDisableFastNode(true),
+			)
+			if height == 0 {
+				height = rootmulti.GetLatestVersion(db)
+			}
+			if err := oraichainApp.LoadHeight(height); err != nil {
+				return err
+			}
+
+			report := oraichainApp.CheckState()
+			if output == "json" {
+				bz, err := json.MarshalIndent(report, "", "  ")
+				if err != nil {
+					return err
+				}
+				cmd.Println(string(bz))
+			} else {
+				cmd.Printf("checked %d invariants at height %d\n", len(report.Invariants), report.Height)
+				for _, result := range report.Invariants {
+					if result.Broken {
+						cmd.Printf("BROKEN %s/%s\n%s\n", result.Module, result.Route, strings.TrimSpace(result.Message))
+					} else {
+						cmd.Printf("ok     %s/%s\n", result.Module, result.Route)
+					}
+				}
+			}
+
+			if broken := report.Broken(); broken > 0 {
+				return fmt.Errorf("%d of %d invariants broken at height %d", broken, len(report.Invariants), report.Height)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().Int64(flagHeight, 0, "Height of the state to check, the latest one by default")
+	cmd.Flags().String(cli.OutputFlag, "text", "Output format (text|json)")
+	return cmd
+}
@@ -0,0 +1,311 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/cosmos/cosmos-sdk/client/flags"
+	cryptocodec "github.com/cosmos/cosmos-sdk/crypto/codec"
+	"github.com/cosmos/cosmos-sdk/server"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/version"
+	banktypes "github.com/cosmos/cosmos-sdk/x/bank/types"
+	"github.com/oraichain/orai/app"
+	"github.com/spf13/cobra"
+	tmcfg "github.com/tendermint/tendermint/config"
+	tmos "github.com/tendermint/tendermint/libs/os"
+	"github.com/tendermint/tendermint/privval"
+	tmtypes "github.com/tendermint/tendermint/types"
+	tmtime "github.com/tendermint/tendermint/types/time"
+	dbm "github.com/tendermint/tm-db"
+)
+
+const (
+	flagExportModules              = "modules"
+	flagExportWasmIncludeCodes     = "wasm-include-codes"
+	flagExportWasmExcludeCodes     = "wasm-exclude-codes"
+	flagExportWasmIncludeContracts = "wasm-include-contracts"
+	flagExportWasmExcludeContracts = "wasm-exclude-contracts"
+	flagExportDryRun               = "dry-run"
+
+	flagTestnetValidatorKeys = "validator-keys"
+	flagTestnetValidators    = "validators"
+	flagTestnetFund          = "fund"
+	flagTestnetVotingPeriod  = "voting-period"
+	flagTestnetUnbonding     = "unbonding-time"
+)
+
+// ExportStreamCmd returns a command exporting the state to a genesis file module by module
+func ExportStreamCmd(ac appCreator, defaultNodeHome string) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "export-stream [genesis-file]",
+		Short: "Export state to a genesis file, writing it module by module",
+		Long: `Export state to a genesis file like export, writing it module by module and the wasm codes and contracts
+one by one instead of holding the whole state in memory.
+
+The wasm codes and contracts can be filtered, the contracts of the codes left out being left out too as they could not
+be imported without them. For debugging, the export can be restricted to some modules with --modules, the genesis file
+then being incomplete.
+
+Zero height exports write a report of the validators jailed for not being in --jail-allowed-addrs and of the rewards
+withdrawn next to the genesis file. With --dry-run, the report is printed without exporting the state.`,
+		Example: fmt.Sprintf(`$ %[1]s export-stream genesis.json --for-zero-height
+$ %[1]s export-stream --for-zero-height --jail-allowed-addrs oraivaloper1...,oraivaloper1... --dry-run
+$ %[1]s export-stream wasm.json --modules wasm --wasm-include-codes 1,2`, version.AppName),
+		Args: cobra.RangeArgs(0, 1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			serverCtx := server.GetServerContextFromCmd(cmd)
+			config := serverCtx.Config
+
+			homeDir, _ := cmd.Flags().GetString(flags.FlagHome)
+			config.SetRoot(homeDir)
+
+			height, _ := cmd.Flags().GetInt64(server.FlagHeight)
+			opts := app.GenesisExportOptions{}
+			opts.ForZeroHeight, _ = cmd.Flags().GetBool(server.FlagForZeroHeight)
+			opts.JailAllowedAddrs, _ = cmd.Flags().GetStringSlice(server.FlagJailAllowedAddrs)
+			opts.Modules, _ = cmd.Flags().GetStringSlice(flagExportModules)
+			includeCodes, _ := cmd.Flags().GetUintSlice(flagExportWasmIncludeCodes)
+			excludeCodes, _ := cmd.Flags().GetUintSlice(flagExportWasmExcludeCodes)
+			opts.Wasm.IncludeCodes, opts.Wasm.ExcludeCodes = toUint64s(includeCodes), toUint64s(excludeCodes)
+			opts.Wasm.IncludeContracts, _ = cmd.Flags().GetStringSlice(flagExportWasmIncludeContracts)
+			opts.Wasm.ExcludeContracts, _ = cmd.Flags().GetStringSlice(flagExportWasmExcludeContracts)
+			if err := opts.Wasm.Validate(); err != nil {
+				return err
+			}
+
+			if dryRun, _ := cmd.Flags().GetBool(flagExportDryRun); dryRun {
+				if !opts.ForZeroHeight {
+					return fmt.Errorf("--%s requires --%s", flagExportDryRun, server.FlagForZeroHeight)
+				}
+				return printZeroHeightReport(cmd, ac, height, opts.JailAllowedAddrs)
+			}
+			if len(args) == 0 {
+				return fmt.Errorf("genesis-file is required unless --%s", flagExportDryRun)
+			}
+
+			genDoc, err := tmtypes.GenesisDocFromFile(config.GenesisFile())
+			if err != nil {
+				return err
+			}
+			return exportGenesis(cmd, ac, height, genDoc, opts, args[0])
+		},
+	}
+
+	cmd.Flags().String(flags.FlagHome, defaultNodeHome, "The application home directory")
+	cmd.Flags().Int64(server.FlagHeight, -1, "Export state from a particular height (-1 means latest height)")
+	cmd.Flags().Bool(server.FlagForZeroHeight, false, "Export state to start at height zero (perform preproccessing)")
+	cmd.Flags().StringSlice(server.FlagJailAllowedAddrs, []string{}, "Comma-separated list of operator addresses of jailed validators to unjail")
+	cmd.Flags().StringSlice(flagExportModules, nil, "Comma-separated list of the modules to export, all of them by default")
+	cmd.Flags().UintSlice(flagExportWasmIncludeCodes, nil, "Comma-separated list of the wasm code ids to export, all of them by default")
+	cmd.Flags().UintSlice(flagExportWasmExcludeCodes, nil, "Comma-separated list of the wasm code ids not to export")
+	cmd.Flags().StringSlice(flagExportWasmIncludeContracts, nil, "Comma-separated list of the wasm contract addresses to export, all of them by default")
+	cmd.Flags().StringSlice(flagExportWasmExcludeContracts, nil, "Comma-separated list of the wasm contract addresses not to export")
+	cmd.Flags().Bool(flagExportDryRun, false, "Print the report of a zero height export without exporting the state")
+
+	return cmd
+}
+
+func toUint64s(values []uint) []uint64 {
+	converted := make([]uint64, len(values))
+	for i, value := range values {
+		converted[i] = uint64(value)
+	}
+	return converted
+}
+
+// ExportTestnetCmd returns a command exporting the state to the genesis file of a local testnet
+func ExportTestnetCmd(ac appCreator, defaultNodeHome string) *cobra.Command {
+	localProfile := app.GenesisProfiles[app.LocalProfile]
+
+	cmd := &cobra.Command{
+		Use:   "export-testnet [genesis-file] [chain-id]",
+		Short: "Export state to the genesis file of a local testnet",
+		Long: `Export state to the genesis file of a local testnet starting now under chain-id, like export-stream.
+
+The consensus keys of the priv_validator_key.json files given with --validator-keys, the one of the node by default,
+replace the keys of the first bonded validators by power or of the validators given with --validators. All the other
+validators are jailed so that the local keys hold all the voting power. The operators of the validators are unchanged.
+The accounts given with --fund receive newly minted coins, and the voting and unbonding periods are shortened.`,
+		Example: fmt.Sprintf(`$ %[1]s export-testnet genesis.json local-testnet --validator-keys node0/config/priv_validator_key.json --fund orai1...=1000000000orai`, version.AppName),
+		Args:    cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			serverCtx := server.GetServerContextFromCmd(cmd)
+			config := serverCtx.Config
+
+			homeDir, _ := cmd.Flags().GetString(flags.FlagHome)
+			config.SetRoot(homeDir)
+
+			cfg := app.TestnetConfig{}
+			height, _ := cmd.Flags().GetInt64(server.FlagHeight)
+			cfg.VotingPeriod, _ = cmd.Flags().GetDuration(flagTestnetVotingPeriod)
+			cfg.UnbondingTime, _ = cmd.Flags().GetDuration(flagTestnetUnbonding)
+
+			keyFiles, _ := cmd.Flags().GetStringSlice(flagTestnetValidatorKeys)
+			if len(keyFiles) == 0 {
+				keyFiles = []string{config.PrivValidatorKeyFile()}
+			}
+			for _, keyFile := range keyFiles {
+				if !tmos.FileExists(keyFile) {
+					return fmt.Errorf("validator key file %s not found", keyFile)
+				}
+				pubKey, err := cryptocodec.FromTmPubKeyInterface(privval.LoadFilePVEmptyState(keyFile, "").Key.PubKey)
+				if err != nil {
+					return err
+				}
+				cfg.ConsensusKeys = append(cfg.ConsensusKeys, pubKey)
+			}
+
+			validators, _ := cmd.Flags().GetStringSlice(flagTestnetValidators)
+			for _, validator := range validators {
+				operator, err := sdk.ValAddressFromBech32(validator)
+				if err != nil {
+					return err
+				}
+				cfg.Validators = append(cfg.Validators, operator)
+			}
+
+			funds, err := testnetFundsFromFlags(cmd)
+			if err != nil {
+				return err
+			}
+			cfg.Funds = funds
+
+			genDoc, err := tmtypes.GenesisDocFromFile(config.GenesisFile())
+			if err != nil {
+				return err
+			}
+			genDoc.ChainID = args[1]
+			genDoc.GenesisTime = tmtime.Now()
+			if err := genDoc.ValidateAndComplete(); err != nil {
+				return err
+			}
+
+			return exportGenesis(cmd, ac, height, genDoc, app.GenesisExportOptions{Testnet: &cfg}, args[0])
+		},
+	}
+
+	cmd.Flags().String(flags.FlagHome, defaultNodeHome, "The application home directory")
+	cmd.Flags().Int64(server.FlagHeight, -1, "Export state from a particular height (-1 means latest height)")
+	cmd.Flags().StringSlice(flagTestnetValidatorKeys, nil, "Comma-separated list of the priv_validator_key.json files of the testnet validators, the one of the node by default")
+	cmd.Flags().StringSlice(flagTestnetValidators, nil, "Comma-separated list of the operator addresses of the validators taking the keys, the first ones by power by default")
+	cmd.Flags().StringArray(flagTestnetFund, nil, "Coins minted to an account, as address=coins, can be repeated")
+	cmd.Flags().Duration(flagTestnetVotingPeriod, time.Duration(localProfile.Gov.VotingPeriod), "Voting period of the testnet")
+	cmd.Flags().Duration(flagTestnetUnbonding, time.Duration(localProfile.Staking.UnbondingTime), "Unbonding time of the testnet")
+
+	return cmd
+}
+
+// testnetFundsFromFlags parses the coins minted to the accounts of a testnet given as address=coins
+func testnetFundsFromFlags(cmd *cobra.Command) ([]banktypes.Balance, error) {
+	var balances []banktypes.Balance
+	funds, _ := cmd.Flags().GetStringArray(flagTestnetFund)
+	for _, fund := range funds {
+		addr, amount, found := strings.Cut(fund, "=")
+		if !found {
+			return nil, fmt.Errorf("invalid fund %s, expected address=coins", fund)
+		}
+		if _, err := sdk.AccAddressFromBech32(addr); err != nil {
+			return nil, err
+		}
+		coins, err := sdk.ParseCoinsNormalized(amount)
+		if err != nil {
+			return nil, err
+		}
+		balances = append(balances, banktypes.Balance{Address: addr, Coins: coins})
+	}
+	return balances, nil
+}
+
+// exportGenesis writes to output the genesis document genDoc updated with the state of the node at height, the export
+// being written next to output and only moved there once complete. The report of zero height exports is written next
+// to output too.
+func exportGenesis(cmd *cobra.Command, ac appCreator, height int64, genDoc *tmtypes.GenesisDoc, opts app.GenesisExportOptions, output string) error {
+	oraichainApp, db, err := loadExportedApp(cmd, ac, height)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	tmp, err := os.CreateTemp(filepath.Dir(output), filepath.Base(output)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	report, err := oraichainApp.ExportGenesisStream(tmp, genDoc, opts)
+	if err != nil {
+		tmp.Close()
+		return fmt.Errorf("error exporting state: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp.Name(), output); err != nil {
+		return err
+	}
+	cmd.Printf("exported state at height %d to %s\n", oraichainApp.LastBlockHeight(), output)
+
+	if report != nil {
+		bz, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			return err
+		}
+		reportFile := zeroHeightReportFile(output)
+		if err := os.WriteFile(reportFile, bz, 0o644); err != nil {
+			return err
+		}
+		cmd.Printf("wrote the zero height report to %s\n", reportFile)
+	}
+	return nil
+}
+
+// printZeroHeightReport prints the report of a zero height export of the state of the node at height
+func printZeroHeightReport(cmd *cobra.Command, ac appCreator, height int64, jailAllowedAddrs []string) error {
+	oraichainApp, db, err := loadExportedApp(cmd, ac, height)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	report, err := oraichainApp.ZeroHeightReport(jailAllowedAddrs)
+	if err != nil {
+		return err
+	}
+	bz, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return err
+	}
+	cmd.Println(string(bz))
+	return nil
+}
+
+// loadExportedApp loads the app of the node at height, the returned database being closed by the caller
+func loadExportedApp(cmd *cobra.Command, ac appCreator, height int64) (*app.OraichainApp, dbm.DB, error) {
+	serverCtx := server.GetServerContextFromCmd(cmd)
+
+	db, err := openApplicationDB(serverCtx.Config)
+	if err != nil {
+		return nil, nil, err
+	}
+	oraichainApp, err := ac.loadOraichainApp(serverCtx.Logger, db, nil, height, serverCtx.Viper)
+	if err != nil {
+		db.Close()
+		return nil, nil, err
+	}
+	return oraichainApp, db, nil
+}
+
+// zeroHeightReportFile returns the file of the report of the zero height export written to output
+func zeroHeightReportFile(output string) string {
+	return strings.TrimSuffix(output, filepath.Ext(output)) + ".report.json"
+}
+
+// openApplicationDB opens the database of the app of the node with the backend of its config, as start does
+func openApplicationDB(config *tmcfg.Config) (dbm.DB, error) {
+	return dbm.NewDB("application", dbm.BackendType(config.DBBackend), config.DBDir())
+}
@@ -5,11 +5,13 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"os"
 
 	"github.com/spf13/cobra"
 
 	"github.com/cosmos/cosmos-sdk/client"
 	"github.com/cosmos/cosmos-sdk/client/flags"
+	"github.com/cosmos/cosmos-sdk/codec"
 	"github.com/cosmos/cosmos-sdk/crypto/keyring"
 	"github.com/cosmos/cosmos-sdk/server"
 	sdk "github.com/cosmos/cosmos-sdk/types"
@@ -25,6 +27,8 @@ const (
 	flagVestingStart = "vesting-start-time"
 	flagVestingEnd   = "vesting-end-time"
 	flagVestingAmt   = "vesting-amount"
+	flagVestingFile  = "vesting-periods-file"
+	flagVestingLock  = "vesting-locked"
 )
 
 // AddGenesisAccountCmd returns add-genesis-account cobra Command.
@@ -35,7 +39,14 @@ func AddGenesisAccountCmd(defaultNodeHome string) *cobra.Command {
 		Long: `Add a genesis account to genesis.json. The provided account must specify
 the account address or key name and a list of initial coins. If a key name is given,
 the address will be looked up in the local Keybase. The list of initial tokens must
-contain valid denominations. Accounts may optionally be supplied with vesting parameters.
+contain valid denominations. Accounts may optionally be supplied with vesting parameters:
+
+- a continuous vesting account with --vesting-amount, --vesting-start-time and --vesting-end-time
+- a delayed vesting account with --vesting-amount and --vesting-end-time
+- a periodic vesting account with --vesting-periods-file, a json file of the start time and periods
+  of the schedule, the vesting amount defaulting to the sum of the periods:
+  {"start_time": 1700000000, "periods": [{"coins": "1000orai", "length_seconds": 2592000}]}
+- a permanently locked account with --vesting-amount and --vesting-locked
 `,
 		Args: cobra.ExactArgs(2),
 		RunE: func(cmd *cobra.Command, args []string) error {
@@ -82,54 +93,14 @@ contain valid denominations. Accounts may optionally be supplied with vesting pa
 				return fmt.Errorf("failed to parse coins: %w", err)
 			}
 
-			vestingStart, err := cmd.Flags().GetInt64(flagVestingStart)
-			if err != nil {
-				return err
-			}
-			vestingEnd, err := cmd.Flags().GetInt64(flagVestingEnd)
-			if err != nil {
-				return err
-			}
-			vestingAmtStr, err := cmd.Flags().GetString(flagVestingAmt)
+			vesting, err := genesisVestingFromFlags(cmd)
 			if err != nil {
 				return err
 			}
 
-			vestingAmt, err := sdk.ParseCoinsNormalized(vestingAmtStr)
+			genAccount, balances, err := newGenesisAccount(addr, coins, vesting)
 			if err != nil {
-				return fmt.Errorf("failed to parse vesting amount: %w", err)
-			}
-
-			// create concrete account type based on input parameters
-			var genAccount authtypes.GenesisAccount
-
-			balances := banktypes.Balance{Address: addr.String(), Coins: coins.Sort()}
-			baseAccount := authtypes.NewBaseAccount(addr, nil, 0, 0)
-
-			if !vestingAmt.IsZero() {
-				baseVestingAccount := authvesting.NewBaseVestingAccount(baseAccount, vestingAmt.Sort(), vestingEnd)
-
-				if (balances.Coins.IsZero() && !baseVestingAccount.OriginalVesting.IsZero()) ||
-					baseVestingAccount.OriginalVesting.IsAnyGT(balances.Coins) {
-					return errors.New("vesting amount cannot be greater than total amount")
-				}
-
-				switch {
-				case vestingStart != 0 && vestingEnd != 0:
-					genAccount = authvesting.NewContinuousVestingAccountRaw(baseVestingAccount, vestingStart)
-
-				case vestingEnd != 0:
-					genAccount = authvesting.NewDelayedVestingAccountRaw(baseVestingAccount)
-
-				default:
-					return errors.New("invalid vesting parameters; must supply start and end time or end time")
-				}
-			} else {
-				genAccount = baseAccount
-			}
-
-			if err := genAccount.Validate(); err != nil {
-				return fmt.Errorf("failed to validate new genesis account: %w", err)
+				return err
 			}
 
 			genFile := config.GenesisFile()
@@ -149,39 +120,9 @@ contain valid denominations. Accounts may optionally be supplied with vesting pa
 				return fmt.Errorf("cannot add account at existing address %s", addr)
 			}
 
-			// Add the new account to the set of genesis accounts and sanitize the
-			// accounts afterwards.
-			accs = append(accs, genAccount)
-			accs = authtypes.SanitizeGenesisAccounts(accs)
-
-			genAccs, err := authtypes.PackAccounts(accs)
-			if err != nil {
-				return fmt.Errorf("failed to convert accounts into any's: %w", err)
-			}
-			authGenState.Accounts = genAccs
-
-			authGenStateBz, err := cdc.MarshalJSON(&authGenState)
+			appStateJSON, err := addGenesisAccounts(cdc, appState, []authtypes.GenesisAccount{genAccount}, []banktypes.Balance{balances})
 			if err != nil {
-				return fmt.Errorf("failed to marshal auth genesis state: %w", err)
-			}
-
-			appState[authtypes.ModuleName] = authGenStateBz
-
-			bankGenState := banktypes.GetGenesisStateFromAppState(cdc, appState)
-			bankGenState.Balances = append(bankGenState.Balances, balances)
-			bankGenState.Balances = banktypes.SanitizeGenesisBalances(bankGenState.Balances)
-			bankGenState.Supply = bankGenState.Supply.Add(balances.Coins...)
-
-			bankGenStateBz, err := cdc.MarshalJSON(bankGenState)
-			if err != nil {
-				return fmt.Errorf("failed to marshal bank genesis state: %w", err)
-			}
-
-			appState[banktypes.ModuleName] = bankGenStateBz
-
-			appStateJSON, err := json.Marshal(appState)
-			if err != nil {
-				return fmt.Errorf("failed to marshal application genesis state: %w", err)
+				return err
 			}
 
 			genDoc.AppState = appStateJSON
@@ -194,7 +135,215 @@ contain valid denominations. Accounts may optionally be supplied with vesting pa
 	cmd.Flags().String(flagVestingAmt, "", "amount of coins for vesting accounts")
 	cmd.Flags().Int64(flagVestingStart, 0, "schedule start time (unix epoch) for vesting accounts")
 	cmd.Flags().Int64(flagVestingEnd, 0, "schedule end time (unix epoch) for vesting accounts")
+	cmd.Flags().String(flagVestingFile, "", "json file of the start time and periods of periodic vesting accounts")
+	cmd.Flags().Bool(flagVestingLock, false, "lock the vesting amount permanently")
 	flags.AddQueryFlagsToCmd(cmd)
 
 	return cmd
 }
+
+// genesisVesting is the vesting schedule of a genesis account, none when Amount is zero and no Periods are given
+type genesisVesting struct {
+	Amount sdk.Coins
+	Start  int64
+	End    int64
+	// Periods is the schedule of a periodic vesting account, starting at Start
+	Periods authvesting.Periods
+	// Locked is set for a permanently locked account
+	Locked bool
+}
+
+// vestingPeriodsJSON is the json format of the schedule of a periodic vesting account
+type vestingPeriodsJSON struct {
+	StartTime int64 `json:"start_time"`
+	Periods   []struct {
+		Coins  string `json:"coins"`
+		Length int64  `json:"length_seconds"`
+	} `json:"periods"`
+}
+
+// parseVestingPeriods parses the start time and periods of a periodic vesting account from json
+func parseVestingPeriods(bz []byte) (int64, authvesting.Periods, error) {
+	var schedule vestingPeriodsJSON
+	if err := json.Unmarshal(bz, &schedule); err != nil {
+		return 0, nil, fmt.Errorf("failed to parse vesting periods: %w", err)
+	}
+	if len(schedule.Periods) == 0 {
+		return 0, nil, errors.New("no vesting periods")
+	}
+
+	periods := make(authvesting.Periods, len(schedule.Periods))
+	for i, period := range schedule.Periods {
+		if period.Length <= 0 {
+			return 0, nil, fmt.Errorf("vesting period %d must have a positive length", i)
+		}
+		amount, err := sdk.ParseCoinsNormalized(period.Coins)
+		if err != nil {
+			return 0, nil, fmt.Errorf("failed to parse coins of vesting period %d: %w", i, err)
+		}
+		if amount.IsZero() {
+			return 0, nil, fmt.Errorf("vesting period %d must have a positive amount", i)
+		}
+		periods[i] = authvesting.Period{Length: period.Length, Amount: amount}
+	}
+	return schedule.StartTime, periods, nil
+}
+
+// genesisVestingFromFlags reads the vesting schedule given by the flags of add-genesis-account
+func genesisVestingFromFlags(cmd *cobra.Command) (genesisVesting, error) {
+	var vesting genesisVesting
+
+	vestingStart, err := cmd.Flags().GetInt64(flagVestingStart)
+	if err != nil {
+		return vesting, err
+	}
+	vestingEnd, err := cmd.Flags().GetInt64(flagVestingEnd)
+	if err != nil {
+		return vesting, err
+	}
+	vestingAmtStr, err := cmd.Flags().GetString(flagVestingAmt)
+	if err != nil {
+		return vesting, err
+	}
+	vestingFile, err := cmd.Flags().GetString(flagVestingFile)
+	if err != nil {
+		return vesting, err
+	}
+	vesting.Locked, err = cmd.Flags().GetBool(flagVestingLock)
+	if err != nil {
+		return vesting, err
+	}
+
+	vesting.Amount, err = sdk.ParseCoinsNormalized(vestingAmtStr)
+	if err != nil {
+		return vesting, fmt.Errorf("failed to parse vesting amount: %w", err)
+	}
+
+	if vestingFile != "" {
+		if vestingStart != 0 || vestingEnd != 0 {
+			return vesting, fmt.Errorf("--%s cannot be combined with vesting times, the start time is read from the file", flagVestingFile)
+		}
+		bz, err := os.ReadFile(vestingFile)
+		if err != nil {
+			return vesting, err
+		}
+		if vestingStart, vesting.Periods, err = parseVestingPeriods(bz); err != nil {
+			return vesting, err
+		}
+	}
+
+	vesting.Start, vesting.End = vestingStart, vestingEnd
+	return vesting, nil
+}
+
+// newGenesisAccount creates the account and balance of addr, a vesting account when a vesting schedule is given
+func newGenesisAccount(addr sdk.AccAddress, coins sdk.Coins, vesting genesisVesting) (authtypes.GenesisAccount, banktypes.Balance, error) {
+	// create concrete account type based on input parameters
+	var genAccount authtypes.GenesisAccount
+
+	balances := banktypes.Balance{Address: addr.String(), Coins: coins.Sort()}
+	baseAccount := authtypes.NewBaseAccount(addr, nil, 0, 0)
+
+	vestingAmt := vesting.Amount.Sort()
+	var periodsAmt sdk.Coins
+	for _, period := range vesting.Periods {
+		periodsAmt = periodsAmt.Add(period.Amount...)
+	}
+	if vesting.Periods != nil && vestingAmt.IsZero() {
+		vestingAmt = periodsAmt
+	}
+
+	if !vestingAmt.IsZero() {
+		baseVestingAccount := authvesting.NewBaseVestingAccount(baseAccount, vestingAmt, vesting.End)
+
+		if (balances.Coins.IsZero() && !baseVestingAccount.OriginalVesting.IsZero()) ||
+			baseVestingAccount.OriginalVesting.IsAnyGT(balances.Coins) {
+			return nil, balances, errors.New("vesting amount cannot be greater than total amount")
+		}
+
+		switch {
+		case vesting.Locked:
+			if vesting.Start != 0 || vesting.End != 0 || vesting.Periods != nil {
+				return nil, balances, errors.New("invalid vesting parameters; permanently locked accounts take no vesting time or periods")
+			}
+			genAccount = authvesting.NewPermanentLockedAccount(baseAccount, vestingAmt)
+
+		case vesting.Periods != nil:
+			if !periodsAmt.IsEqual(vestingAmt) {
+				return nil, balances, fmt.Errorf("vesting periods sum to %s instead of the vesting amount %s", periodsAmt, vestingAmt)
+			}
+			genAccount = authvesting.NewPeriodicVestingAccount(baseAccount, vestingAmt, vesting.Start, vesting.Periods)
+
+		case vesting.Start != 0 && vesting.End != 0:
+			genAccount = authvesting.NewContinuousVestingAccountRaw(baseVestingAccount, vesting.Start)
+
+		case vesting.End != 0:
+			genAccount = authvesting.NewDelayedVestingAccountRaw(baseVestingAccount)
+
+		default:
+			return nil, balances, errors.New("invalid vesting parameters; must supply start and end time or end time")
+		}
+	} else if vesting.Locked {
+		return nil, balances, errors.New("invalid vesting parameters; permanently locked accounts must supply a vesting amount")
+	} else {
+		genAccount = baseAccount
+	}
+
+	if err := genAccount.Validate(); err != nil {
+		return nil, balances, fmt.Errorf("failed to validate new genesis account: %w", err)
+	}
+	return genAccount, balances, nil
+}
+
+// addGenesisAccounts adds the accounts and their balances to the auth and bank genesis states of appState, updating
+// the supply, and returns the marshalled application genesis state. The accounts must not exist in appState.
+func addGenesisAccounts(cdc codec.Codec, appState map[string]json.RawMessage, genAccounts []authtypes.GenesisAccount, balances []banktypes.Balance) (json.RawMessage, error) {
+	authGenState := authtypes.GetGenesisStateFromAppState(cdc, appState)
+
+	accs, err := authtypes.UnpackAccounts(authGenState.Accounts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get accounts from any: %w", err)
+	}
+
+	// Add the new accounts to the set of genesis accounts and sanitize the
+	// accounts afterwards.
+	accs = append(accs, genAccounts...)
+	accs = authtypes.SanitizeGenesisAccounts(accs)
+
+	genAccs, err := authtypes.PackAccounts(accs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert accounts into any's: %w", err)
+	}
+	authGenState.Accounts = genAccs
+
+	authGenStateBz, err := cdc.MarshalJSON(&authGenState)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal auth genesis state: %w", err)
+	}
+
+	appState[authtypes.ModuleName] = authGenStateBz
+
+	bankGenState := banktypes.GetGenesisStateFromAppState(cdc, appState)
+	bankGenState.Balances = append(bankGenState.Balances, balances...)
+	bankGenState.Balances = banktypes.SanitizeGenesisBalances(bankGenState.Balances)
+	// an empty supply is computed from the balances at genesis, it must then hold the balances added before too
+	if bankGenState.Supply.Empty() {
+		balances = bankGenState.Balances
+	}
+	for _, balance := range balances {
+		bankGenState.Supply = bankGenState.Supply.Add(balance.Coins...)
+	}
+
+	bankGenStateBz, err := cdc.MarshalJSON(bankGenState)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal bank genesis state: %w", err)
+	}
+
+	appState[banktypes.ModuleName] = bankGenStateBz
+
+	appStateJSON, err := json.Marshal(appState)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal application genesis state: %w", err)
+	}
+	return appStateJSON, nil
+}
@@ -0,0 +1,330 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/cosmos/cosmos-sdk/client"
+	"github.com/cosmos/cosmos-sdk/client/flags"
+	"github.com/cosmos/cosmos-sdk/server"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/version"
+	authtypes "github.com/cosmos/cosmos-sdk/x/auth/types"
+	banktypes "github.com/cosmos/cosmos-sdk/x/bank/types"
+	"github.com/cosmos/cosmos-sdk/x/genutil"
+	genutiltypes "github.com/cosmos/cosmos-sdk/x/genutil/types"
+)
+
+const flagAccountsFile = "file"
+
+// genesisAccountRecord is an account read from the file of add-genesis-accounts
+type genesisAccountRecord struct {
+	// Row is the line of the record in a csv file, or its index in a json file
+	Row           int    `json:"-"`
+	Address       string `json:"address"`
+	Coins         string `json:"coins"`
+	VestingAmount string `json:"vesting_amount"`
+	VestingStart  int64  `json:"vesting_start_time"`
+	VestingEnd    int64  `json:"vesting_end_time"`
+	// VestingPeriods is the schedule of a periodic vesting account in the format of --vesting-periods-file
+	VestingPeriods json.RawMessage `json:"vesting_periods"`
+	VestingLocked  bool            `json:"vesting_locked"`
+	// parseErr is the error met parsing the record from a csv file
+	parseErr error
+}
+
+// genesisAccountColumns are the columns of a csv file of add-genesis-accounts, the first two are required
+var genesisAccountColumns = []string{
+	"address", "coins", "vesting_amount", "vesting_start_time", "vesting_end_time", "vesting_periods", "vesting_locked",
+}
+
+// AddGenesisAccountsCmd returns add-genesis-accounts cobra Command.
+func AddGenesisAccountsCmd(defaultNodeHome string) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "add-genesis-accounts --file [accounts.csv|accounts.json]",
+		Short: "Add the genesis accounts of a csv or json file to genesis.json",
+		Long: fmt.Sprintf(`Add the genesis accounts of a csv or json file to genesis.json in a single pass, updating the
+bank supply. A csv file starts with a header naming its columns among %s, the first two being
+required. A json file holds an array of objects with the same fields. Coins are comma separated,
+vesting times are unix epochs, vesting periods follow the json format of --vesting-periods-file
+and vesting_locked is a boolean, all following the rules of add-genesis-account.
+
+All the invalid records and the addresses duplicated in the file or the genesis are reported
+together, in which case genesis.json is left unchanged.
+`, strings.Join(genesisAccountColumns, ", ")),
+		Example: fmt.Sprintf(`$ %s add-genesis-accounts --file accounts.csv
+
+where accounts.csv is:
+
+address,coins,vesting_amount,vesting_end_time
+orai1...,1000000orai,,
+orai1...,1000000orai,500000orai,1700000000`, version.AppName),
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			clientCtx := client.GetClientContextFromCmd(cmd)
+			cdc := clientCtx.Codec
+
+			serverCtx := server.GetServerContextFromCmd(cmd)
+			config := serverCtx.Config
+
+			config.SetRoot(clientCtx.HomeDir)
+
+			file, err := cmd.Flags().GetString(flagAccountsFile)
+			if err != nil {
+				return err
+			}
+			if file == "" {
+				return fmt.Errorf("--%s is required", flagAccountsFile)
+			}
+			records, err := readGenesisAccountsFile(file)
+			if err != nil {
+				return err
+			}
+
+			genFile := config.GenesisFile()
+			appState, genDoc, err := genutiltypes.GenesisStateFromGenFile(genFile)
+			if err != nil {
+				return fmt.Errorf("failed to unmarshal genesis state: %w", err)
+			}
+
+			authGenState := authtypes.GetGenesisStateFromAppState(cdc, appState)
+			accs, err := authtypes.UnpackAccounts(authGenState.Accounts)
+			if err != nil {
+				return fmt.Errorf("failed to get accounts from any: %w", err)
+			}
+			existing := make(map[string]bool, len(accs))
+			for _, acc := range accs {
+				existing[acc.GetAddress().String()] = true
+			}
+
+			genAccounts, balances, err := newGenesisAccounts(records, existing)
+			if err != nil {
+				return err
+			}
+
+			appStateJSON, err := addGenesisAccounts(cdc, appState, genAccounts, balances)
+			if err != nil {
+				return err
+			}
+
+			genDoc.AppState = appStateJSON
+			if err := genutil.ExportGenesisFile(genDoc, genFile); err != nil {
+				return err
+			}
+			cmd.Printf("added %d genesis accounts\n", len(genAccounts))
+			return nil
+		},
+	}
+
+	cmd.Flags().String(flags.FlagHome, defaultNodeHome, "The application home directory")
+	cmd.Flags().String(flagAccountsFile, "", "csv or json file of the accounts to add")
+	flags.AddQueryFlagsToCmd(cmd)
+
+	return cmd
+}
+
+// newGenesisAccounts creates the accounts and balances of records, reporting together the invalid records and the
+// addresses duplicated in records or found in existing
+func newGenesisAccounts(records []genesisAccountRecord, existing map[string]bool) ([]authtypes.GenesisAccount, []banktypes.Balance, error) {
+	genAccounts := make([]authtypes.GenesisAccount, 0, len(records))
+	balances := make([]banktypes.Balance, 0, len(records))
+	rows := make(map[string]int, len(records))
+
+	var problems []string
+	for _, record := range records {
+		genAccount, balance, err := newGenesisAccountFromRecord(record)
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("row %d: %s", record.Row, err))
+			continue
+		}
+
+		if row, found := rows[balance.Address]; found {
+			problems = append(problems, fmt.Sprintf("row %d: duplicate of row %d for address %s", record.Row, row, balance.Address))
+			continue
+		}
+		rows[balance.Address] = record.Row
+		if existing[balance.Address] {
+			problems = append(problems, fmt.Sprintf("row %d: cannot add account at existing address %s", record.Row, balance.Address))
+			continue
+		}
+
+		genAccounts = append(genAccounts, genAccount)
+		balances = append(balances, balance)
+	}
+
+	if len(problems) > 0 {
+		return nil, nil, fmt.Errorf("%d of %d accounts are invalid:\n%s", len(problems), len(records), strings.Join(problems, "\n"))
+	}
+	return genAccounts, balances, nil
+}
+
+// newGenesisAccountFromRecord creates the account and balance of record
+func newGenesisAccountFromRecord(record genesisAccountRecord) (authtypes.GenesisAccount, banktypes.Balance, error) {
+	if record.parseErr != nil {
+		return nil, banktypes.Balance{}, record.parseErr
+	}
+
+	addr, err := sdk.AccAddressFromBech32(record.Address)
+	if err != nil {
+		return nil, banktypes.Balance{}, fmt.Errorf("invalid address %q: %w", record.Address, err)
+	}
+
+	coins, err := sdk.ParseCoinsNormalized(record.Coins)
+	if err != nil {
+		return nil, banktypes.Balance{}, fmt.Errorf("failed to parse coins: %w", err)
+	}
+
+	vestingAmt, err := sdk.ParseCoinsNormalized(record.VestingAmount)
+	if err != nil {
+		return nil, banktypes.Balance{}, fmt.Errorf("failed to parse vesting amount: %w", err)
+	}
+
+	vesting := genesisVesting{Amount: vestingAmt, Start: record.VestingStart, End: record.VestingEnd, Locked: record.VestingLocked}
+	if len(record.VestingPeriods) > 0 {
+		if vesting.Start != 0 || vesting.End != 0 {
+			return nil, banktypes.Balance{}, errors.New("vesting periods cannot be combined with vesting times, the start time is read from the periods")
+		}
+		if vesting.Start, vesting.Periods, err = parseVestingPeriods(record.VestingPeriods); err != nil {
+			return nil, banktypes.Balance{}, err
+		}
+	}
+
+	return newGenesisAccount(addr, coins, vesting)
+}
+
+// readGenesisAccountsFile reads the records of a csv or json file depending on its extension
+func readGenesisAccountsFile(path string) ([]genesisAccountRecord, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".csv":
+		return readGenesisAccountsCSV(f)
+	case ".json":
+		return readGenesisAccountsJSON(f)
+	default:
+		return nil, fmt.Errorf("unsupported accounts file extension %q, expected .csv or .json", ext)
+	}
+}
+
+// readGenesisAccountsJSON reads the records of a json array, numbering them from 1
+func readGenesisAccountsJSON(r io.Reader) ([]genesisAccountRecord, error) {
+	decoder := json.NewDecoder(r)
+	decoder.DisallowUnknownFields()
+
+	var records []genesisAccountRecord
+	if err := decoder.Decode(&records); err != nil {
+		return nil, fmt.Errorf("failed to parse json accounts: %w", err)
+	}
+	for i := range records {
+		records[i].Row = i + 1
+	}
+	return records, nil
+}
+
+// readGenesisAccountsCSV reads the records of a csv file with a header, numbering them by line
+func readGenesisAccountsCSV(r io.Reader) ([]genesisAccountRecord, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+	// rows with a wrong number of fields are reported along with the other invalid records
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read csv header: %w", err)
+	}
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if !containsString(genesisAccountColumns, name) {
+			return nil, fmt.Errorf("unknown csv column %q, expected one of %s", name, strings.Join(genesisAccountColumns, ", "))
+		}
+		if _, found := columns[name]; found {
+			return nil, fmt.Errorf("duplicate csv column %q", name)
+		}
+		columns[name] = i
+	}
+	for _, name := range genesisAccountColumns[:2] {
+		if _, found := columns[name]; !found {
+			return nil, fmt.Errorf("missing csv column %q", name)
+		}
+	}
+
+	var records []genesisAccountRecord
+	for {
+		fields, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read csv: %w", err)
+		}
+		line, _ := reader.FieldPos(0)
+		records = append(records, parseGenesisAccountCSVRecord(line, columns, fields))
+	}
+	return records, nil
+}
+
+// parseGenesisAccountCSVRecord parses the fields of a csv row, setting the parse error of the record if they are invalid
+func parseGenesisAccountCSVRecord(line int, columns map[string]int, fields []string) genesisAccountRecord {
+	record := genesisAccountRecord{Row: line}
+	if len(fields) != len(columns) {
+		record.parseErr = fmt.Errorf("expected %d fields, got %d", len(columns), len(fields))
+		return record
+	}
+
+	field := func(name string) string {
+		if i, found := columns[name]; found {
+			return strings.TrimSpace(fields[i])
+		}
+		return ""
+	}
+	parseTime := func(name string) int64 {
+		value := field(name)
+		if value == "" || record.parseErr != nil {
+			return 0
+		}
+		t, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			record.parseErr = fmt.Errorf("invalid %s %q", name, value)
+		}
+		return t
+	}
+
+	record.Address = field("address")
+	record.Coins = field("coins")
+	record.VestingAmount = field("vesting_amount")
+	record.VestingStart = parseTime("vesting_start_time")
+	record.VestingEnd = parseTime("vesting_end_time")
+	if periods := field("vesting_periods"); periods != "" {
+		record.VestingPeriods = json.RawMessage(periods)
+	}
+	if locked := field("vesting_locked"); locked != "" && record.parseErr == nil {
+		var err error
+		if record.VestingLocked, err = strconv.ParseBool(locked); err != nil {
+			record.parseErr = fmt.Errorf("invalid vesting_locked %q", locked)
+		}
+	}
+	return record
+}
+
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
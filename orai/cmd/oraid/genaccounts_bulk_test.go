@@ -0,0 +1,109 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	authtypes "github.com/cosmos/cosmos-sdk/x/auth/types"
+	authvesting "github.com/cosmos/cosmos-sdk/x/auth/vesting/types"
+	banktypes "github.com/cosmos/cosmos-sdk/x/bank/types"
+	"github.com/oraichain/orai/app"
+	"github.com/stretchr/testify/require"
+)
+
+func writeAccountsFile(t *testing.T, name, content string) string {
+	path := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	return path
+}
+
+func TestAddGenesisAccountsFromFile(t *testing.T) {
+	cdc := app.MakeEncodingConfig().Codec
+	addr1, addr2, addr3 := sdk.AccAddress("addr1_______________"), sdk.AccAddress("addr2_______________"), sdk.AccAddress("addr3_______________")
+	addr4, addr5 := sdk.AccAddress("addr4_______________"), sdk.AccAddress("addr5_______________")
+
+	csvFile := writeAccountsFile(t, "accounts.csv", fmt.Sprintf(`address,coins,vesting_amount,vesting_start_time,vesting_end_time,vesting_periods
+%s,100orai,,,,
+%s, 50orai,20orai,1000,2000,
+%s,10orai,,,,"{""start_time"":1000,""periods"":[{""coins"":""4orai"",""length_seconds"":10},{""coins"":""6orai"",""length_seconds"":10}]}"
+`, addr1, addr2, addr5))
+	jsonFile := writeAccountsFile(t, "accounts.json", fmt.Sprintf(`[
+		{"address":"%s","coins":"10orai,5uatom","vesting_periods":{"start_time":1000,"periods":[{"coins":"5uatom","length_seconds":10}]}},
+		{"address":"%s","coins":"10orai","vesting_amount":"10orai","vesting_locked":true}
+	]`, addr3, addr4))
+
+	appState := app.NewDefaultGenesisState(cdc)
+	for _, file := range []string{csvFile, jsonFile} {
+		records, err := readGenesisAccountsFile(file)
+		require.NoError(t, err)
+		genAccounts, balances, err := newGenesisAccounts(records, nil)
+		require.NoError(t, err)
+		_, err = addGenesisAccounts(cdc, appState, genAccounts, balances)
+		require.NoError(t, err)
+	}
+
+	authGenState := authtypes.GetGenesisStateFromAppState(cdc, appState)
+	accs, err := authtypes.UnpackAccounts(authGenState.Accounts)
+	require.NoError(t, err)
+	require.Len(t, accs, 5)
+	types := map[string]authtypes.GenesisAccount{}
+	for _, acc := range accs {
+		types[acc.GetAddress().String()] = acc
+	}
+	require.IsType(t, &authtypes.BaseAccount{}, types[addr1.String()])
+	require.IsType(t, &authvesting.ContinuousVestingAccount{}, types[addr2.String()])
+	require.IsType(t, &authvesting.PeriodicVestingAccount{}, types[addr3.String()])
+	require.IsType(t, &authvesting.PermanentLockedAccount{}, types[addr4.String()])
+	require.IsType(t, &authvesting.PeriodicVestingAccount{}, types[addr5.String()])
+
+	bankGenState := banktypes.GetGenesisStateFromAppState(cdc, appState)
+	require.Len(t, bankGenState.Balances, 5)
+	require.Equal(t, sdk.NewCoins(sdk.NewInt64Coin("orai", 180), sdk.NewInt64Coin("uatom", 5)), bankGenState.Supply)
+}
+
+func TestAddGenesisAccountsReportsInvalidRows(t *testing.T) {
+	addr1, addr2, addr3 := sdk.AccAddress("addr1_______________"), sdk.AccAddress("addr2_______________"), sdk.AccAddress("addr3_______________")
+
+	file := writeAccountsFile(t, "accounts.csv", fmt.Sprintf(`address,coins,vesting_end_time
+%[1]s,100orai,
+%[1]s,100orai,
+invalid,100orai,
+%[2]s,-1orai,
+%[2]s,100orai,soon
+%[2]s,100orai
+%[3]s,100orai,
+%[2]s,100orai,
+`, addr1, addr2, addr3))
+
+	records, err := readGenesisAccountsFile(file)
+	require.NoError(t, err)
+	require.Len(t, records, 8)
+
+	_, _, err = newGenesisAccounts(records, map[string]bool{addr3.String(): true})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "6 of 8 accounts are invalid")
+	for _, problem := range []string{
+		fmt.Sprintf("row 3: duplicate of row 2 for address %s", addr1),
+		`row 4: invalid address "invalid"`,
+		"row 5: failed to parse coins",
+		`row 6: invalid vesting_end_time "soon"`,
+		"row 7: expected 3 fields, got 2",
+		fmt.Sprintf("row 8: cannot add account at existing address %s", addr3),
+	} {
+		require.Contains(t, err.Error(), problem)
+	}
+}
+
+func TestReadGenesisAccountsFileRejectsUnknownColumns(t *testing.T) {
+	_, err := readGenesisAccountsFile(writeAccountsFile(t, "accounts.csv", "address,amount\n"))
+	require.ErrorContains(t, err, `unknown csv column "amount"`)
+
+	_, err = readGenesisAccountsFile(writeAccountsFile(t, "accounts.csv", "address\n"))
+	require.ErrorContains(t, err, `missing csv column "coins"`)
+
+	_, err = readGenesisAccountsFile(writeAccountsFile(t, "accounts.txt", ""))
+	require.ErrorContains(t, err, "unsupported accounts file extension")
+}
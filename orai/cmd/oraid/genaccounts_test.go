@@ -0,0 +1,103 @@
+package main
+
+import (
+	"testing"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	authtypes "github.com/cosmos/cosmos-sdk/x/auth/types"
+	authvesting "github.com/cosmos/cosmos-sdk/x/auth/vesting/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewGenesisAccountRoundTrip(t *testing.T) {
+	addr := sdk.AccAddress("addr________________")
+	coins := sdk.NewCoins(sdk.NewInt64Coin("orai", 1000))
+	vestingAmt := sdk.NewCoins(sdk.NewInt64Coin("orai", 600))
+
+	startTime, periods, err := parseVestingPeriods([]byte(`{"start_time": 1000, "periods": [
+		{"coins": "300orai", "length_seconds": 100},
+		{"coins": "100orai", "length_seconds": 30},
+		{"coins": "100orai", "length_seconds": 30},
+		{"coins": "100orai", "length_seconds": 30}
+	]}`))
+	require.NoError(t, err)
+
+	testCases := []struct {
+		name     string
+		vesting  genesisVesting
+		expected authtypes.GenesisAccount
+	}{
+		{"base", genesisVesting{}, &authtypes.BaseAccount{}},
+		{"continuous", genesisVesting{Amount: vestingAmt, Start: 1000, End: 2000}, &authvesting.ContinuousVestingAccount{}},
+		{"delayed", genesisVesting{Amount: vestingAmt, End: 2000}, &authvesting.DelayedVestingAccount{}},
+		{"periodic", genesisVesting{Start: startTime, Periods: periods}, &authvesting.PeriodicVestingAccount{}},
+		{"periodic with amount", genesisVesting{Amount: vestingAmt, Start: startTime, Periods: periods}, &authvesting.PeriodicVestingAccount{}},
+		{"permanently locked", genesisVesting{Amount: vestingAmt, Locked: true}, &authvesting.PermanentLockedAccount{}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			genAccount, balance, err := newGenesisAccount(addr, coins, tc.vesting)
+			require.NoError(t, err)
+			require.IsType(t, tc.expected, genAccount)
+			require.Equal(t, coins, balance.Coins)
+
+			packed, err := authtypes.PackAccounts(authtypes.GenesisAccounts{genAccount})
+			require.NoError(t, err)
+			unpacked, err := authtypes.UnpackAccounts(packed)
+			require.NoError(t, err)
+			require.Len(t, unpacked, 1)
+			require.Equal(t, genAccount, unpacked[0])
+			require.NoError(t, unpacked[0].Validate())
+		})
+	}
+
+	periodic, _, err := newGenesisAccount(addr, coins, genesisVesting{Start: startTime, Periods: periods})
+	require.NoError(t, err)
+	require.Equal(t, int64(1190), periodic.(*authvesting.PeriodicVestingAccount).EndTime)
+	require.Equal(t, vestingAmt, periodic.(*authvesting.PeriodicVestingAccount).OriginalVesting)
+}
+
+func TestNewGenesisAccountInvalidVesting(t *testing.T) {
+	addr := sdk.AccAddress("addr________________")
+	coins := sdk.NewCoins(sdk.NewInt64Coin("orai", 1000))
+	vestingAmt := sdk.NewCoins(sdk.NewInt64Coin("orai", 600))
+	periods := authvesting.Periods{{Length: 100, Amount: sdk.NewCoins(sdk.NewInt64Coin("orai", 500))}}
+
+	testCases := []struct {
+		name    string
+		vesting genesisVesting
+		err     string
+	}{
+		{"periods not summing to the amount", genesisVesting{Amount: vestingAmt, Start: 1000, Periods: periods}, "vesting periods sum to 500orai instead of the vesting amount 600orai"},
+		{"periods above the balance", genesisVesting{Start: 1000, Periods: authvesting.Periods{{Length: 100, Amount: sdk.NewCoins(sdk.NewInt64Coin("orai", 2000))}}}, "vesting amount cannot be greater than total amount"},
+		{"locked with times", genesisVesting{Amount: vestingAmt, End: 2000, Locked: true}, "permanently locked accounts take no vesting time or periods"},
+		{"locked without amount", genesisVesting{Locked: true}, "permanently locked accounts must supply a vesting amount"},
+		{"no end time", genesisVesting{Amount: vestingAmt, Start: 1000}, "must supply start and end time or end time"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, _, err := newGenesisAccount(addr, coins, tc.vesting)
+			require.ErrorContains(t, err, tc.err)
+		})
+	}
+}
+
+func TestParseVestingPeriods(t *testing.T) {
+	for name, tc := range map[string]struct {
+		json string
+		err  string
+	}{
+		"no periods":       {`{"start_time": 1000, "periods": []}`, "no vesting periods"},
+		"zero length":      {`{"start_time": 1000, "periods": [{"coins": "1orai", "length_seconds": 0}]}`, "vesting period 0 must have a positive length"},
+		"zero amount":      {`{"start_time": 1000, "periods": [{"coins": "", "length_seconds": 10}]}`, "vesting period 0 must have a positive amount"},
+		"invalid coins":    {`{"start_time": 1000, "periods": [{"coins": "1", "length_seconds": 10}]}`, "failed to parse coins of vesting period 0"},
+		"malformed schema": {`{"start_time": "soon"}`, "failed to parse vesting periods"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, _, err := parseVestingPeriods([]byte(tc.json))
+			require.ErrorContains(t, err, tc.err)
+		})
+	}
+}
@@ -0,0 +1,532 @@
+package main
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	wasmtypes "github.com/CosmWasm/wasmd/x/wasm/types"
+	"github.com/cosmos/cosmos-sdk/client"
+	"github.com/cosmos/cosmos-sdk/codec"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/types/module"
+	"github.com/cosmos/cosmos-sdk/version"
+	banktypes "github.com/cosmos/cosmos-sdk/x/bank/types"
+	genutiltypes "github.com/cosmos/cosmos-sdk/x/genutil/types"
+	ibchost "github.com/cosmos/ibc-go/v4/modules/core/24-host"
+	ibccoretypes "github.com/cosmos/ibc-go/v4/modules/core/types"
+	ibctmtypes "github.com/cosmos/ibc-go/v4/modules/light-clients/07-tendermint/types"
+	"github.com/oraichain/orai/app/params"
+	"github.com/spf13/cobra"
+	"github.com/tendermint/tendermint/libs/cli"
+)
+
+// genesisDiff is the difference between an old and a new genesis file
+type genesisDiff struct {
+	ChainID       *valueChange    `json:"chain_id,omitempty"`
+	InitialHeight *valueChange    `json:"initial_height,omitempty"`
+	Modules       []moduleDiff    `json:"modules"`
+	Unchanged     []string        `json:"unchanged"`
+	Supply        []supplyChange  `json:"supply,omitempty"`
+	Balances      *balancesDiff   `json:"balances,omitempty"`
+	Wasm          *wasmDiff       `json:"wasm,omitempty"`
+	IBCClients    *ibcClientsDiff `json:"ibc_clients,omitempty"`
+}
+
+// moduleDiff is the difference between the genesis states of a module, its params being compared value by value
+type moduleDiff struct {
+	Module string        `json:"module"`
+	Status string        `json:"status"`
+	Params []valueChange `json:"params,omitempty"`
+	Errors []string      `json:"errors,omitempty"`
+}
+
+type valueChange struct {
+	Path string `json:"path,omitempty"`
+	Old  string `json:"old"`
+	New  string `json:"new"`
+}
+
+type supplyChange struct {
+	Denom string  `json:"denom"`
+	Old   sdk.Int `json:"old"`
+	New   sdk.Int `json:"new"`
+	Delta sdk.Int `json:"delta"`
+}
+
+// balancesDiff counts the accounts whose balances were added, removed or changed
+type balancesDiff struct {
+	Added   int `json:"added"`
+	Removed int `json:"removed"`
+	Changed int `json:"changed"`
+}
+
+type wasmDiff struct {
+	CodesAdded   []uint64 `json:"codes_added"`
+	CodesRemoved []uint64 `json:"codes_removed"`
+	// CodesChanged are the code ids whose code hash differs
+	CodesChanged     []uint64 `json:"codes_changed"`
+	ContractsAdded   int      `json:"contracts_added"`
+	ContractsRemoved int      `json:"contracts_removed"`
+	// ContractsMigrated counts the contracts whose code id differs
+	ContractsMigrated int `json:"contracts_migrated"`
+}
+
+type ibcClientsDiff struct {
+	Added   []string `json:"added"`
+	Removed []string `json:"removed"`
+}
+
+// GenesisCmd returns the genesis file tools
+func GenesisCmd(mbm module.BasicManager, encodingConfig params.EncodingConfig) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:                        "genesis",
+		Short:                      "Genesis file tools",
+		DisableFlagParsing:         true,
+		SuggestionsMinimumDistance: 2,
+		RunE:                       client.ValidateCmd,
+	}
+	cmd.AddCommand(GenesisDiffCmd(mbm, encodingConfig))
+	return cmd
+}
+
+// GenesisDiffCmd returns a command comparing two genesis files module by module
+func GenesisDiffCmd(mbm module.BasicManager, encodingConfig params.EncodingConfig) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "diff [old-genesis-file] [new-genesis-file]",
+		Short: "Compare two genesis files module by module",
+		Long: `Compare two genesis files or exports module by module, for instance the exports before and after an upgrade.
+
+Each module is decoded and validated with the codec of the app. The params of the modules are compared value by value,
+decimals and durations being compared by value whatever their formatting, and the other changes to the state of the
+modules are only reported. The supply and the balances of the bank module, the codes and contracts of the wasm module
+and the IBC clients are summarized.`,
+		Example: fmt.Sprintf(`$ %s genesis diff export-v0.41.json export-v0.42.json --output json`, version.AppName),
+		Args:    cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			output, _ := cmd.Flags().GetString(cli.OutputFlag)
+			if output != "text" && output != "json" {
+				return fmt.Errorf("unsupported output %s, expected text or json", output)
+			}
+
+			diff, err := diffGenesisFiles(mbm, encodingConfig, args[0], args[1])
+			if err != nil {
+				return err
+			}
+
+			if output == "json" {
+				bz, err := json.MarshalIndent(diff, "", "  ")
+				if err != nil {
+					return err
+				}
+				cmd.Println(string(bz))
+				return nil
+			}
+			printGenesisDiff(cmd, diff)
+			return nil
+		},
+	}
+
+	cmd.Flags().String(cli.OutputFlag, "text", "Output format (text|json)")
+	return cmd
+}
+
+// diffGenesisFiles compares the genesis files oldFile and newFile
+func diffGenesisFiles(mbm module.BasicManager, encodingConfig params.EncodingConfig, oldFile, newFile string) (*genesisDiff, error) {
+	oldState, oldDoc, err := genutiltypes.GenesisStateFromGenFile(oldFile)
+	if err != nil {
+		return nil, err
+	}
+	newState, newDoc, err := genutiltypes.GenesisStateFromGenFile(newFile)
+	if err != nil {
+		return nil, err
+	}
+
+	diff := &genesisDiff{Modules: []moduleDiff{}, Unchanged: []string{}}
+	if oldDoc.ChainID != newDoc.ChainID {
+		diff.ChainID = &valueChange{Old: oldDoc.ChainID, New: newDoc.ChainID}
+	}
+	if oldDoc.InitialHeight != newDoc.InitialHeight {
+		diff.InitialHeight = &valueChange{Old: fmt.Sprint(oldDoc.InitialHeight), New: fmt.Sprint(newDoc.InitialHeight)}
+	}
+
+	names := make(map[string]bool)
+	for name := range oldState {
+		names[name] = true
+	}
+	for name := range newState {
+		names[name] = true
+	}
+	sorted := make([]string, 0, len(names))
+	for name := range names {
+		sorted = append(sorted, name)
+	}
+	sort.Strings(sorted)
+
+	for _, name := range sorted {
+		moduleDiff, err := diffModuleGenesis(mbm, encodingConfig, name, oldState[name], newState[name])
+		if err != nil {
+			return nil, err
+		}
+		if moduleDiff == nil {
+			diff.Unchanged = append(diff.Unchanged, name)
+		} else {
+			diff.Modules = append(diff.Modules, *moduleDiff)
+		}
+	}
+
+	cdc := encodingConfig.Codec
+	if oldState[banktypes.ModuleName] != nil && newState[banktypes.ModuleName] != nil {
+		if diff.Supply, diff.Balances, err = diffBankGenesis(cdc, oldState[banktypes.ModuleName], newState[banktypes.ModuleName]); err != nil {
+			return nil, err
+		}
+	}
+	if oldState[wasmtypes.ModuleName] != nil && newState[wasmtypes.ModuleName] != nil {
+		if diff.Wasm, err = diffWasmGenesis(cdc, oldState[wasmtypes.ModuleName], newState[wasmtypes.ModuleName]); err != nil {
+			return nil, err
+		}
+	}
+	if oldState[ibchost.ModuleName] != nil && newState[ibchost.ModuleName] != nil {
+		if diff.IBCClients, err = diffIBCClients(cdc, oldState[ibchost.ModuleName], newState[ibchost.ModuleName]); err != nil {
+			return nil, err
+		}
+	}
+	return diff, nil
+}
+
+// diffModuleGenesis compares the genesis states of the module name, returning nil when they are the same and valid
+func diffModuleGenesis(mbm module.BasicManager, encodingConfig params.EncodingConfig, name string, oldState, newState json.RawMessage) (*moduleDiff, error) {
+	diff := &moduleDiff{Module: name}
+	switch {
+	case oldState == nil:
+		diff.Status = "added"
+	case newState == nil:
+		diff.Status = "removed"
+	default:
+		oldSorted, err := sdk.SortJSON(oldState)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s genesis: %w", name, err)
+		}
+		newSorted, err := sdk.SortJSON(newState)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s genesis: %w", name, err)
+		}
+		if !bytes.Equal(oldSorted, newSorted) {
+			diff.Status = "changed"
+		}
+	}
+
+	if basic, found := mbm[name]; found {
+		for _, state := range []struct {
+			label string
+			bz    json.RawMessage
+		}{{"old", oldState}, {"new", newState}} {
+			if state.bz == nil {
+				continue
+			}
+			if err := basic.ValidateGenesis(encodingConfig.Codec, encodingConfig.TxConfig, state.bz); err != nil {
+				diff.Errors = append(diff.Errors, fmt.Sprintf("invalid %s genesis: %v", state.label, err))
+			}
+		}
+	}
+
+	oldParams, err := paramValues(oldState)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s genesis: %w", name, err)
+	}
+	newParams, err := paramValues(newState)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s genesis: %w", name, err)
+	}
+	paths := make([]string, 0, len(oldParams)+len(newParams))
+	for path := range oldParams {
+		paths = append(paths, path)
+	}
+	for path := range newParams {
+		if _, found := oldParams[path]; !found {
+			paths = append(paths, path)
+		}
+	}
+	sort.Strings(paths)
+	for _, path := range paths {
+		oldValue, newValue := oldParams[path], newParams[path]
+		if !sameParamValue(oldValue, newValue) {
+			diff.Params = append(diff.Params, valueChange{Path: path, Old: oldValue, New: newValue})
+		}
+	}
+
+	if diff.Status == "" && len(diff.Errors) == 0 {
+		return nil, nil
+	}
+	if diff.Status == "" {
+		diff.Status = "unchanged"
+	}
+	return diff, nil
+}
+
+// paramValues flattens the params of a genesis state, which are the objects named params or ending with _params of
+// the state or of its nested states, into values by path. Lists are kept as single values.
+func paramValues(state json.RawMessage) (map[string]string, error) {
+	values := make(map[string]string)
+	if state == nil {
+		return values, nil
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(state))
+	decoder.UseNumber()
+	var root interface{}
+	if err := decoder.Decode(&root); err != nil {
+		return nil, err
+	}
+
+	var flatten func(path string, value interface{})
+	flatten = func(path string, value interface{}) {
+		switch value := value.(type) {
+		case map[string]interface{}:
+			for key, item := range value {
+				flatten(path+"."+key, item)
+			}
+		case string:
+			values[path] = value
+		default:
+			bz, _ := json.Marshal(value)
+			values[path] = string(bz)
+		}
+	}
+	var find func(path string, value interface{}, depth int)
+	find = func(path string, value interface{}, depth int) {
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			return
+		}
+		for key, item := range object {
+			switch {
+			case key == "params" || strings.HasSuffix(key, "_params"):
+				flatten(strings.TrimPrefix(path+"."+key, "."), item)
+			case depth < 2:
+				find(path+"."+key, item, depth+1)
+			}
+		}
+	}
+	find("", root, 0)
+	return values, nil
+}
+
+// sameParamValue compares decimals and durations by value, and the other values as they are
+func sameParamValue(oldValue, newValue string) bool {
+	if oldValue == newValue {
+		return true
+	}
+	if oldDec, err := sdk.NewDecFromStr(oldValue); err == nil {
+		newDec, err := sdk.NewDecFromStr(newValue)
+		return err == nil && oldDec.Equal(newDec)
+	}
+	if oldDuration, err := time.ParseDuration(oldValue); err == nil {
+		newDuration, err := time.ParseDuration(newValue)
+		return err == nil && oldDuration == newDuration
+	}
+	return false
+}
+
+// diffBankGenesis summarizes the changes to the supply and to the balances
+func diffBankGenesis(cdc codec.JSONCodec, oldState, newState json.RawMessage) ([]supplyChange, *balancesDiff, error) {
+	var oldGenesis, newGenesis banktypes.GenesisState
+	if err := cdc.UnmarshalJSON(oldState, &oldGenesis); err != nil {
+		return nil, nil, fmt.Errorf("invalid old bank genesis: %w", err)
+	}
+	if err := cdc.UnmarshalJSON(newState, &newGenesis); err != nil {
+		return nil, nil, fmt.Errorf("invalid new bank genesis: %w", err)
+	}
+
+	oldSupply, newSupply := genesisSupply(oldGenesis), genesisSupply(newGenesis)
+	denoms := make(map[string]bool)
+	for _, coin := range append(oldSupply, newSupply...) {
+		denoms[coin.Denom] = true
+	}
+	var supply []supplyChange
+	for denom := range denoms {
+		oldAmount, newAmount := oldSupply.AmountOf(denom), newSupply.AmountOf(denom)
+		if !oldAmount.Equal(newAmount) {
+			supply = append(supply, supplyChange{Denom: denom, Old: oldAmount, New: newAmount, Delta: newAmount.Sub(oldAmount)})
+		}
+	}
+	sort.Slice(supply, func(i, j int) bool { return supply[i].Denom < supply[j].Denom })
+
+	oldBalances := make(map[string]sdk.Coins, len(oldGenesis.Balances))
+	for _, balance := range oldGenesis.Balances {
+		oldBalances[balance.Address] = balance.Coins
+	}
+	balances := &balancesDiff{}
+	for _, balance := range newGenesis.Balances {
+		oldCoins, found := oldBalances[balance.Address]
+		switch {
+		case !found:
+			balances.Added++
+		case !oldCoins.IsEqual(balance.Coins):
+			balances.Changed++
+		}
+		delete(oldBalances, balance.Address)
+	}
+	balances.Removed = len(oldBalances)
+	return supply, balances, nil
+}
+
+// genesisSupply returns the supply of genesis, the sum of the balances when it is left empty
+func genesisSupply(genesis banktypes.GenesisState) sdk.Coins {
+	if !genesis.Supply.Empty() {
+		return genesis.Supply
+	}
+	supply := sdk.NewCoins()
+	for _, balance := range genesis.Balances {
+		supply = supply.Add(balance.Coins...)
+	}
+	return supply
+}
+
+// diffWasmGenesis compares the codes by id and code hash, and the contracts by address and code id
+func diffWasmGenesis(cdc codec.JSONCodec, oldState, newState json.RawMessage) (*wasmDiff, error) {
+	var oldGenesis, newGenesis wasmtypes.GenesisState
+	if err := cdc.UnmarshalJSON(oldState, &oldGenesis); err != nil {
+		return nil, fmt.Errorf("invalid old wasm genesis: %w", err)
+	}
+	if err := cdc.UnmarshalJSON(newState, &newGenesis); err != nil {
+		return nil, fmt.Errorf("invalid new wasm genesis: %w", err)
+	}
+
+	diff := &wasmDiff{CodesAdded: []uint64{}, CodesRemoved: []uint64{}, CodesChanged: []uint64{}}
+	oldCodes := make(map[uint64]string, len(oldGenesis.Codes))
+	for _, code := range oldGenesis.Codes {
+		oldCodes[code.CodeID] = hex.EncodeToString(code.CodeInfo.CodeHash)
+	}
+	for _, code := range newGenesis.Codes {
+		oldHash, found := oldCodes[code.CodeID]
+		switch {
+		case !found:
+			diff.CodesAdded = append(diff.CodesAdded, code.CodeID)
+		case oldHash != hex.EncodeToString(code.CodeInfo.CodeHash):
+			diff.CodesChanged = append(diff.CodesChanged, code.CodeID)
+		}
+		delete(oldCodes, code.CodeID)
+	}
+	for codeID := range oldCodes {
+		diff.CodesRemoved = append(diff.CodesRemoved, codeID)
+	}
+	sort.Slice(diff.CodesRemoved, func(i, j int) bool { return diff.CodesRemoved[i] < diff.CodesRemoved[j] })
+
+	oldContracts := make(map[string]uint64, len(oldGenesis.Contracts))
+	for _, contract := range oldGenesis.Contracts {
+		oldContracts[contract.ContractAddress] = contract.ContractInfo.CodeID
+	}
+	for _, contract := range newGenesis.Contracts {
+		oldCodeID, found := oldContracts[contract.ContractAddress]
+		switch {
+		case !found:
+			diff.ContractsAdded++
+		case oldCodeID != contract.ContractInfo.CodeID:
+			diff.ContractsMigrated++
+		}
+		delete(oldContracts, contract.ContractAddress)
+	}
+	diff.ContractsRemoved = len(oldContracts)
+	return diff, nil
+}
+
+// diffIBCClients compares the IBC clients by id, described with the chain id of tendermint clients
+func diffIBCClients(cdc codec.JSONCodec, oldState, newState json.RawMessage) (*ibcClientsDiff, error) {
+	oldClients, err := ibcClients(cdc, oldState)
+	if err != nil {
+		return nil, fmt.Errorf("invalid old ibc genesis: %w", err)
+	}
+	newClients, err := ibcClients(cdc, newState)
+	if err != nil {
+		return nil, fmt.Errorf("invalid new ibc genesis: %w", err)
+	}
+
+	diff := &ibcClientsDiff{Added: []string{}, Removed: []string{}}
+	for id, description := range newClients {
+		if _, found := oldClients[id]; !found {
+			diff.Added = append(diff.Added, description)
+		}
+	}
+	for id, description := range oldClients {
+		if _, found := newClients[id]; !found {
+			diff.Removed = append(diff.Removed, description)
+		}
+	}
+	sort.Strings(diff.Added)
+	sort.Strings(diff.Removed)
+	return diff, nil
+}
+
+func ibcClients(cdc codec.JSONCodec, state json.RawMessage) (map[string]string, error) {
+	var genesis ibccoretypes.GenesisState
+	if err := cdc.UnmarshalJSON(state, &genesis); err != nil {
+		return nil, err
+	}
+	clients := make(map[string]string, len(genesis.ClientGenesis.Clients))
+	for _, client := range genesis.ClientGenesis.Clients {
+		description := client.ClientId
+		if clientState, ok := client.ClientState.GetCachedValue().(*ibctmtypes.ClientState); ok {
+			description = fmt.Sprintf("%s (%s)", client.ClientId, clientState.ChainId)
+		}
+		clients[client.ClientId] = description
+	}
+	return clients, nil
+}
+
+func printGenesisDiff(cmd *cobra.Command, diff *genesisDiff) {
+	if diff.ChainID != nil {
+		cmd.Printf("chain_id: %s -> %s\n", diff.ChainID.Old, diff.ChainID.New)
+	}
+	if diff.InitialHeight != nil {
+		cmd.Printf("initial_height: %s -> %s\n", diff.InitialHeight.Old, diff.InitialHeight.New)
+	}
+
+	for _, module := range diff.Modules {
+		cmd.Printf("%s: %s\n", module.Module, module.Status)
+		for _, change := range module.Params {
+			cmd.Printf("  %s: %s -> %s\n", change.Path, orNone(change.Old), orNone(change.New))
+		}
+		for _, err := range module.Errors {
+			cmd.Printf("  %s\n", err)
+		}
+	}
+	if len(diff.Unchanged) > 0 {
+		cmd.Printf("unchanged: %s\n", strings.Join(diff.Unchanged, ", "))
+	}
+
+	if len(diff.Supply) > 0 {
+		cmd.Println("supply:")
+		for _, change := range diff.Supply {
+			cmd.Printf("  %s: %s -> %s (%s)\n", change.Denom, change.Old, change.New, signed(change.Delta))
+		}
+	}
+	if diff.Balances != nil {
+		cmd.Printf("balances: %d accounts added, %d removed, %d changed\n", diff.Balances.Added, diff.Balances.Removed, diff.Balances.Changed)
+	}
+	if diff.Wasm != nil {
+		cmd.Printf("wasm codes: added %v, removed %v, changed %v\n", diff.Wasm.CodesAdded, diff.Wasm.CodesRemoved, diff.Wasm.CodesChanged)
+		cmd.Printf("wasm contracts: %d added, %d removed, %d migrated\n", diff.Wasm.ContractsAdded, diff.Wasm.ContractsRemoved, diff.Wasm.ContractsMigrated)
+	}
+	if diff.IBCClients != nil {
+		cmd.Printf("ibc clients: added %v, removed %v\n", diff.IBCClients.Added, diff.IBCClients.Removed)
+	}
+}
+
+func orNone(value string) string {
+	if value == "" {
+		return "(none)"
+	}
+	return value
+}
+
+func signed(amount sdk.Int) string {
+	if amount.IsNegative() {
+		return amount.String()
+	}
+	return "+" + amount.String()
+}
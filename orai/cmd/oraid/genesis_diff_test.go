@@ -0,0 +1,120 @@
+package main
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	wasmtypes "github.com/CosmWasm/wasmd/x/wasm/types"
+	"github.com/cosmos/cosmos-sdk/codec"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	banktypes "github.com/cosmos/cosmos-sdk/x/bank/types"
+	minttypes "github.com/cosmos/cosmos-sdk/x/mint/types"
+	stakingtypes "github.com/cosmos/cosmos-sdk/x/staking/types"
+	clienttypes "github.com/cosmos/ibc-go/v4/modules/core/02-client/types"
+	commitmenttypes "github.com/cosmos/ibc-go/v4/modules/core/23-commitment/types"
+	ibchost "github.com/cosmos/ibc-go/v4/modules/core/24-host"
+	ibccoretypes "github.com/cosmos/ibc-go/v4/modules/core/types"
+	ibctmtypes "github.com/cosmos/ibc-go/v4/modules/light-clients/07-tendermint/types"
+	"github.com/oraichain/orai/app"
+	"github.com/stretchr/testify/require"
+	tmtypes "github.com/tendermint/tendermint/types"
+)
+
+// writeGenesisFile writes the genesis file of chainID with appState to dir
+func writeGenesisFile(t *testing.T, dir, chainID string, appState app.GenesisState) string {
+	bz, err := json.Marshal(appState)
+	require.NoError(t, err)
+	genFile := filepath.Join(dir, chainID+".json")
+	require.NoError(t, (&tmtypes.GenesisDoc{ChainID: chainID, AppState: bz}).SaveAs(genFile))
+	return genFile
+}
+
+// updateGenesis decodes the genesis state of a module into state, applies update and encodes it back
+func updateGenesis(t *testing.T, cdc codec.Codec, genesisState app.GenesisState, module string, state codec.ProtoMarshaler, update func()) {
+	require.NoError(t, cdc.UnmarshalJSON(genesisState[module], state))
+	update()
+	bz, err := cdc.MarshalJSON(state)
+	require.NoError(t, err)
+	genesisState[module] = bz
+}
+
+func TestDiffGenesisFiles(t *testing.T) {
+	encodingConfig := app.MakeEncodingConfig()
+	cdc := encodingConfig.Codec
+	dir := t.TempDir()
+
+	oldState := app.NewDefaultGenesisState(cdc)
+	oldGenFile := writeGenesisFile(t, dir, "old-1", oldState)
+
+	newState := app.NewDefaultGenesisState(cdc)
+	// the same params formatted differently are not a change
+	newState[minttypes.ModuleName] = json.RawMessage(strings.Replace(string(newState[minttypes.ModuleName]), `"0.200000000000000000"`, `"0.2"`, 1))
+	var staking stakingtypes.GenesisState
+	updateGenesis(t, cdc, newState, stakingtypes.ModuleName, &staking, func() {
+		staking.Params.UnbondingTime = 0
+	})
+	var bank banktypes.GenesisState
+	updateGenesis(t, cdc, newState, banktypes.ModuleName, &bank, func() {
+		bank.Balances = append(bank.Balances, banktypes.Balance{
+			Address: sdk.AccAddress("funded______________").String(),
+			Coins:   sdk.NewCoins(sdk.NewInt64Coin("orai", 1000)),
+		})
+	})
+	var wasm wasmtypes.GenesisState
+	updateGenesis(t, cdc, newState, wasmtypes.ModuleName, &wasm, func() {
+		wasm.Codes = append(wasm.Codes, wasmtypes.Code{
+			CodeID:    1,
+			CodeInfo:  wasmtypes.CodeInfo{CodeHash: []byte{1}, Creator: sdk.AccAddress("creator_____________").String()},
+			CodeBytes: []byte("code"),
+		})
+	})
+	var ibc ibccoretypes.GenesisState
+	updateGenesis(t, cdc, newState, ibchost.ModuleName, &ibc, func() {
+		clientState := ibctmtypes.NewClientState("cosmoshub-4", ibctmtypes.DefaultTrustLevel, time.Hour, 2*time.Hour, time.Minute,
+			clienttypes.NewHeight(4, 100), commitmenttypes.GetSDKSpecs(), nil, false, false)
+		ibc.ClientGenesis.Clients = append(ibc.ClientGenesis.Clients, clienttypes.NewIdentifiedClientState("07-tendermint-0", clientState))
+	})
+	newGenFile := writeGenesisFile(t, dir, "new-1", newState)
+
+	diff, err := diffGenesisFiles(app.ModuleBasics, encodingConfig, oldGenFile, newGenFile)
+	require.NoError(t, err)
+	require.Equal(t, &valueChange{Old: "old-1", New: "new-1"}, diff.ChainID)
+	require.Nil(t, diff.InitialHeight)
+
+	modules := make(map[string]moduleDiff)
+	for _, module := range diff.Modules {
+		modules[module.Module] = module
+	}
+	require.Equal(t, "changed", modules[minttypes.ModuleName].Status)
+	require.Empty(t, modules[minttypes.ModuleName].Params)
+	require.Equal(t, []valueChange{{Path: "params.unbonding_time", Old: "7200s", New: "0s"}}, modules[stakingtypes.ModuleName].Params)
+	require.Len(t, modules[stakingtypes.ModuleName].Errors, 1)
+	require.Contains(t, modules[stakingtypes.ModuleName].Errors[0], "invalid new genesis")
+	require.Contains(t, diff.Unchanged, "gov")
+
+	require.Equal(t, []supplyChange{{Denom: "orai", Old: sdk.ZeroInt(), New: sdk.NewInt(1000), Delta: sdk.NewInt(1000)}}, diff.Supply)
+	require.Equal(t, &balancesDiff{Added: 1}, diff.Balances)
+	require.Equal(t, []uint64{1}, diff.Wasm.CodesAdded)
+	require.Empty(t, diff.Wasm.CodesRemoved)
+	require.Equal(t, []string{"07-tendermint-0 (cosmoshub-4)"}, diff.IBCClients.Added)
+
+	// and the other way around
+	diff, err = diffGenesisFiles(app.ModuleBasics, encodingConfig, newGenFile, oldGenFile)
+	require.NoError(t, err)
+	require.Equal(t, &balancesDiff{Removed: 1}, diff.Balances)
+	require.Equal(t, []uint64{1}, diff.Wasm.CodesRemoved)
+	require.Equal(t, []string{"07-tendermint-0 (cosmoshub-4)"}, diff.IBCClients.Removed)
+}
+
+func TestSameParamValue(t *testing.T) {
+	require.True(t, sameParamValue("0.05", "0.050000000000000000"))
+	require.True(t, sameParamValue("100", "100.0"))
+	require.True(t, sameParamValue("1209600s", "336h0m0s"))
+	require.False(t, sameParamValue("0.05", "0.5"))
+	require.False(t, sameParamValue("10s", "1m"))
+	require.False(t, sameParamValue("orai", "uorai"))
+	require.False(t, sameParamValue("", "1"))
+}
@@ -0,0 +1,321 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"time"
+
+	cryptocodec "github.com/cosmos/cosmos-sdk/crypto/codec"
+	"github.com/cosmos/cosmos-sdk/server"
+	servertypes "github.com/cosmos/cosmos-sdk/server/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/version"
+	"github.com/oraichain/orai/app"
+	"github.com/spf13/cobra"
+	tmcfg "github.com/tendermint/tendermint/config"
+	tmjson "github.com/tendermint/tendermint/libs/json"
+	"github.com/tendermint/tendermint/libs/log"
+	tmos "github.com/tendermint/tendermint/libs/os"
+	"github.com/tendermint/tendermint/node"
+	"github.com/tendermint/tendermint/privval"
+	tmproto "github.com/tendermint/tendermint/proto/tendermint/types"
+	sm "github.com/tendermint/tendermint/state"
+	"github.com/tendermint/tendermint/store"
+	tmtypes "github.com/tendermint/tendermint/types"
+	tmtime "github.com/tendermint/tendermint/types/time"
+	dbm "github.com/tendermint/tm-db"
+	ethermintserver "github.com/tharsis/ethermint/server"
+)
+
+// genesisDocKey is the key of the genesis document in the state database of Tendermint
+var genesisDocKey = []byte("genesisDoc")
+
+// genesisDocDB is the state database of Tendermint holding the genesis document
+type genesisDocDB interface {
+	Get([]byte) ([]byte, error)
+	SetSync([]byte, []byte) error
+}
+
+// inPlaceTestnet turns the data of a node into the one of a local testnet run by the node only
+type inPlaceTestnet struct {
+	config  *tmcfg.Config
+	chainID string
+	testnet app.TestnetConfig
+}
+
+// InPlaceTestnetCmd returns a command starting the node as the only validator of a local testnet continuing the chain
+// from its data
+func InPlaceTestnetCmd(ac appCreator, defaultNodeHome string) *cobra.Command {
+	localProfile := app.GenesisProfiles[app.LocalProfile]
+	testnet := &inPlaceTestnet{}
+
+	cmd := ethermintserver.StartCmd(ethermintserver.NewDefaultStartOptions(testnet.appCreator(ac), defaultNodeHome))
+	cmd.Use = "in-place-testnet [chain-id] [operator-address]"
+	cmd.Short = "Start the node as the only validator of a local testnet continuing the chain from its data"
+	cmd.Long = `Start the node as the only validator of a local testnet continuing the chain from its data under chain-id,
+without exporting and importing the state.
+
+The consensus key of the node replaces the one of the validator of operator-address, which must be bonded, and all the
+other validators are jailed. The consensus state is rewritten for the node to sign the last block alone on the new
+chain-id, the sign state of priv_validator_state.json is reset and the node does not connect to its peers. The accounts
+given with --fund receive newly minted coins, and the voting and unbonding periods are shortened.
+
+The data of the node is modified for good: run the command on a copy of it, and restart the testnet with start. The
+changes to the state are committed with the first block of the testnet, the command must be run again on a new copy
+if the node stops before.`
+	cmd.Example = fmt.Sprintf(`$ %s in-place-testnet local-testnet oraivaloper1... --fund orai1...=1000000000orai`, version.AppName)
+	cmd.Args = cobra.ExactArgs(2)
+
+	start := cmd.RunE
+	cmd.RunE = func(cmd *cobra.Command, args []string) error {
+		serverCtx := server.GetServerContextFromCmd(cmd)
+		config := serverCtx.Config
+
+		operator, err := sdk.ValAddressFromBech32(args[1])
+		if err != nil {
+			return err
+		}
+		funds, err := testnetFundsFromFlags(cmd)
+		if err != nil {
+			return err
+		}
+		votingPeriod, _ := cmd.Flags().GetDuration(flagTestnetVotingPeriod)
+		unbondingTime, _ := cmd.Flags().GetDuration(flagTestnetUnbonding)
+
+		state, err := loadConsensusState(config)
+		if err != nil {
+			return err
+		}
+		if state.ChainID == args[0] {
+			return fmt.Errorf("the node is already on chain-id %s, restart it with start", args[0])
+		}
+
+		testnet.config = config
+		testnet.chainID = args[0]
+		testnet.testnet = app.TestnetConfig{
+			Validators:    []sdk.ValAddress{operator},
+			Funds:         funds,
+			VotingPeriod:  votingPeriod,
+			UnbondingTime: unbondingTime,
+		}
+
+		// the node only runs the testnet
+		config.P2P.Seeds = ""
+		config.P2P.PersistentPeers = ""
+		config.P2P.PexReactor = false
+
+		return start(cmd, args)
+	}
+
+	cmd.Flags().StringArray(flagTestnetFund, nil, "Coins minted to an account, as address=coins, can be repeated")
+	cmd.Flags().Duration(flagTestnetVotingPeriod, time.Duration(localProfile.Gov.VotingPeriod), "Voting period of the testnet")
+	cmd.Flags().Duration(flagTestnetUnbonding, time.Duration(localProfile.Staking.UnbondingTime), "Unbonding time of the testnet")
+	addModuleInitFlags(cmd)
+
+	return cmd
+}
+
+// appCreator returns an app creator turning the state of the app into the one of the testnet, and the consensus state
+// of the node accordingly
+func (t *inPlaceTestnet) appCreator(ac appCreator) servertypes.AppCreator {
+	return func(logger log.Logger, db dbm.DB, traceStore io.Writer, appOpts servertypes.AppOptions) servertypes.Application {
+		oraichainApp := ac.newApp(logger, db, traceStore, appOpts).(*app.OraichainApp)
+		if err := t.apply(logger, oraichainApp); err != nil {
+			panic(fmt.Errorf("failed to create the in-place testnet: %w", err))
+		}
+		return oraichainApp
+	}
+}
+
+// apply makes the node the only validator of the testnet. Everything is checked before the data of the node is
+// written, the consensus state last, so that a failure leaves the data of the node untouched.
+func (t *inPlaceTestnet) apply(logger log.Logger, oraichainApp *app.OraichainApp) error {
+	stateDB, err := node.DefaultDBProvider(&node.DBContext{ID: "state", Config: t.config})
+	if err != nil {
+		return err
+	}
+	defer stateDB.Close()
+	blockStoreDB, err := node.DefaultDBProvider(&node.DBContext{ID: "blockstore", Config: t.config})
+	if err != nil {
+		return err
+	}
+	defer blockStoreDB.Close()
+
+	stateStore := sm.NewStore(stateDB, sm.StoreOptions{DiscardABCIResponses: false})
+	state, err := stateStore.Load()
+	if err != nil {
+		return err
+	}
+	height := state.LastBlockHeight
+	if oraichainApp.LastBlockHeight() != height {
+		return fmt.Errorf("the application is at height %d and the consensus state at height %d, start the node once to sync them",
+			oraichainApp.LastBlockHeight(), height)
+	}
+	blockStore := store.NewBlockStore(blockStoreDB)
+	blockMeta := blockStore.LoadBlockMeta(height)
+	if blockMeta == nil {
+		return fmt.Errorf("block %d not found", height)
+	}
+
+	keyFile, signStateFile := t.config.PrivValidatorKeyFile(), t.config.PrivValidatorStateFile()
+	if !tmos.FileExists(keyFile) || !tmos.FileExists(signStateFile) {
+		return fmt.Errorf("the validator key %s and its sign state %s must exist", keyFile, signStateFile)
+	}
+	pv := privval.LoadFilePV(keyFile, signStateFile)
+	pubKey, err := cryptocodec.FromTmPubKeyInterface(pv.Key.PubKey)
+	if err != nil {
+		return err
+	}
+	t.testnet.ConsensusKeys = append(t.testnet.ConsensusKeys[:0], pubKey)
+
+	// the state of the app is only committed with the first block of the testnet
+	genesisValidators, err := oraichainApp.InitInPlaceTestnet(state.LastBlockTime, t.testnet)
+	if err != nil {
+		return err
+	}
+	validators := make([]*tmtypes.Validator, len(genesisValidators))
+	for i, validator := range genesisValidators {
+		validators[i] = tmtypes.NewValidator(validator.PubKey, validator.Power)
+	}
+	validatorSet := tmtypes.NewValidatorSet(validators)
+
+	// the next block carries the commit of the last one, signed again by the node for the new chain-id
+	commit, err := signCommit(t.chainID, pv, validatorSet, blockMeta.BlockID, height, state.LastBlockTime)
+	if err != nil {
+		return err
+	}
+	genesis, err := t.testnetGenesis(stateDB)
+	if err != nil {
+		return err
+	}
+
+	if err := genesis.save(stateDB); err != nil {
+		return err
+	}
+	if err := blockStore.SaveSeenCommit(height, commit); err != nil {
+		return err
+	}
+
+	state.ChainID = t.chainID
+	state.LastValidators = validatorSet.Copy()
+	state.Validators = validatorSet.Copy()
+	state.NextValidators = validatorSet.Copy()
+	state.LastHeightValidatorsChanged = height + 1
+	// unlike Save, Bootstrap also writes the validators of the last height, which sign the commit of the next block
+	if err := stateStore.Bootstrap(state); err != nil {
+		return err
+	}
+
+	// the node signs the blocks of the testnet from its next height, whatever it signed before
+	pv.Reset()
+
+	logger.Info("created in-place testnet", "chain_id", t.chainID, "height", height, "validator", pv.Key.Address)
+	return nil
+}
+
+// testnetGenesis is the genesis document of the node with the chain-id of the testnet, as saved in the state database
+// of the node and in its genesis file
+type testnetGenesis struct {
+	// stateDBDoc is nil if the node did not save its genesis document
+	stateDBDoc []byte
+	// fileDoc is nil if the node has no genesis file
+	fileDoc *tmtypes.GenesisDoc
+	genFile string
+}
+
+// testnetGenesis returns the genesis documents of the node with the chain-id of the testnet
+func (t *inPlaceTestnet) testnetGenesis(stateDB genesisDocDB) (testnetGenesis, error) {
+	genesis := testnetGenesis{genFile: t.config.GenesisFile()}
+
+	bz, err := stateDB.Get(genesisDocKey)
+	if err != nil {
+		return genesis, err
+	}
+	if len(bz) > 0 {
+		var genDoc tmtypes.GenesisDoc
+		if err := tmjson.Unmarshal(bz, &genDoc); err != nil {
+			return genesis, err
+		}
+		genDoc.ChainID = t.chainID
+		if genesis.stateDBDoc, err = tmjson.Marshal(&genDoc); err != nil {
+			return genesis, err
+		}
+	}
+
+	if tmos.FileExists(genesis.genFile) {
+		if genesis.fileDoc, err = tmtypes.GenesisDocFromFile(genesis.genFile); err != nil {
+			return genesis, err
+		}
+		genesis.fileDoc.ChainID = t.chainID
+	}
+	return genesis, nil
+}
+
+// save writes the genesis documents to the state database of the node and to its genesis file
+func (g testnetGenesis) save(stateDB genesisDocDB) error {
+	if g.stateDBDoc != nil {
+		if err := stateDB.SetSync(genesisDocKey, g.stateDBDoc); err != nil {
+			return err
+		}
+	}
+	if g.fileDoc != nil {
+		return g.fileDoc.SaveAs(g.genFile)
+	}
+	return nil
+}
+
+// signCommit returns the commit of the block blockID at height signed by pv for chainID, the other validators of
+// validatorSet being absent
+func signCommit(chainID string, pv *privval.FilePV, validatorSet *tmtypes.ValidatorSet, blockID tmtypes.BlockID, height int64, lastBlockTime time.Time) (*tmtypes.Commit, error) {
+	// the time of the next block is the one of the commit, which must follow the last block
+	timestamp := tmtime.Now()
+	if !timestamp.After(lastBlockTime) {
+		timestamp = lastBlockTime.Add(time.Second)
+	}
+
+	signatures := make([]tmtypes.CommitSig, len(validatorSet.Validators))
+	for i, validator := range validatorSet.Validators {
+		if !bytes.Equal(validator.Address, pv.Key.Address) {
+			signatures[i] = tmtypes.NewCommitSigAbsent()
+			continue
+		}
+		vote := &tmproto.Vote{
+			Type:             tmproto.PrecommitType,
+			Height:           height,
+			BlockID:          blockID.ToProto(),
+			Timestamp:        timestamp,
+			ValidatorAddress: validator.Address,
+			ValidatorIndex:   int32(i),
+		}
+		signature, err := pv.Key.PrivKey.Sign(tmtypes.VoteSignBytes(chainID, vote))
+		if err != nil {
+			return nil, err
+		}
+		signatures[i] = tmtypes.CommitSig{
+			BlockIDFlag:      tmtypes.BlockIDFlagCommit,
+			ValidatorAddress: validator.Address,
+			Timestamp:        timestamp,
+			Signature:        signature,
+		}
+	}
+	return tmtypes.NewCommit(height, 0, blockID, signatures), nil
+}
+
+// loadConsensusState returns the consensus state of the node, which must have committed blocks
+func loadConsensusState(config *tmcfg.Config) (sm.State, error) {
+	stateDB, err := node.DefaultDBProvider(&node.DBContext{ID: "state", Config: config})
+	if err != nil {
+		return sm.State{}, err
+	}
+	defer stateDB.Close()
+
+	state, err := sm.NewStore(stateDB, sm.StoreOptions{DiscardABCIResponses: false}).Load()
+	if err != nil {
+		return sm.State{}, err
+	}
+	if state.IsEmpty() || state.LastBlockHeight == 0 {
+		return sm.State{}, fmt.Errorf("no block committed in %s", config.DBDir())
+	}
+	return state, nil
+}
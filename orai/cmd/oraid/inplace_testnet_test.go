@@ -0,0 +1,226 @@
+package main
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/cosmos/cosmos-sdk/client/flags"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/oraichain/orai/app"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/require"
+	tmcfg "github.com/tendermint/tendermint/config"
+	tmjson "github.com/tendermint/tendermint/libs/json"
+	"github.com/tendermint/tendermint/libs/log"
+	"github.com/tendermint/tendermint/node"
+	"github.com/tendermint/tendermint/p2p"
+	"github.com/tendermint/tendermint/privval"
+	"github.com/tendermint/tendermint/proxy"
+	sm "github.com/tendermint/tendermint/state"
+	"github.com/tendermint/tendermint/store"
+	tmtypes "github.com/tendermint/tendermint/types"
+)
+
+// copyDir copies the files of src to dst
+func copyDir(t *testing.T, src, dst string) {
+	err := filepath.Walk(src, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dst, rel)
+		if info.IsDir() {
+			return os.MkdirAll(target, info.Mode())
+		}
+
+		in, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer in.Close()
+		out, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, info.Mode())
+		if err != nil {
+			return err
+		}
+		if _, err := io.Copy(out, in); err != nil {
+			out.Close()
+			return err
+		}
+		return out.Close()
+	})
+	require.NoError(t, err)
+}
+
+// loadNodeConfig returns the configuration of the node of home and its app options
+func loadNodeConfig(t *testing.T, home string) (*tmcfg.Config, *viper.Viper) {
+	v := viper.New()
+	v.Set(flags.FlagHome, home)
+	v.SetConfigFile(filepath.Join(home, "config", "config.toml"))
+	require.NoError(t, v.ReadInConfig())
+	v.SetConfigFile(filepath.Join(home, "config", "app.toml"))
+	require.NoError(t, v.MergeInConfig())
+
+	config := tmcfg.DefaultConfig()
+	require.NoError(t, v.Unmarshal(config))
+	config.SetRoot(home)
+	config.Instrumentation.Prometheus = false
+	return config, v
+}
+
+// consensusData is the data of the node read back after the in-place testnet was applied
+type consensusData struct {
+	state          sm.State
+	lastValidators *tmtypes.ValidatorSet
+	blockID        tmtypes.BlockID
+	seenCommit     *tmtypes.Commit
+	genesisDoc     tmtypes.GenesisDoc
+}
+
+// loadConsensusData reads the consensus state, the seen commit of height and the saved genesis document of the node
+func loadConsensusData(t *testing.T, config *tmcfg.Config, height int64) consensusData {
+	stateDB, err := node.DefaultDBProvider(&node.DBContext{ID: "state", Config: config})
+	require.NoError(t, err)
+	defer stateDB.Close()
+	blockStoreDB, err := node.DefaultDBProvider(&node.DBContext{ID: "blockstore", Config: config})
+	require.NoError(t, err)
+	defer blockStoreDB.Close()
+
+	var data consensusData
+	stateStore := sm.NewStore(stateDB, sm.StoreOptions{DiscardABCIResponses: false})
+	data.state, err = stateStore.Load()
+	require.NoError(t, err)
+	data.lastValidators, err = stateStore.LoadValidators(height)
+	require.NoError(t, err)
+
+	blockStore := store.NewBlockStore(blockStoreDB)
+	data.blockID = blockStore.LoadBlockMeta(height).BlockID
+	data.seenCommit = blockStore.LoadSeenCommit(height)
+
+	bz, err := stateDB.Get(genesisDocKey)
+	require.NoError(t, err)
+	require.NoError(t, tmjson.Unmarshal(bz, &data.genesisDoc))
+	return data
+}
+
+func TestInPlaceTestnet(t *testing.T) {
+	logger := log.NewNopLogger()
+	clientCtx := newTestClientContext()
+	ac := appCreator{encCfg: app.MakeEncodingConfig()}
+	cfg := newTestnetFilesConfig(t.TempDir())
+	cfg.PortOffset = 31000
+	nodes, err := initTestnetFiles(clientCtx, nil, cfg)
+	require.NoError(t, err)
+
+	testnet, err := startLocalTestnet(logger, clientCtx, ac, testnetHomes(cfg.OutputDir))
+	require.NoError(t, err)
+	require.Eventually(t, func() bool {
+		return testnet.nodes[0].tmNode.BlockStore().Height() >= 3
+	}, time.Minute, 100*time.Millisecond)
+	testnet.stop()
+
+	// the testnet runs on a copy of the data of the node
+	home := filepath.Join(t.TempDir(), "node0")
+	copyDir(t, nodes[0].Home, home)
+	config, appOpts := loadNodeConfig(t, home)
+	state, err := loadConsensusState(config)
+	require.NoError(t, err)
+	height := state.LastBlockHeight
+	localProfile := app.GenesisProfiles[app.LocalProfile]
+	testnetConfig := app.TestnetConfig{
+		Validators:    []sdk.ValAddress{sdk.ValAddress(nodes[0].Address)},
+		VotingPeriod:  time.Duration(localProfile.Gov.VotingPeriod),
+		UnbondingTime: time.Duration(localProfile.Staking.UnbondingTime),
+	}
+
+	// a failure leaves the data of the node untouched, the app of another copy failing to create the testnet as the
+	// app does not release the data of its home
+	failingHome := filepath.Join(t.TempDir(), "node0")
+	copyDir(t, nodes[0].Home, failingHome)
+	failingConfig, failingAppOpts := loadNodeConfig(t, failingHome)
+	failing := &inPlaceTestnet{config: failingConfig, chainID: "in-place", testnet: testnetConfig}
+	failing.testnet.Validators = []sdk.ValAddress{sdk.ValAddress("unknown_validator___")}
+	failingDB, err := openApplicationDB(failingConfig)
+	require.NoError(t, err)
+	defer failingDB.Close()
+	failingApp := ac.newOraichainApp(logger, failingDB, nil, failingAppOpts)
+	require.ErrorContains(t, failing.apply(logger, failingApp), "not found")
+
+	// the genesis file is only read once the state of the app is changed
+	genesisFile, err := os.ReadFile(failingConfig.GenesisFile())
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(failingConfig.GenesisFile(), []byte("{"), 0o600))
+	failing.testnet.Validators = testnetConfig.Validators
+	require.Error(t, failing.apply(logger, failingApp))
+	require.NoError(t, os.WriteFile(failingConfig.GenesisFile(), genesisFile, 0o600))
+
+	data := loadConsensusData(t, failingConfig, height)
+	require.Equal(t, "testing", data.state.ChainID)
+	require.Equal(t, "testing", data.genesisDoc.ChainID)
+	require.Len(t, data.state.Validators.Validators, 2)
+	require.NoError(t, data.lastValidators.VerifyCommit("testing", data.blockID, height, data.seenCommit))
+	genDoc, err := tmtypes.GenesisDocFromFile(failingConfig.GenesisFile())
+	require.NoError(t, err)
+	require.Equal(t, "testing", genDoc.ChainID)
+	require.NotZero(t, privval.LoadFilePV(failingConfig.PrivValidatorKeyFile(), failingConfig.PrivValidatorStateFile()).LastSignState.Height)
+
+	inPlace := &inPlaceTestnet{config: config, chainID: "in-place", testnet: testnetConfig}
+	db, err := openApplicationDB(config)
+	require.NoError(t, err)
+	defer db.Close()
+	oraichainApp := ac.newOraichainApp(logger, db, nil, appOpts)
+	require.NoError(t, inPlace.apply(logger, oraichainApp))
+
+	// the node alone signs the last block again for the new chain-id
+	pv := privval.LoadFilePV(config.PrivValidatorKeyFile(), config.PrivValidatorStateFile())
+	require.Zero(t, pv.LastSignState.Height)
+	data = loadConsensusData(t, config, height)
+	require.Equal(t, "in-place", data.state.ChainID)
+	require.Equal(t, "in-place", data.genesisDoc.ChainID)
+	require.Len(t, data.state.Validators.Validators, 1)
+	require.Equal(t, pv.Key.Address, data.state.Validators.Validators[0].Address)
+	require.Equal(t, data.state.Validators.Hash(), data.state.NextValidators.Hash())
+	require.Equal(t, data.state.Validators.Hash(), data.lastValidators.Hash())
+	require.Equal(t, height+1, data.state.LastHeightValidatorsChanged)
+	require.NoError(t, data.lastValidators.VerifyCommit("in-place", data.blockID, height, data.seenCommit))
+	require.Error(t, data.lastValidators.VerifyCommit("testing", data.blockID, height, data.seenCommit))
+	genDoc, err = tmtypes.GenesisDocFromFile(config.GenesisFile())
+	require.NoError(t, err)
+	require.Equal(t, "in-place", genDoc.ChainID)
+
+	// and produces the blocks of the testnet, without its former peer
+	config.P2P.PersistentPeers = ""
+	config.P2P.PexReactor = false
+	config.RPC.ListenAddress = ""
+	nodeKey, err := p2p.LoadNodeKey(config.NodeKeyFile())
+	require.NoError(t, err)
+	tmNode, err := node.NewNode(
+		config,
+		pv,
+		nodeKey,
+		proxy.NewLocalClientCreator(oraichainApp),
+		node.DefaultGenesisDocProviderFunc(config),
+		node.DefaultDBProvider,
+		node.DefaultMetricsProvider(config.Instrumentation),
+		logger,
+	)
+	require.NoError(t, err)
+	require.NoError(t, tmNode.Start())
+	defer func() {
+		require.NoError(t, tmNode.Stop())
+		tmNode.Wait()
+	}()
+
+	require.Eventually(t, func() bool {
+		return tmNode.BlockStore().Height() >= height+3
+	}, time.Minute, 100*time.Millisecond)
+	block := tmNode.BlockStore().LoadBlock(height + 1)
+	require.Equal(t, "in-place", block.ChainID)
+	require.NoError(t, data.lastValidators.VerifyCommit("in-place", data.blockID, height, block.LastCommit))
+	require.Greater(t, oraichainApp.LastBlockHeight(), height)
+}
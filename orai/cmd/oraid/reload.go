@@ -0,0 +1,33 @@
+package main
+
+import (
+	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+
+	servertypes "github.com/cosmos/cosmos-sdk/server/types"
+	"github.com/spf13/viper"
+	"github.com/tendermint/tendermint/libs/log"
+)
+
+// reloadOnSignal re-reads app.toml each time the node receives SIGHUP and passes it to reload.
+// Only node-local settings may be reloaded this way, as they do not affect consensus.
+func reloadOnSignal(logger log.Logger, homeDir string, reload func(appOpts servertypes.AppOptions) error) {
+	sigs := make(chan os.Signal, 1)
+	signal.Notify(sigs, syscall.SIGHUP)
+
+	go func() {
+		for range sigs {
+			v := viper.New()
+			v.SetConfigFile(filepath.Join(homeDir, "config", "app.toml"))
+			if err := v.ReadInConfig(); err != nil {
+				logger.Error("failed to read app.toml on SIGHUP", "error", err)
+				continue
+			}
+			if err := reload(v); err != nil {
+				logger.Error("failed to reload app.toml on SIGHUP", "error", err)
+			}
+		}
+	}()
+}
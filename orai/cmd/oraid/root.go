@@ -7,9 +7,12 @@ import (
 	"io"
 	"os"
 	"path/filepath"
+	"sort"
+	"strings"
 	"time"
 
 	clientconfig "github.com/cosmos/cosmos-sdk/client/config"
+	"github.com/cosmos/cosmos-sdk/codec"
 	srvconfig "github.com/cosmos/cosmos-sdk/server/config"
 	"github.com/pkg/errors"
 	cfg "github.com/tendermint/tendermint/config"
@@ -48,7 +51,9 @@ import (
 	banktypes "github.com/cosmos/cosmos-sdk/x/bank/types"
 	"github.com/cosmos/cosmos-sdk/x/crisis"
 	genutilcli "github.com/cosmos/cosmos-sdk/x/genutil/client/cli"
+	genutiltypes "github.com/cosmos/cosmos-sdk/x/genutil/types"
 	"github.com/oraichain/orai/app"
+	customante "github.com/oraichain/orai/app/ante"
 	"github.com/oraichain/orai/app/params"
 	"github.com/oraichain/orai/cmd"
 	"github.com/prometheus/client_golang/prometheus"
@@ -67,6 +72,12 @@ const (
 
 	flagMempoolEnableAuth    = "mempool.enable-authentication"
 	flagMempoolAuthAddresses = "mempool.authorized-addresses"
+	flagMempoolIBCPriority   = "mempool.ibc-relay-priority"
+	flagMempoolPolicyFile    = "mempool.policy-file"
+	flagAntePanicDumpDir     = "ante.panic-dump-dir"
+
+	flagGenesisProfile     = "profile"
+	flagGenesisProfileFile = "profile-file"
 )
 
 // NewRootCmd creates a new root command for wasmd. It is called once in the
@@ -124,22 +135,30 @@ func NewRootCmd() (*cobra.Command, params.EncodingConfig) {
 }
 
 func initRootCmd(rootCmd *cobra.Command, encodingConfig params.EncodingConfig) {
-	initCommand := initCmd(app.ModuleBasics, app.NewDefaultGenesisState(encodingConfig.Codec), app.DefaultNodeHome)
+	ac := appCreator{
+		encCfg: encodingConfig,
+	}
+
+	debugCmd := debug.Cmd()
+	debugCmd.AddCommand(ReplayTxCmd(ac), CheckStateCmd(ac))
+
+	initCommand := initCmd(app.ModuleBasics, encodingConfig.Codec, app.DefaultNodeHome)
 	rootCmd.AddCommand(
 		initCommand,
 		genutilcli.CollectGenTxsCmd(banktypes.GenesisBalancesIterator{}, app.DefaultNodeHome),
 		genutilcli.MigrateGenesisCmd(),
 		genutilcli.GenTxCmd(app.ModuleBasics, encodingConfig.TxConfig, banktypes.GenesisBalancesIterator{}, app.DefaultNodeHome),
-		genutilcli.ValidateGenesisCmd(app.ModuleBasics),
+		ValidateGenesisCmd(app.ModuleBasics),
 		AddGenesisAccountCmd(app.DefaultNodeHome),
+		AddGenesisAccountsCmd(app.DefaultNodeHome),
+		ExportStreamCmd(ac, app.DefaultNodeHome),
+		ExportTestnetCmd(ac, app.DefaultNodeHome),
+		InPlaceTestnetCmd(ac, app.DefaultNodeHome),
+		GenesisCmd(app.ModuleBasics, encodingConfig),
 		tmcli.NewCompletionCmd(rootCmd, true),
-		// testnetCmd(app.ModuleBasics, banktypes.GenesisBalancesIterator{}),
-		debug.Cmd(),
+		TestnetCmd(ac),
+		debugCmd,
 	)
-
-	ac := appCreator{
-		encCfg: encodingConfig,
-	}
 	// ethermintserver adds additional flags to start the JSON-RPC server for evm support
 	ethermintserver.AddCommands(rootCmd, ethermintserver.NewDefaultStartOptions(ac.newApp, app.DefaultNodeHome), ac.createOraichainAppAndExport, addModuleInitFlags)
 
@@ -180,6 +199,7 @@ func queryCommand() *cobra.Command {
 	)
 
 	app.ModuleBasics.AddQueryCommands(cmd)
+	cmd.AddCommand(SponsorshipQueryCmd())
 	cmd.PersistentFlags().String(flags.FlagChainID, "", "The network chain ID")
 
 	return cmd
@@ -227,7 +247,32 @@ func txCommand() *cobra.Command {
 	return cmd
 }
 
+// newApp creates the app run by start, whose node-local mempool settings are reloaded from app.toml on SIGHUP
 func (ac appCreator) newApp(logger log.Logger, db dbm.DB, traceStore io.Writer, appOpts servertypes.AppOptions) servertypes.Application {
+	oraichainApp := ac.newOraichainApp(logger, db, traceStore, appOpts)
+
+	homeDir := cast.ToString(appOpts.Get(flags.FlagHome))
+	reloadOnSignal(logger, homeDir, func(appOpts servertypes.AppOptions) error {
+		enabled, addresses, err := mempoolAllowlistFromConfig(appOpts)
+		if err != nil {
+			return err
+		}
+		blocked, err := addressPolicyFromConfig(appOpts, homeDir)
+		if err != nil {
+			return err
+		}
+
+		oraichainApp.UpdateMempoolAllowlist(enabled, addresses)
+		oraichainApp.UpdateAddressPolicy(blocked)
+		logger.Info("reloaded mempool policies", "auth_enabled", enabled, "authorized", len(addresses), "blocked", len(blocked))
+		return nil
+	})
+
+	return oraichainApp
+}
+
+// newOraichainApp creates the app of the node from appOpts
+func (ac appCreator) newOraichainApp(logger log.Logger, db dbm.DB, traceStore io.Writer, appOpts servertypes.AppOptions) *app.OraichainApp {
 	var cache sdk.MultiStorePersistentCache
 
 	if cast.ToBool(appOpts.Get(server.FlagInterBlockCache)) {
@@ -259,15 +304,17 @@ func (ac appCreator) newApp(logger log.Logger, db dbm.DB, traceStore io.Writer,
 		wasmOpts = append(wasmOpts, wasmkeeper.WithVMCacheMetrics(prometheus.DefaultRegisterer))
 	}
 
-	mempoolEnableAuth := cast.ToBool(appOpts.Get(flagMempoolEnableAuth))
-	mempoolAuthAddresses, err := accAddressesFromBech32(
-		cast.ToStringSlice(appOpts.Get(flagMempoolAuthAddresses))...,
-	)
+	homeDir := cast.ToString(appOpts.Get(flags.FlagHome))
+	mempoolEnableAuth, mempoolAuthAddresses, err := mempoolAllowlistFromConfig(appOpts)
 	if err != nil {
-		panic(fmt.Sprintf("could not get authorized address from config: %v", err))
+		panic(err)
+	}
+	mempoolBlockedAddresses, err := addressPolicyFromConfig(appOpts, homeDir)
+	if err != nil {
+		panic(err)
 	}
 
-	return app.NewOraichainApp(logger, db, traceStore, true, skipUpgradeHeights,
+	oraichainApp := app.NewOraichainApp(logger, db, traceStore, true, skipUpgradeHeights,
 		cast.ToString(appOpts.Get(flags.FlagHome)),
 		cast.ToUint(appOpts.Get(server.FlagInvCheckPeriod)),
 		ac.encCfg,
@@ -275,10 +322,13 @@ func (ac appCreator) newApp(logger log.Logger, db dbm.DB, traceStore io.Writer,
 		appOpts,
 		wasmOpts,
 		app.EvmOptions{
-			MempoolEnableAuth:    mempoolEnableAuth,
-			MempoolAuthAddresses: mempoolAuthAddresses,
-			EVMTrace:             cast.ToString(appOpts.Get(ethermintflags.EVMTracer)),
-			EVMMaxGasWanted:      cast.ToUint64(appOpts.Get(ethermintflags.EVMMaxTxGasWanted)),
+			MempoolEnableAuth:       mempoolEnableAuth,
+			MempoolAuthAddresses:    mempoolAuthAddresses,
+			MempoolIBCRelayPriority: cast.ToBool(appOpts.Get(flagMempoolIBCPriority)),
+			MempoolBlockedAddresses: mempoolBlockedAddresses,
+			AntePanicDumpDir:        cast.ToString(appOpts.Get(flagAntePanicDumpDir)),
+			EVMTrace:                cast.ToString(appOpts.Get(ethermintflags.EVMTracer)),
+			EVMMaxGasWanted:         cast.ToUint64(appOpts.Get(ethermintflags.EVMMaxTxGasWanted)),
 		},
 		baseapp.SetPruning(pruningOpts),
 		baseapp.SetMinGasPrices(cast.ToString(appOpts.Get(server.FlagMinGasPrices))),
@@ -292,6 +342,32 @@ func (ac appCreator) newApp(logger log.Logger, db dbm.DB, traceStore io.Writer,
 		baseapp.SetSnapshotInterval(cast.ToUint64(appOpts.Get(server.FlagStateSyncSnapshotInterval))),
 		baseapp.SetSnapshotKeepRecent(cast.ToUint32(appOpts.Get(server.FlagStateSyncSnapshotKeepRecent))),
 	)
+
+	return oraichainApp
+}
+
+// mempoolAllowlistFromConfig reads the mempool authentication settings
+func mempoolAllowlistFromConfig(appOpts servertypes.AppOptions) (bool, []sdk.AccAddress, error) {
+	addresses, err := accAddressesFromBech32(
+		cast.ToStringSlice(appOpts.Get(flagMempoolAuthAddresses))...,
+	)
+	if err != nil {
+		return false, nil, fmt.Errorf("could not get authorized address from config: %v", err)
+	}
+	return cast.ToBool(appOpts.Get(flagMempoolEnableAuth)), addresses, nil
+}
+
+// addressPolicyFromConfig reads the blocked addresses of the mempool policy file, relative to the home
+// directory unless absolute
+func addressPolicyFromConfig(appOpts servertypes.AppOptions, homeDir string) ([]sdk.AccAddress, error) {
+	policyFile := cast.ToString(appOpts.Get(flagMempoolPolicyFile))
+	if policyFile == "" {
+		return nil, nil
+	}
+	if !filepath.IsAbs(policyFile) {
+		policyFile = filepath.Join(homeDir, policyFile)
+	}
+	return customante.LoadAddressPolicyFile(policyFile)
 }
 
 func (ac appCreator) createOraichainAppAndExport(
@@ -304,10 +380,27 @@ func (ac appCreator) createOraichainAppAndExport(
 	appOpts servertypes.AppOptions,
 ) (servertypes.ExportedApp, error) {
 
+	wasmApp, err := ac.loadOraichainApp(logger, db, traceStore, height, appOpts)
+	if err != nil {
+		return servertypes.ExportedApp{}, err
+	}
+
+	return wasmApp.ExportAppStateAndValidators(forZeroHeight, jailAllowedAddrs)
+}
+
+// loadOraichainApp creates an app loaded at height, the latest one when height is -1
+func (ac appCreator) loadOraichainApp(
+	logger log.Logger,
+	db dbm.DB,
+	traceStore io.Writer,
+	height int64,
+	appOpts servertypes.AppOptions,
+) (*app.OraichainApp, error) {
+
 	var wasmApp *app.OraichainApp
 	homePath, ok := appOpts.Get(flags.FlagHome).(string)
 	if !ok || homePath == "" {
-		return servertypes.ExportedApp{}, errors.New("application home is not set")
+		return nil, errors.New("application home is not set")
 	}
 
 	loadLatest := height == -1
@@ -330,21 +423,26 @@ func (ac appCreator) createOraichainAppAndExport(
 
 	if height != -1 {
 		if err := wasmApp.LoadHeight(height); err != nil {
-			return servertypes.ExportedApp{}, err
+			return nil, err
 		}
 	}
 
-	return wasmApp.ExportAppStateAndValidators(forZeroHeight, jailAllowedAddrs)
+	return wasmApp, nil
 }
 
 // initCmd returns a command that initializes all files needed for Tendermint
 // and the respective application.
-func initCmd(_ module.BasicManager, customAppState app.GenesisState, defaultNodeHome string) *cobra.Command {
+func initCmd(_ module.BasicManager, cdc codec.Codec, defaultNodeHome string) *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "init [moniker]",
 		Short: "Initialize private validator, p2p, genesis, and application configuration files",
-		Long:  `Initialize validators's and node's configuration files.`,
-		Args:  cobra.ExactArgs(1),
+		Long: `Initialize validators's and node's configuration files.
+
+The params of the genesis state are set by a profile among mainnet, testnet and local, their
+values being overridden by the ones of a json profile file given with --profile-file, such as:
+
+{"staking": {"unbonding_time": "336h"}, "gov": {"voting_period": "72h"}}`,
+		Args: cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			clientCtx := client.GetClientContextFromCmd(cmd)
 
@@ -380,11 +478,7 @@ func initCmd(_ module.BasicManager, customAppState app.GenesisState, defaultNode
 			}
 
 			config.Moniker = args[0]
-			config.P2P.MaxNumInboundPeers = 100
-			config.P2P.MaxNumOutboundPeers = 100
-			config.P2P.FlushThrottleTimeout = 10 * time.Millisecond
-			config.Consensus.TimeoutCommit = 500 * time.Millisecond
-			config.Consensus.PeerGossipSleepDuration = 10 * time.Millisecond
+			setNodeConfigDefaults(config)
 
 			genFile := config.GenesisFile()
 			overwrite, _ := cmd.Flags().GetBool(FlagOverwrite)
@@ -392,7 +486,13 @@ func initCmd(_ module.BasicManager, customAppState app.GenesisState, defaultNode
 			if !overwrite && tmos.FileExists(genFile) {
 				return fmt.Errorf("genesis.json file already exists: %v", genFile)
 			}
-			appState, err := json.MarshalIndent(customAppState, "", " ")
+			profileName, _ := cmd.Flags().GetString(flagGenesisProfile)
+			profileFile, _ := cmd.Flags().GetString(flagGenesisProfileFile)
+			profile, err := app.LoadGenesisProfile(profileName, profileFile)
+			if err != nil {
+				return err
+			}
+			appState, err := json.MarshalIndent(app.NewGenesisStateFromProfile(cdc, profile), "", " ")
 			if err != nil {
 				return errors.Wrap(err, "Failed to marshall default genesis state")
 			}
@@ -433,10 +533,96 @@ func initCmd(_ module.BasicManager, customAppState app.GenesisState, defaultNode
 	cmd.Flags().BoolP(FlagOverwrite, "o", false, "overwrite the genesis.json file")
 	cmd.Flags().Bool(FlagRecover, false, "provide seed phrase to recover existing key instead of creating")
 	cmd.Flags().String(flags.FlagChainID, "", "genesis file chain-id, if left blank will be randomly created")
+	cmd.Flags().String(flagGenesisProfile, app.TestnetProfile, fmt.Sprintf("genesis params profile (%s)", strings.Join(app.GenesisProfileNames(), "|")))
+	cmd.Flags().String(flagGenesisProfileFile, "", "json file of genesis params overriding the ones of the profile")
 
 	return cmd
 }
 
+// setNodeConfigDefaults sets the peering and consensus settings of the nodes created by the app
+func setNodeConfigDefaults(config *cfg.Config) {
+	config.P2P.MaxNumInboundPeers = 100
+	config.P2P.MaxNumOutboundPeers = 100
+	config.P2P.FlushThrottleTimeout = 10 * time.Millisecond
+	config.Consensus.TimeoutCommit = 500 * time.Millisecond
+	config.Consensus.PeerGossipSleepDuration = 10 * time.Millisecond
+}
+
+// ValidateGenesisCmd returns the validate-genesis command of genutil, which also checks the consistency of the genesis
+// state across modules and warns when the params of a mainnet chain are weaker than the ones of the mainnet profile
+func ValidateGenesisCmd(mbm module.BasicManager) *cobra.Command {
+	cmd := genutilcli.ValidateGenesisCmd(mbm)
+	cmd.Long = `Validate the genesis file at the default location or at the location passed as an arg.
+
+Besides the validation of the genesis state of each module, the consistency of the state across modules is checked:
+the supply equals the sum of the balances, the staking pools hold the tokens of the validators and unbonding
+delegations, the evm denom is the one of the app, the IBC vouchers have a denom trace, the wasm contracts have an
+account and the vesting accounts hold their coins locked at the genesis time. With --output json, the report is
+printed as json.`
+	cmd.RunE = func(cmd *cobra.Command, args []string) error {
+		output, _ := cmd.Flags().GetString(tmcli.OutputFlag)
+		if output != "text" && output != "json" {
+			return fmt.Errorf("unsupported output %s, expected text or json", output)
+		}
+
+		serverCtx := server.GetServerContextFromCmd(cmd)
+		clientCtx := client.GetClientContextFromCmd(cmd)
+		genesis := serverCtx.Config.GenesisFile()
+		if len(args) > 0 {
+			genesis = args[0]
+		}
+		genState, genDoc, err := genutiltypes.GenesisStateFromGenFile(genesis)
+		if err != nil {
+			return err
+		}
+
+		report := app.ValidateGenesisState(mbm, clientCtx.Codec, clientCtx.TxConfig, genState, genDoc.GenesisTime)
+		if app.IsMainnetChainID(genDoc.ChainID) {
+			for _, warning := range app.MainnetProfileWarnings(clientCtx.Codec, genState) {
+				report.Warnings = append(report.Warnings, fmt.Sprintf("%s is a mainnet chain-id but %s", genDoc.ChainID, warning))
+			}
+		}
+
+		if output == "json" {
+			bz, err := json.MarshalIndent(report, "", "  ")
+			if err != nil {
+				return err
+			}
+			cmd.Println(string(bz))
+		} else {
+			printGenesisReport(cmd, report)
+		}
+		if !report.Valid {
+			return fmt.Errorf("%s is not a valid genesis file", genesis)
+		}
+		if output == "text" {
+			cmd.Printf("File at %s is a valid genesis file\n", genesis)
+		}
+		return nil
+	}
+	cmd.Flags().String(tmcli.OutputFlag, "text", "Output format (text|json)")
+	return cmd
+}
+
+func printGenesisReport(cmd *cobra.Command, report *app.GenesisReport) {
+	modules := make([]string, 0, len(report.ModuleErrors))
+	for module := range report.ModuleErrors {
+		modules = append(modules, module)
+	}
+	sort.Strings(modules)
+	for _, module := range modules {
+		cmd.PrintErrf("ERROR: invalid %s genesis: %s\n", module, report.ModuleErrors[module])
+	}
+	for _, check := range report.Checks {
+		for _, err := range check.Errors {
+			cmd.PrintErrf("ERROR: %s: %s\n", check.Name, err)
+		}
+	}
+	for _, warning := range report.Warnings {
+		cmd.PrintErrf("WARNING: %s\n", warning)
+	}
+}
+
 type printInfo struct {
 	Moniker    string          `json:"moniker" yaml:"moniker"`
 	ChainID    string          `json:"chain_id" yaml:"chain_id"`
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"github.com/cosmos/cosmos-sdk/client"
+	"github.com/cosmos/cosmos-sdk/client/flags"
+	customante "github.com/oraichain/orai/app/ante"
+	antetypes "github.com/oraichain/orai/app/ante/types"
+	"github.com/spf13/cobra"
+)
+
+// SponsorshipQueryCmd returns the query commands of the sponsorship pool
+func SponsorshipQueryCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:                        customante.SponsorshipQuerierRoute,
+		Short:                      "Querying commands for the fee sponsorship pool",
+		DisableFlagParsing:         true,
+		SuggestionsMinimumDistance: 2,
+		RunE:                       client.ValidateCmd,
+	}
+
+	cmd.AddCommand(
+		sponsorshipPoolCmd(),
+		sponsorshipAccountCmd(),
+	)
+	return cmd
+}
+
+func sponsorshipPoolCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "pool",
+		Short: "Query the address, balance and params of the sponsorship pool",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			clientCtx, err := client.GetClientQueryContext(cmd)
+			if err != nil {
+				return err
+			}
+
+			res, err := antetypes.NewQueryClient(clientCtx).Pool(cmd.Context(), &antetypes.QueryPoolRequest{})
+			if err != nil {
+				return err
+			}
+			return clientCtx.PrintProto(res)
+		},
+	}
+
+	flags.AddQueryFlagsToCmd(cmd)
+	return cmd
+}
+
+func sponsorshipAccountCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "account [address]",
+		Short: "Query whether the fees of an account can be sponsored",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			clientCtx, err := client.GetClientQueryContext(cmd)
+			if err != nil {
+				return err
+			}
+
+			res, err := antetypes.NewQueryClient(clientCtx).Account(cmd.Context(), &antetypes.QueryAccountRequest{Address: args[0]})
+			if err != nil {
+				return err
+			}
+			return clientCtx.PrintProto(res)
+		},
+	}
+
+	flags.AddQueryFlagsToCmd(cmd)
+	return cmd
+}
@@ -0,0 +1,737 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/cosmos/cosmos-sdk/client"
+	"github.com/cosmos/cosmos-sdk/client/flags"
+	"github.com/cosmos/cosmos-sdk/client/tx"
+	cryptocodec "github.com/cosmos/cosmos-sdk/crypto/codec"
+	sdkhd "github.com/cosmos/cosmos-sdk/crypto/hd"
+	"github.com/cosmos/cosmos-sdk/crypto/keyring"
+	cryptotypes "github.com/cosmos/cosmos-sdk/crypto/types"
+	"github.com/cosmos/cosmos-sdk/server"
+	"github.com/cosmos/cosmos-sdk/server/api"
+	srvconfig "github.com/cosmos/cosmos-sdk/server/config"
+	servergrpc "github.com/cosmos/cosmos-sdk/server/grpc"
+	servertypes "github.com/cosmos/cosmos-sdk/server/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/version"
+	authtypes "github.com/cosmos/cosmos-sdk/x/auth/types"
+	banktypes "github.com/cosmos/cosmos-sdk/x/bank/types"
+	"github.com/cosmos/cosmos-sdk/x/genutil"
+	genutiltypes "github.com/cosmos/cosmos-sdk/x/genutil/types"
+	stakingtypes "github.com/cosmos/cosmos-sdk/x/staking/types"
+	"github.com/cosmos/go-bip39"
+	"github.com/oraichain/orai/app"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	tmcfg "github.com/tendermint/tendermint/config"
+	"github.com/tendermint/tendermint/crypto/ed25519"
+	"github.com/tendermint/tendermint/libs/log"
+	tmos "github.com/tendermint/tendermint/libs/os"
+	"github.com/tendermint/tendermint/node"
+	"github.com/tendermint/tendermint/p2p"
+	"github.com/tendermint/tendermint/privval"
+	"github.com/tendermint/tendermint/proxy"
+	"github.com/tendermint/tendermint/rpc/client/local"
+	tmtypes "github.com/tendermint/tendermint/types"
+	tmtime "github.com/tendermint/tendermint/types/time"
+	dbm "github.com/tendermint/tm-db"
+	"github.com/tharsis/ethermint/crypto/hd"
+	ethermintserver "github.com/tharsis/ethermint/server"
+	servercfg "github.com/tharsis/ethermint/server/config"
+	"google.golang.org/grpc"
+)
+
+const (
+	flagTestnetNumValidators = "v"
+	flagTestnetOutputDir     = "output-dir"
+	flagTestnetAccountCoins  = "account-coins"
+	flagTestnetStakingAmount = "staking-amount"
+	flagTestnetGenesisTime   = "genesis-time"
+	flagTestnetPortOffset    = "port-offset"
+	flagTestnetPortIncrement = "port-increment"
+
+	testnetNodeDirPrefix = "node"
+)
+
+// testnetPorts are the ports a node of a local testnet listens on
+type testnetPorts struct {
+	P2P       int
+	RPC       int
+	ABCI      int
+	Pprof     int
+	API       int
+	GRPC      int
+	GRPCWeb   int
+	JSONRPC   int
+	JSONRPCWs int
+}
+
+// defaultTestnetPorts are the default ports of a node, the ones of the nodes of a testnet being shifted from them
+var defaultTestnetPorts = testnetPorts{
+	P2P:       26656,
+	RPC:       26657,
+	ABCI:      26658,
+	Pprof:     6060,
+	API:       1317,
+	GRPC:      9090,
+	GRPCWeb:   9091,
+	JSONRPC:   8545,
+	JSONRPCWs: 8546,
+}
+
+// shift returns the ports shifted by offset
+func (p testnetPorts) shift(offset int) testnetPorts {
+	return testnetPorts{
+		P2P:       p.P2P + offset,
+		RPC:       p.RPC + offset,
+		ABCI:      p.ABCI + offset,
+		Pprof:     p.Pprof + offset,
+		API:       p.API + offset,
+		GRPC:      p.GRPC + offset,
+		GRPCWeb:   p.GRPCWeb + offset,
+		JSONRPC:   p.JSONRPC + offset,
+		JSONRPCWs: p.JSONRPCWs + offset,
+	}
+}
+
+func (p testnetPorts) list() []int {
+	return []int{p.P2P, p.RPC, p.ABCI, p.Pprof, p.API, p.GRPC, p.GRPCWeb, p.JSONRPC, p.JSONRPCWs}
+}
+
+// testnetFilesConfig defines the homes of the validators of a local testnet
+type testnetFilesConfig struct {
+	ChainID        string
+	NumValidators  int
+	OutputDir      string
+	Profile        app.GenesisProfile
+	GenesisTime    time.Time
+	AccountCoins   sdk.Coins
+	StakingAmount  sdk.Coin
+	Funds          []banktypes.Balance
+	MinGasPrices   string
+	PortOffset     int
+	PortIncrement  int
+	KeyringBackend string
+	KeyAlgorithm   string
+}
+
+// ports returns the ports of the node i of the testnet
+func (cfg testnetFilesConfig) ports(i int) testnetPorts {
+	return defaultTestnetPorts.shift(cfg.PortOffset + i*cfg.PortIncrement)
+}
+
+// validatePorts checks that the nodes of the testnet listen on distinct and valid ports
+func (cfg testnetFilesConfig) validatePorts() error {
+	used := make(map[int]string)
+	for i := 0; i < cfg.NumValidators; i++ {
+		moniker := testnetMoniker(i)
+		for _, port := range cfg.ports(i).list() {
+			if port <= 0 || port > 65535 {
+				return fmt.Errorf("invalid port %d of %s", port, moniker)
+			}
+			if other, ok := used[port]; ok {
+				return fmt.Errorf("port %d used by both %s and %s, increase --%s", port, other, moniker, flagTestnetPortIncrement)
+			}
+			used[port] = moniker
+		}
+	}
+	return nil
+}
+
+// testnetNode is a node created for a local testnet
+type testnetNode struct {
+	Moniker string
+	Home    string
+	NodeID  string
+	Address sdk.AccAddress
+	Ports   testnetPorts
+}
+
+// TestnetCmd returns the commands creating and running local testnets of several validators
+func TestnetCmd(ac appCreator) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:                        "testnet",
+		Short:                      "Create and run local testnets of several validators",
+		DisableFlagParsing:         true,
+		SuggestionsMinimumDistance: 2,
+		RunE:                       client.ValidateCmd,
+	}
+	cmd.AddCommand(TestnetInitFilesCmd(), TestnetStartCmd(ac))
+	return cmd
+}
+
+// TestnetInitFilesCmd returns a command creating the homes of the validators of a local testnet
+func TestnetInitFilesCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "init-files",
+		Short: "Create the homes of the validators of a local testnet",
+		Long: `Create the homes of the validators of a local testnet in --output-dir, node0, node1, ... sharing the genesis
+file built from a genesis params profile and the gentxs of the validators.
+
+The nodes peer with each other on 127.0.0.1 and listen on the default ports shifted by --port-offset, plus
+--port-increment for each node, so that they can run side by side on a single host with start --home.
+
+The keys of the nodes, the validators and their accounts are derived from the chain-id, so that the same command
+creates the same node ids and addresses. They are stored unencrypted and are only meant for local testnets. Together
+with --genesis-time, the same command creates the same genesis file.`,
+		Example: fmt.Sprintf(`$ %[1]s testnet init-files --v 4 --output-dir ./testnet --chain-id testing
+$ %[1]s start --home ./testnet/node0`, version.AppName),
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			clientCtx := client.GetClientContextFromCmd(cmd)
+			cfg, err := testnetFilesConfigFromFlags(cmd)
+			if err != nil {
+				return err
+			}
+
+			nodes, err := initTestnetFiles(clientCtx, cmd.InOrStdin(), cfg)
+			if err != nil {
+				return err
+			}
+			printTestnetNodes(cmd, nodes)
+			return nil
+		},
+	}
+
+	addTestnetFilesFlags(cmd)
+	return cmd
+}
+
+// TestnetStartCmd returns a command running the validators of a local testnet in process
+func TestnetStartCmd(ac appCreator) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "start",
+		Short: "Run the validators of a local testnet in process",
+		Long: `Run the validators of the local testnet of --output-dir in process until interrupted, creating the testnet
+first like init-files when the directory holds none.
+
+Tendermint serves the RPC of a single node per process: only node0 serves the RPC, API, gRPC and JSON-RPC endpoints.
+The other nodes take part in the consensus only.`,
+		Example: fmt.Sprintf(`$ %s testnet start --v 4 --output-dir ./testnet`, version.AppName),
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			clientCtx := client.GetClientContextFromCmd(cmd)
+			serverCtx := server.GetServerContextFromCmd(cmd)
+			outputDir, _ := cmd.Flags().GetString(flagTestnetOutputDir)
+
+			homes := testnetHomes(outputDir)
+			if len(homes) == 0 {
+				cfg, err := testnetFilesConfigFromFlags(cmd)
+				if err != nil {
+					return err
+				}
+				nodes, err := initTestnetFiles(clientCtx, cmd.InOrStdin(), cfg)
+				if err != nil {
+					return err
+				}
+				printTestnetNodes(cmd, nodes)
+				homes = testnetHomes(outputDir)
+			}
+
+			testnet, err := startLocalTestnet(serverCtx.Logger, clientCtx, ac, homes)
+			if err != nil {
+				return err
+			}
+			defer testnet.stop()
+
+			cmd.Printf("running %d nodes of %s, press Ctrl+C to stop\n", len(homes), outputDir)
+			server.WaitForQuitSignals()
+			return nil
+		},
+	}
+
+	addTestnetFilesFlags(cmd)
+	return cmd
+}
+
+func addTestnetFilesFlags(cmd *cobra.Command) {
+	cmd.Flags().Int(flagTestnetNumValidators, 4, "Number of validators of the testnet")
+	cmd.Flags().StringP(flagTestnetOutputDir, "o", "./testnet", "Directory of the homes of the validators")
+	cmd.Flags().String(flags.FlagChainID, "testing", "Chain-id of the testnet, from which the keys are derived")
+	cmd.Flags().String(flagGenesisProfile, app.LocalProfile, fmt.Sprintf("genesis params profile (%s)", strings.Join(app.GenesisProfileNames(), "|")))
+	cmd.Flags().String(flagGenesisProfileFile, "", "json file of genesis params overriding the ones of the profile")
+	cmd.Flags().String(flagTestnetGenesisTime, "", "Genesis time of the testnet in RFC3339 format, now if empty")
+	cmd.Flags().String(flagTestnetAccountCoins, "1000000000000orai", "Coins of the account of each validator")
+	cmd.Flags().String(flagTestnetStakingAmount, "500000000orai", "Coins self-delegated by each validator")
+	cmd.Flags().StringArray(flagTestnetFund, nil, "Coins of another account, as address=coins, can be repeated")
+	cmd.Flags().String(server.FlagMinGasPrices, "0orai", "Minimum gas prices of the nodes")
+	cmd.Flags().Int(flagTestnetPortOffset, 0, "Offset added to the default ports of all the nodes")
+	cmd.Flags().Int(flagTestnetPortIncrement, 10, "Offset added to the ports of each node from the ones of the previous node")
+	cmd.Flags().String(flags.FlagKeyringBackend, keyring.BackendTest, "Keyring backend of the validator keys (os|file|test)")
+	cmd.Flags().String(flags.FlagKeyAlgorithm, string(sdkhd.Secp256k1Type), "Signing algorithm of the validator keys")
+}
+
+// testnetFilesConfigFromFlags reads the definition of a local testnet
+func testnetFilesConfigFromFlags(cmd *cobra.Command) (testnetFilesConfig, error) {
+	cfg := testnetFilesConfig{}
+	cfg.NumValidators, _ = cmd.Flags().GetInt(flagTestnetNumValidators)
+	if cfg.NumValidators < 1 {
+		return cfg, fmt.Errorf("invalid number of validators %d", cfg.NumValidators)
+	}
+	cfg.OutputDir, _ = cmd.Flags().GetString(flagTestnetOutputDir)
+	cfg.ChainID, _ = cmd.Flags().GetString(flags.FlagChainID)
+	if cfg.ChainID == "" {
+		return cfg, fmt.Errorf("the chain-id is required")
+	}
+
+	profileName, _ := cmd.Flags().GetString(flagGenesisProfile)
+	profileFile, _ := cmd.Flags().GetString(flagGenesisProfileFile)
+	profile, err := app.LoadGenesisProfile(profileName, profileFile)
+	if err != nil {
+		return cfg, err
+	}
+	cfg.Profile = profile
+
+	cfg.GenesisTime = tmtime.Now()
+	if genesisTime, _ := cmd.Flags().GetString(flagTestnetGenesisTime); genesisTime != "" {
+		if cfg.GenesisTime, err = time.Parse(time.RFC3339, genesisTime); err != nil {
+			return cfg, err
+		}
+	}
+
+	accountCoins, _ := cmd.Flags().GetString(flagTestnetAccountCoins)
+	if cfg.AccountCoins, err = sdk.ParseCoinsNormalized(accountCoins); err != nil {
+		return cfg, err
+	}
+	stakingAmount, _ := cmd.Flags().GetString(flagTestnetStakingAmount)
+	if cfg.StakingAmount, err = sdk.ParseCoinNormalized(stakingAmount); err != nil {
+		return cfg, err
+	}
+	if cfg.Funds, err = testnetFundsFromFlags(cmd); err != nil {
+		return cfg, err
+	}
+
+	cfg.MinGasPrices, _ = cmd.Flags().GetString(server.FlagMinGasPrices)
+	cfg.PortOffset, _ = cmd.Flags().GetInt(flagTestnetPortOffset)
+	cfg.PortIncrement, _ = cmd.Flags().GetInt(flagTestnetPortIncrement)
+	cfg.KeyringBackend, _ = cmd.Flags().GetString(flags.FlagKeyringBackend)
+	cfg.KeyAlgorithm, _ = cmd.Flags().GetString(flags.FlagKeyAlgorithm)
+	return cfg, nil
+}
+
+// initTestnetFiles creates the homes of the validators of the testnet, their genesis file holding the gentxs of all
+// the validators
+func initTestnetFiles(clientCtx client.Context, input io.Reader, cfg testnetFilesConfig) ([]testnetNode, error) {
+	if err := cfg.validatePorts(); err != nil {
+		return nil, err
+	}
+	if len(testnetHomes(cfg.OutputDir)) > 0 {
+		return nil, fmt.Errorf("a testnet already exists in %s", cfg.OutputDir)
+	}
+
+	gentxsDir := filepath.Join(cfg.OutputDir, "gentxs")
+	inBuf := bufio.NewReader(input)
+	nodes := make([]testnetNode, cfg.NumValidators)
+	valPubKeys := make([]cryptotypes.PubKey, cfg.NumValidators)
+	var (
+		genAccounts []authtypes.GenesisAccount
+		balances    []banktypes.Balance
+	)
+
+	for i := range nodes {
+		moniker := testnetMoniker(i)
+		home := filepath.Join(cfg.OutputDir, moniker)
+		ports := cfg.ports(i)
+		config := newTestnetNodeConfig(home, moniker, ports)
+		if err := os.MkdirAll(filepath.Join(home, "config"), 0o755); err != nil {
+			return nil, err
+		}
+		if err := os.MkdirAll(filepath.Join(home, "data"), 0o755); err != nil {
+			return nil, err
+		}
+
+		nodeKey := &p2p.NodeKey{PrivKey: ed25519.GenPrivKeyFromSecret(testnetSecret(cfg.ChainID, "node", i))}
+		if err := nodeKey.SaveAs(config.NodeKeyFile()); err != nil {
+			return nil, err
+		}
+		pv := privval.NewFilePV(ed25519.GenPrivKeyFromSecret(testnetSecret(cfg.ChainID, "validator", i)),
+			config.PrivValidatorKeyFile(), config.PrivValidatorStateFile())
+		pv.Save()
+		valPubKey, err := cryptocodec.FromTmPubKeyInterface(pv.Key.PubKey)
+		if err != nil {
+			return nil, err
+		}
+		valPubKeys[i] = valPubKey
+
+		addr, mnemonic, err := newTestnetAccountKey(clientCtx, inBuf, cfg, home, moniker, i)
+		if err != nil {
+			return nil, err
+		}
+		if err := writeTestnetFile(filepath.Join(home, "key_seed.json"), map[string]string{"secret": mnemonic}); err != nil {
+			return nil, err
+		}
+
+		genAccount, balance, err := newGenesisAccount(addr, cfg.AccountCoins, genesisVesting{})
+		if err != nil {
+			return nil, err
+		}
+		genAccounts = append(genAccounts, genAccount)
+		balances = append(balances, balance)
+
+		nodes[i] = testnetNode{Moniker: moniker, Home: home, NodeID: string(nodeKey.ID()), Address: addr, Ports: ports}
+		memo := fmt.Sprintf("%s@127.0.0.1:%d", nodes[i].NodeID, ports.P2P)
+		if err := writeTestnetGentx(clientCtx, inBuf, cfg, home, moniker, memo, addr, valPubKey, filepath.Join(gentxsDir, moniker+".json")); err != nil {
+			return nil, err
+		}
+
+		appConfigTemplate, appConfig := servercfg.AppConfig("")
+		appConfig.MinGasPrices = cfg.MinGasPrices
+		appConfig.API.Enable = true
+		appConfig.API.Address = fmt.Sprintf("tcp://127.0.0.1:%d", ports.API)
+		appConfig.GRPC.Address = fmt.Sprintf("127.0.0.1:%d", ports.GRPC)
+		appConfig.GRPCWeb.Address = fmt.Sprintf("127.0.0.1:%d", ports.GRPCWeb)
+		appConfig.JSONRPC.Address = fmt.Sprintf("127.0.0.1:%d", ports.JSONRPC)
+		appConfig.JSONRPC.WsAddress = fmt.Sprintf("127.0.0.1:%d", ports.JSONRPCWs)
+		srvconfig.SetConfigTemplate(appConfigTemplate)
+		srvconfig.WriteConfigFile(filepath.Join(home, "config", "app.toml"), appConfig)
+	}
+
+	for _, fund := range cfg.Funds {
+		genAccount, balance, err := newGenesisAccount(sdk.MustAccAddressFromBech32(fund.Address), fund.Coins, genesisVesting{})
+		if err != nil {
+			return nil, err
+		}
+		genAccounts = append(genAccounts, genAccount)
+		balances = append(balances, balance)
+	}
+
+	appState, err := addGenesisAccounts(clientCtx.Codec, app.NewGenesisStateFromProfile(clientCtx.Codec, cfg.Profile), genAccounts, balances)
+	if err != nil {
+		return nil, err
+	}
+	genDoc := tmtypes.GenesisDoc{
+		ChainID:     cfg.ChainID,
+		GenesisTime: cfg.GenesisTime.UTC(),
+		AppState:    appState,
+	}
+
+	// each node gets the same genesis file and the other nodes as persistent peers
+	for i, n := range nodes {
+		config := newTestnetNodeConfig(n.Home, n.Moniker, n.Ports)
+		initCfg := genutiltypes.NewInitConfig(cfg.ChainID, gentxsDir, n.NodeID, valPubKeys[i])
+		if _, err := genutil.GenAppStateFromConfig(clientCtx.Codec, clientCtx.TxConfig, config, initCfg, genDoc, banktypes.GenesisBalancesIterator{}); err != nil {
+			return nil, err
+		}
+	}
+	return nodes, nil
+}
+
+// newTestnetNodeConfig returns the Tendermint config of a node of a local testnet
+func newTestnetNodeConfig(home, moniker string, ports testnetPorts) *tmcfg.Config {
+	config := tmcfg.DefaultConfig()
+	config.SetRoot(home)
+	setNodeConfigDefaults(config)
+	config.Moniker = moniker
+	config.ProxyApp = fmt.Sprintf("tcp://127.0.0.1:%d", ports.ABCI)
+	config.RPC.ListenAddress = fmt.Sprintf("tcp://127.0.0.1:%d", ports.RPC)
+	config.RPC.PprofListenAddress = fmt.Sprintf("127.0.0.1:%d", ports.Pprof)
+	config.P2P.ListenAddress = fmt.Sprintf("tcp://127.0.0.1:%d", ports.P2P)
+	config.P2P.AddrBookStrict = false
+	config.P2P.AllowDuplicateIP = true
+	return config
+}
+
+// newTestnetAccountKey adds to the keyring of home the key of the account of the validator i, derived from the chain-id
+func newTestnetAccountKey(clientCtx client.Context, input io.Reader, cfg testnetFilesConfig, home, moniker string, i int) (sdk.AccAddress, string, error) {
+	kb, err := keyring.New(sdk.KeyringServiceName(), cfg.KeyringBackend, home, input, clientCtx.KeyringOptions...)
+	if err != nil {
+		return nil, "", err
+	}
+	keyringAlgos, _ := kb.SupportedAlgorithms()
+	algo, err := keyring.NewSigningAlgoFromString(cfg.KeyAlgorithm, keyringAlgos)
+	if err != nil {
+		return nil, "", err
+	}
+
+	mnemonic, err := bip39.NewMnemonic(testnetSecret(cfg.ChainID, "account", i))
+	if err != nil {
+		return nil, "", err
+	}
+	info, err := kb.NewAccount(moniker, mnemonic, "", sdk.GetConfig().GetFullBIP44Path(), algo)
+	if err != nil {
+		return nil, "", err
+	}
+	return info.GetAddress(), mnemonic, nil
+}
+
+// writeTestnetGentx writes to file the gentx of the validator of home, signed with its account key
+func writeTestnetGentx(clientCtx client.Context, input io.Reader, cfg testnetFilesConfig, home, moniker, memo string, addr sdk.AccAddress, valPubKey cryptotypes.PubKey, file string) error {
+	kb, err := keyring.New(sdk.KeyringServiceName(), cfg.KeyringBackend, home, input, clientCtx.KeyringOptions...)
+	if err != nil {
+		return err
+	}
+
+	createValMsg, err := stakingtypes.NewMsgCreateValidator(
+		sdk.ValAddress(addr),
+		valPubKey,
+		cfg.StakingAmount,
+		stakingtypes.NewDescription(moniker, "", "", "", ""),
+		stakingtypes.NewCommissionRates(sdk.NewDecWithPrec(1, 1), sdk.NewDecWithPrec(2, 1), sdk.NewDecWithPrec(1, 2)),
+		sdk.OneInt(),
+	)
+	if err != nil {
+		return err
+	}
+
+	txBuilder := clientCtx.TxConfig.NewTxBuilder()
+	if err := txBuilder.SetMsgs(createValMsg); err != nil {
+		return err
+	}
+	txBuilder.SetMemo(memo)
+	txBuilder.SetGasLimit(flags.DefaultGasLimit)
+
+	txFactory := tx.Factory{}.
+		WithChainID(cfg.ChainID).
+		WithMemo(memo).
+		WithKeybase(kb).
+		WithTxConfig(clientCtx.TxConfig)
+	if err := tx.Sign(txFactory, moniker, txBuilder, true); err != nil {
+		return err
+	}
+
+	bz, err := clientCtx.TxConfig.TxJSONEncoder()(txBuilder.GetTx())
+	if err != nil {
+		return err
+	}
+	if err := tmos.EnsureDir(filepath.Dir(file), 0o755); err != nil {
+		return err
+	}
+	return tmos.WriteFile(file, bz, 0o644)
+}
+
+// testnetSecret returns the secret of the key of kind of the node i of the testnet chainID
+func testnetSecret(chainID, kind string, i int) []byte {
+	secret := sha256.Sum256([]byte(fmt.Sprintf("%s/%s/%d", chainID, kind, i)))
+	return secret[:]
+}
+
+func testnetMoniker(i int) string {
+	return fmt.Sprintf("%s%d", testnetNodeDirPrefix, i)
+}
+
+// testnetHomes returns the homes of the nodes of the testnet of dir
+func testnetHomes(dir string) []string {
+	var homes []string
+	for i := 0; ; i++ {
+		home := filepath.Join(dir, testnetMoniker(i))
+		if !tmos.FileExists(filepath.Join(home, "config", "genesis.json")) {
+			return homes
+		}
+		homes = append(homes, home)
+	}
+}
+
+func writeTestnetFile(file string, v interface{}) error {
+	bz, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return tmos.WriteFile(file, bz, 0o600)
+}
+
+func printTestnetNodes(cmd *cobra.Command, nodes []testnetNode) {
+	for _, n := range nodes {
+		cmd.Printf("%s: home %s, node id %s, account %s\n", n.Moniker, n.Home, n.NodeID, n.Address)
+		cmd.Printf("  p2p %d, rpc %d, api %d, grpc %d, json-rpc %d\n", n.Ports.P2P, n.Ports.RPC, n.Ports.API, n.Ports.GRPC, n.Ports.JSONRPC)
+	}
+	cmd.Printf("initialized %d node directories\n", len(nodes))
+}
+
+// localTestnet is a local testnet whose nodes run in process
+type localTestnet struct {
+	nodes []*localTestnetNode
+}
+
+// localTestnetNode is a node running in process
+type localTestnetNode struct {
+	db      dbm.DB
+	tmNode  *node.Node
+	api     *api.Server
+	grpc    *grpc.Server
+	grpcWeb *http.Server
+	jsonRPC *http.Server
+}
+
+// startLocalTestnet starts the nodes of homes in process, the first one serving the RPC endpoints
+func startLocalTestnet(logger log.Logger, clientCtx client.Context, ac appCreator, homes []string) (*localTestnet, error) {
+	testnet := &localTestnet{}
+	for i, home := range homes {
+		n, err := startLocalTestnetNode(logger, clientCtx, ac, home, i == 0)
+		if err != nil {
+			testnet.stop()
+			return nil, fmt.Errorf("failed to start the node of %s: %w", home, err)
+		}
+		testnet.nodes = append(testnet.nodes, n)
+	}
+	return testnet, nil
+}
+
+// startLocalTestnetNode starts the node of home with the configuration of its home, the RPC endpoints being disabled
+// unless serve is set
+func startLocalTestnetNode(logger log.Logger, clientCtx client.Context, ac appCreator, home string, serve bool) (*localTestnetNode, error) {
+	v := viper.New()
+	v.Set(flags.FlagHome, home)
+	v.SetConfigFile(filepath.Join(home, "config", "config.toml"))
+	if err := v.ReadInConfig(); err != nil {
+		return nil, err
+	}
+	v.SetConfigFile(filepath.Join(home, "config", "app.toml"))
+	if err := v.MergeInConfig(); err != nil {
+		return nil, err
+	}
+
+	config := tmcfg.DefaultConfig()
+	if err := v.Unmarshal(config); err != nil {
+		return nil, err
+	}
+	config.SetRoot(home)
+	config.Instrumentation.Prometheus = false
+	appConfig, err := servercfg.GetConfig(v)
+	if err != nil {
+		return nil, err
+	}
+	// the namespaces are a comma separated list in app.toml, which viper does not split
+	appConfig.JSONRPC.API = strings.Split(strings.Join(appConfig.JSONRPC.API, ","), ",")
+	if !serve {
+		config.RPC.ListenAddress = ""
+		appConfig.API.Enable = false
+		appConfig.GRPC.Enable = false
+		appConfig.JSONRPC.Enable = false
+	}
+	logger = logger.With("node", config.Moniker)
+
+	db, err := openApplicationDB(config)
+	if err != nil {
+		return nil, err
+	}
+	n := &localTestnetNode{db: db}
+	application := ac.newOraichainApp(logger, db, nil, v)
+
+	nodeKey, err := p2p.LoadNodeKey(config.NodeKeyFile())
+	if err != nil {
+		n.stop()
+		return nil, err
+	}
+	n.tmNode, err = node.NewNode(
+		config,
+		privval.LoadFilePV(config.PrivValidatorKeyFile(), config.PrivValidatorStateFile()),
+		nodeKey,
+		proxy.NewLocalClientCreator(application),
+		node.DefaultGenesisDocProviderFunc(config),
+		node.DefaultDBProvider,
+		node.DefaultMetricsProvider(config.Instrumentation),
+		logger,
+	)
+	if err != nil {
+		n.stop()
+		return nil, err
+	}
+	if err := n.tmNode.Start(); err != nil {
+		n.stop()
+		return nil, err
+	}
+
+	if err := n.startServices(server.NewContext(v, config, logger), clientCtx, application, appConfig); err != nil {
+		n.stop()
+		return nil, err
+	}
+	return n, nil
+}
+
+// startServices starts the API, gRPC and JSON-RPC servers enabled by appConfig, like start does
+func (n *localTestnetNode) startServices(serverCtx *server.Context, clientCtx client.Context, application servertypes.Application, appConfig servercfg.Config) error {
+	if !appConfig.API.Enable && !appConfig.GRPC.Enable && !appConfig.JSONRPC.Enable {
+		return nil
+	}
+	// the accounts served by JSON-RPC are the ones of the test keyring of the node
+	home := serverCtx.Config.RootDir
+	kb, err := keyring.New(sdk.KeyringServiceName(), keyring.BackendTest, home, clientCtx.Input, hd.EthSecp256k1Option())
+	if err != nil {
+		return err
+	}
+	clientCtx = clientCtx.
+		WithClient(local.New(n.tmNode)).
+		WithHomeDir(home).
+		WithKeyringDir(home).
+		WithKeyring(kb).
+		WithChainID(n.tmNode.GenesisDoc().ChainID)
+	application.RegisterTxService(clientCtx)
+	application.RegisterTendermintService(clientCtx)
+
+	if appConfig.API.Enable {
+		n.api = api.New(clientCtx, serverCtx.Logger.With("server", "api"))
+		application.RegisterAPIRoutes(n.api, appConfig.API)
+		errCh := make(chan error)
+		go func() {
+			if err := n.api.Start(appConfig.Config); err != nil {
+				errCh <- err
+			}
+		}()
+		select {
+		case err := <-errCh:
+			return err
+		case <-time.After(servertypes.ServerStartTime):
+		}
+	}
+
+	if appConfig.GRPC.Enable {
+		if n.grpc, err = servergrpc.StartGRPCServer(clientCtx, application, appConfig.GRPC.Address); err != nil {
+			return err
+		}
+		if appConfig.GRPCWeb.Enable {
+			if n.grpcWeb, err = servergrpc.StartGRPCWeb(n.grpc, appConfig.Config); err != nil {
+				return err
+			}
+		}
+	}
+
+	if appConfig.JSONRPC.Enable {
+		n.jsonRPC, _, err = ethermintserver.StartJSONRPC(serverCtx, clientCtx, serverCtx.Config.RPC.ListenAddress, "/websocket", appConfig)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (n *localTestnetNode) stop() {
+	if n.jsonRPC != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		_ = n.jsonRPC.Shutdown(ctx)
+		cancel()
+	}
+	if n.grpcWeb != nil {
+		_ = n.grpcWeb.Close()
+	}
+	if n.grpc != nil {
+		n.grpc.Stop()
+	}
+	if n.api != nil {
+		_ = n.api.Close()
+	}
+	if n.tmNode != nil && n.tmNode.IsRunning() {
+		_ = n.tmNode.Stop()
+		n.tmNode.Wait()
+	}
+	_ = n.db.Close()
+}
+
+// stop stops the nodes of the testnet
+func (t *localTestnet) stop() {
+	for _, n := range t.nodes {
+		n.stop()
+	}
+}
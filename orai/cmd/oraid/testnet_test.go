@@ -0,0 +1,118 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cosmos/cosmos-sdk/client"
+	"github.com/cosmos/cosmos-sdk/crypto/keyring"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	genutiltypes "github.com/cosmos/cosmos-sdk/x/genutil/types"
+	"github.com/oraichain/orai/app"
+	"github.com/stretchr/testify/require"
+	"github.com/tendermint/tendermint/libs/log"
+	tmtypes "github.com/tendermint/tendermint/types"
+)
+
+func newTestnetFilesConfig(outputDir string) testnetFilesConfig {
+	return testnetFilesConfig{
+		ChainID:        "testing",
+		NumValidators:  2,
+		OutputDir:      outputDir,
+		Profile:        app.GenesisProfiles[app.LocalProfile],
+		GenesisTime:    time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		AccountCoins:   sdk.NewCoins(sdk.NewInt64Coin("orai", 1000000000000)),
+		StakingAmount:  sdk.NewInt64Coin("orai", 500000000),
+		MinGasPrices:   "0orai",
+		PortOffset:     30000,
+		PortIncrement:  10,
+		KeyringBackend: keyring.BackendTest,
+		KeyAlgorithm:   "secp256k1",
+	}
+}
+
+func newTestClientContext() client.Context {
+	encodingConfig := app.MakeEncodingConfig()
+	return client.Context{}.
+		WithCodec(encodingConfig.Codec).
+		WithInterfaceRegistry(encodingConfig.InterfaceRegistry).
+		WithTxConfig(encodingConfig.TxConfig).
+		WithLegacyAmino(encodingConfig.Amino)
+}
+
+func TestInitTestnetFiles(t *testing.T) {
+	clientCtx := newTestClientContext()
+	cfg := newTestnetFilesConfig(t.TempDir())
+
+	nodes, err := initTestnetFiles(clientCtx, nil, cfg)
+	require.NoError(t, err)
+	require.Len(t, nodes, 2)
+	require.Equal(t, []string{nodes[0].Home, nodes[1].Home}, testnetHomes(cfg.OutputDir))
+	require.Equal(t, 26656+30000, nodes[0].Ports.P2P)
+	require.Equal(t, 26656+30010, nodes[1].Ports.P2P)
+
+	// the nodes share the genesis file holding the gentxs of both validators
+	genesis, err := os.ReadFile(filepath.Join(nodes[0].Home, "config", "genesis.json"))
+	require.NoError(t, err)
+	other, err := os.ReadFile(filepath.Join(nodes[1].Home, "config", "genesis.json"))
+	require.NoError(t, err)
+	require.Equal(t, genesis, other)
+	genDoc, err := tmtypes.GenesisDocFromJSON(genesis)
+	require.NoError(t, err)
+	require.Equal(t, "testing", genDoc.ChainID)
+	require.Equal(t, 2, strings.Count(string(genDoc.AppState), "MsgCreateValidator"))
+	appState, err := genutiltypes.GenesisStateFromGenDoc(*genDoc)
+	require.NoError(t, err)
+	require.NoError(t, app.ModuleBasics.ValidateGenesis(clientCtx.Codec, clientCtx.TxConfig, appState))
+
+	// and peer with each other
+	config, err := os.ReadFile(filepath.Join(nodes[0].Home, "config", "config.toml"))
+	require.NoError(t, err)
+	require.Contains(t, string(config), nodes[1].NodeID+"@127.0.0.1:56666")
+	require.NotContains(t, string(config), nodes[0].NodeID+"@")
+
+	// the same command creates the same testnet
+	again, err := initTestnetFiles(clientCtx, nil, newTestnetFilesConfig(t.TempDir()))
+	require.NoError(t, err)
+	for i := range nodes {
+		require.Equal(t, nodes[i].NodeID, again[i].NodeID)
+		require.Equal(t, nodes[i].Address, again[i].Address)
+	}
+	genesisAgain, err := os.ReadFile(filepath.Join(again[0].Home, "config", "genesis.json"))
+	require.NoError(t, err)
+	require.True(t, bytes.Equal(genesis, genesisAgain))
+
+	_, err = initTestnetFiles(clientCtx, nil, cfg)
+	require.ErrorContains(t, err, "a testnet already exists")
+
+	cfg.PortIncrement = 1
+	_, err = initTestnetFiles(clientCtx, nil, cfg)
+	require.ErrorContains(t, err, "used by both node0 and node1")
+}
+
+func TestStartLocalTestnet(t *testing.T) {
+	clientCtx := newTestClientContext()
+	cfg := newTestnetFilesConfig(t.TempDir())
+	_, err := initTestnetFiles(clientCtx, nil, cfg)
+	require.NoError(t, err)
+
+	testnet, err := startLocalTestnet(log.NewNopLogger(), clientCtx, appCreator{encCfg: app.MakeEncodingConfig()}, testnetHomes(cfg.OutputDir))
+	require.NoError(t, err)
+	defer testnet.stop()
+
+	// both validators are needed to commit blocks
+	require.Eventually(t, func() bool {
+		for _, n := range testnet.nodes {
+			if n.tmNode.BlockStore().Height() < 3 {
+				return false
+			}
+		}
+		return true
+	}, time.Minute, 100*time.Millisecond)
+	require.NotNil(t, testnet.nodes[0].grpc)
+	require.Nil(t, testnet.nodes[1].grpc)
+}
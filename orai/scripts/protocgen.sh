@@ -0,0 +1,29 @@
+#!/usr/bin/env bash
+
+set -eo pipefail
+
+# go install github.com/regen-network/cosmos-proto/protoc-gen-gocosmos@v0.3.1
+# go install github.com/grpc-ecosystem/grpc-gateway/protoc-gen-grpc-gateway@v1.16.0
+
+BASEDIR=$(dirname $0)
+PROJECTDIR=$(realpath $BASEDIR/..)
+
+COSMOS_SDK_DIR=${COSMOS_SDK_DIR:-$(go list -f "{{ .Dir }}" -m github.com/cosmos/cosmos-sdk)}
+
+GEN_DIR=$(mktemp -d)
+trap "rm -rf $GEN_DIR" EXIT
+
+# generate the go files of the first-party proto files, skipping the vendored ones at the root of the proto folder
+proto_dirs=$(find $PROJECTDIR/proto -mindepth 2 -name '*.proto' -print0 | xargs -0 -n1 dirname | sort | uniq)
+for dir in $proto_dirs; do
+  buf alpha protoc \
+    -I="$PROJECTDIR/proto" \
+    -I="$COSMOS_SDK_DIR/third_party/proto" \
+    -I="$COSMOS_SDK_DIR/proto" \
+    --gocosmos_out=Mgoogle/protobuf/any.proto=github.com/cosmos/cosmos-sdk/codec/types,plugins=interfacetype+grpc:$GEN_DIR \
+    --grpc-gateway_out=logtostderr=true:$GEN_DIR \
+    $(find "${dir}" -maxdepth 1 -name '*.proto')
+done
+
+# move the generated files to their go package in the module
+cp -r $GEN_DIR/github.com/oraichain/orai/* $PROJECTDIR/
@@ -0,0 +1,81 @@
+package cli
+
+import (
+	"github.com/cosmos/cosmos-sdk/client"
+	"github.com/cosmos/cosmos-sdk/client/flags"
+	"github.com/oraichain/orai/x/icaauth/types"
+	"github.com/spf13/cobra"
+)
+
+// GetQueryCmd returns the query commands of the module
+func GetQueryCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:                        types.ModuleName,
+		Short:                      "Querying commands for the interchain accounts controller",
+		DisableFlagParsing:         true,
+		SuggestionsMinimumDistance: 2,
+		RunE:                       client.ValidateCmd,
+	}
+
+	cmd.AddCommand(
+		getInterchainAccountCmd(),
+		getInterchainAccountsCmd(),
+	)
+
+	return cmd
+}
+
+func getInterchainAccountCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "interchain-account [owner] [connection-id]",
+		Short: "Query the interchain account of the owner on the host chain of the connection",
+		Args:  cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			clientCtx, err := client.GetClientQueryContext(cmd)
+			if err != nil {
+				return err
+			}
+
+			queryClient := types.NewQueryClient(clientCtx)
+			res, err := queryClient.InterchainAccount(cmd.Context(), &types.QueryInterchainAccountRequest{
+				Owner:        args[0],
+				ConnectionId: args[1],
+			})
+			if err != nil {
+				return err
+			}
+
+			return clientCtx.PrintProto(res)
+		},
+	}
+
+	flags.AddQueryFlagsToCmd(cmd)
+
+	return cmd
+}
+
+func getInterchainAccountsCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "interchain-accounts [owner]",
+		Short: "Query all the interchain accounts of the owner",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			clientCtx, err := client.GetClientQueryContext(cmd)
+			if err != nil {
+				return err
+			}
+
+			queryClient := types.NewQueryClient(clientCtx)
+			res, err := queryClient.InterchainAccounts(cmd.Context(), &types.QueryInterchainAccountsRequest{Owner: args[0]})
+			if err != nil {
+				return err
+			}
+
+			return clientCtx.PrintProto(res)
+		},
+	}
+
+	flags.AddQueryFlagsToCmd(cmd)
+
+	return cmd
+}
@@ -0,0 +1,127 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/cosmos/cosmos-sdk/client"
+	"github.com/cosmos/cosmos-sdk/client/flags"
+	"github.com/cosmos/cosmos-sdk/client/tx"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/oraichain/orai/x/icaauth/types"
+	"github.com/spf13/cobra"
+)
+
+const (
+	// FlagVersion is the version of the channel of the interchain account
+	FlagVersion = "version"
+	// FlagRelativeTimeout is the time after the block time at which the packet of the txs times out
+	FlagRelativeTimeout = "relative-timeout"
+
+	defaultRelativeTimeout = 10 * time.Minute
+)
+
+// GetTxCmd returns the tx commands of the module
+func GetTxCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:                        types.ModuleName,
+		Short:                      "Interchain accounts controller transactions subcommands",
+		DisableFlagParsing:         true,
+		SuggestionsMinimumDistance: 2,
+		RunE:                       client.ValidateCmd,
+	}
+
+	cmd.AddCommand(
+		getRegisterAccountCmd(),
+		getSubmitTxCmd(),
+	)
+
+	return cmd
+}
+
+func getRegisterAccountCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "register [connection-id]",
+		Short: "Register an interchain account of the sender on the host chain of the connection",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			clientCtx, err := client.GetClientTxContext(cmd)
+			if err != nil {
+				return err
+			}
+
+			version, err := cmd.Flags().GetString(FlagVersion)
+			if err != nil {
+				return err
+			}
+
+			msg := types.NewMsgRegisterAccount(clientCtx.GetFromAddress().String(), args[0], version)
+			if err := msg.ValidateBasic(); err != nil {
+				return err
+			}
+
+			return tx.GenerateOrBroadcastTxCLI(clientCtx, cmd.Flags(), msg)
+		},
+	}
+
+	cmd.Flags().String(FlagVersion, "", "Version of the channel, the default metadata of the connection when empty")
+	flags.AddTxFlagsToCmd(cmd)
+
+	return cmd
+}
+
+func getSubmitTxCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "submit-tx [connection-id] [path/to/msgs.json]",
+		Short: "Execute messages on the host chain of the connection with the interchain account of the sender",
+		Long: `Execute messages on the host chain of the connection with the interchain account of the sender.
+The messages are given as a JSON array of messages, or a file holding it, e.g.
+[{"@type":"/cosmos.bank.v1beta1.MsgSend","from_address":"cosmos1...","to_address":"cosmos1...","amount":[{"denom":"uatom","amount":"1"}]}]`,
+		Args: cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			clientCtx, err := client.GetClientTxContext(cmd)
+			if err != nil {
+				return err
+			}
+
+			relativeTimeout, err := cmd.Flags().GetDuration(FlagRelativeTimeout)
+			if err != nil {
+				return err
+			}
+
+			bz := []byte(args[1])
+			if !json.Valid(bz) {
+				if bz, err = os.ReadFile(args[1]); err != nil {
+					return fmt.Errorf("neither JSON messages nor a file holding them were provided: %w", err)
+				}
+			}
+			var rawMsgs []json.RawMessage
+			if err := json.Unmarshal(bz, &rawMsgs); err != nil {
+				return fmt.Errorf("failed to parse the messages: %w", err)
+			}
+			msgs := make([]sdk.Msg, len(rawMsgs))
+			for i, rawMsg := range rawMsgs {
+				if err := clientCtx.Codec.UnmarshalInterfaceJSON(rawMsg, &msgs[i]); err != nil {
+					return fmt.Errorf("failed to parse message %d: %w", i, err)
+				}
+			}
+
+			msg, err := types.NewMsgSubmitTx(clientCtx.GetFromAddress().String(), args[0], msgs, relativeTimeout)
+			if err != nil {
+				return err
+			}
+			if err := msg.ValidateBasic(); err != nil {
+				return err
+			}
+
+			return tx.GenerateOrBroadcastTxCLI(clientCtx, cmd.Flags(), msg)
+		},
+	}
+
+	cmd.Flags().Duration(FlagRelativeTimeout, defaultRelativeTimeout, "Time after the block time at which the packet of the messages times out")
+	flags.AddTxFlagsToCmd(cmd)
+
+	return cmd
+}
@@ -0,0 +1,124 @@
+package icaauth
+
+import (
+	"strconv"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	sdkerrors "github.com/cosmos/cosmos-sdk/types/errors"
+	capabilitytypes "github.com/cosmos/cosmos-sdk/x/capability/types"
+	icatypes "github.com/cosmos/ibc-go/v4/modules/apps/27-interchain-accounts/types"
+	channeltypes "github.com/cosmos/ibc-go/v4/modules/core/04-channel/types"
+	porttypes "github.com/cosmos/ibc-go/v4/modules/core/05-port/types"
+	host "github.com/cosmos/ibc-go/v4/modules/core/24-host"
+	ibcexported "github.com/cosmos/ibc-go/v4/modules/core/exported"
+	"github.com/oraichain/orai/x/icaauth/keeper"
+	"github.com/oraichain/orai/x/icaauth/types"
+)
+
+var _ porttypes.IBCModule = IBCModule{}
+
+// IBCModule is the application of the interchain accounts controller middleware, authenticating the owners of the
+// interchain accounts
+type IBCModule struct {
+	keeper keeper.Keeper
+}
+
+// NewIBCModule creates a new IBCModule
+func NewIBCModule(k keeper.Keeper) IBCModule {
+	return IBCModule{
+		keeper: k,
+	}
+}
+
+// OnChanOpenInit claims the capability of the channel of a new interchain account
+func (im IBCModule) OnChanOpenInit(
+	ctx sdk.Context,
+	order channeltypes.Order,
+	connectionHops []string,
+	portID string,
+	channelID string,
+	chanCap *capabilitytypes.Capability,
+	counterparty channeltypes.Counterparty,
+	version string,
+) (string, error) {
+	if err := im.keeper.ClaimCapability(ctx, chanCap, host.ChannelCapabilityPath(portID, channelID)); err != nil {
+		return "", err
+	}
+	return version, nil
+}
+
+// OnChanOpenTry implements the IBCModule interface, the handshake of interchain accounts is always initiated by the
+// controller chain
+func (im IBCModule) OnChanOpenTry(
+	ctx sdk.Context,
+	order channeltypes.Order,
+	connectionHops []string,
+	portID,
+	channelID string,
+	chanCap *capabilitytypes.Capability,
+	counterparty channeltypes.Counterparty,
+	counterpartyVersion string,
+) (string, error) {
+	return "", sdkerrors.Wrap(icatypes.ErrInvalidChannelFlow, "channel handshake must be initiated by controller chain")
+}
+
+// OnChanOpenAck implements the IBCModule interface
+func (im IBCModule) OnChanOpenAck(ctx sdk.Context, portID, channelID string, counterpartyChannelID string, counterpartyVersion string) error {
+	return nil
+}
+
+// OnChanOpenConfirm implements the IBCModule interface
+func (im IBCModule) OnChanOpenConfirm(ctx sdk.Context, portID, channelID string) error {
+	return sdkerrors.Wrap(icatypes.ErrInvalidChannelFlow, "channel handshake must be initiated by controller chain")
+}
+
+// OnChanCloseInit implements the IBCModule interface
+func (im IBCModule) OnChanCloseInit(ctx sdk.Context, portID, channelID string) error {
+	return nil
+}
+
+// OnChanCloseConfirm implements the IBCModule interface
+func (im IBCModule) OnChanCloseConfirm(ctx sdk.Context, portID, channelID string) error {
+	return nil
+}
+
+// OnRecvPacket implements the IBCModule interface, controller chains do not receive packets
+func (im IBCModule) OnRecvPacket(ctx sdk.Context, packet channeltypes.Packet, relayer sdk.AccAddress) ibcexported.Acknowledgement {
+	return channeltypes.NewErrorAcknowledgement(sdkerrors.Wrap(icatypes.ErrInvalidChannelFlow, "cannot receive packet on controller chain"))
+}
+
+// OnAcknowledgementPacket emits the result of the txs executed by the interchain account
+func (im IBCModule) OnAcknowledgementPacket(ctx sdk.Context, packet channeltypes.Packet, acknowledgement []byte, relayer sdk.AccAddress) error {
+	var ack channeltypes.Acknowledgement
+	if err := channeltypes.SubModuleCdc.UnmarshalJSON(acknowledgement, &ack); err != nil {
+		return sdkerrors.Wrapf(sdkerrors.ErrUnknownRequest, "cannot unmarshal ICS-27 packet acknowledgement: %v", err)
+	}
+
+	attributes := []sdk.Attribute{
+		sdk.NewAttribute(types.AttributeKeyPortID, packet.SourcePort),
+		sdk.NewAttribute(types.AttributeKeyChannel, packet.SourceChannel),
+		sdk.NewAttribute(types.AttributeKeySequence, strconv.FormatUint(packet.Sequence, 10)),
+		sdk.NewAttribute(types.AttributeKeySuccess, strconv.FormatBool(ack.Success())),
+	}
+	if !ack.Success() {
+		attributes = append(attributes, sdk.NewAttribute(types.AttributeKeyError, ack.GetError()))
+	}
+	ctx.EventManager().EmitEvent(sdk.NewEvent(types.EventTypeAcknowledgement, attributes...))
+
+	im.keeper.Logger(ctx).Debug("interchain account packet acknowledged", "port", packet.SourcePort, "sequence", packet.Sequence, "success", ack.Success())
+	return nil
+}
+
+// OnTimeoutPacket emits the timeout of the txs of the interchain account. The interchain accounts controller closes
+// the ordered channel, the owner has to register the account again to reopen it.
+func (im IBCModule) OnTimeoutPacket(ctx sdk.Context, packet channeltypes.Packet, relayer sdk.AccAddress) error {
+	ctx.EventManager().EmitEvent(sdk.NewEvent(
+		types.EventTypeTimeout,
+		sdk.NewAttribute(types.AttributeKeyPortID, packet.SourcePort),
+		sdk.NewAttribute(types.AttributeKeyChannel, packet.SourceChannel),
+		sdk.NewAttribute(types.AttributeKeySequence, strconv.FormatUint(packet.Sequence, 10)),
+	))
+
+	im.keeper.Logger(ctx).Info("interchain account packet timed out", "port", packet.SourcePort, "sequence", packet.Sequence)
+	return nil
+}
@@ -0,0 +1,59 @@
+package keeper
+
+import (
+	"context"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	icatypes "github.com/cosmos/ibc-go/v4/modules/apps/27-interchain-accounts/types"
+	"github.com/oraichain/orai/x/icaauth/types"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+var _ types.QueryServer = Keeper{}
+
+// InterchainAccount implements the Query/InterchainAccount method
+func (k Keeper) InterchainAccount(goCtx context.Context, req *types.QueryInterchainAccountRequest) (*types.QueryInterchainAccountResponse, error) {
+	if req == nil {
+		return nil, status.Error(codes.InvalidArgument, "empty request")
+	}
+	ctx := sdk.UnwrapSDKContext(goCtx)
+
+	portID, err := icatypes.NewControllerPortID(req.Owner)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	address, found := k.icaControllerKeeper.GetInterchainAccountAddress(ctx, req.ConnectionId, portID)
+	if !found {
+		return nil, status.Errorf(codes.NotFound, "no interchain account found for port %s on connection %s", portID, req.ConnectionId)
+	}
+
+	return &types.QueryInterchainAccountResponse{Address: address}, nil
+}
+
+// InterchainAccounts implements the Query/InterchainAccounts method
+func (k Keeper) InterchainAccounts(goCtx context.Context, req *types.QueryInterchainAccountsRequest) (*types.QueryInterchainAccountsResponse, error) {
+	if req == nil {
+		return nil, status.Error(codes.InvalidArgument, "empty request")
+	}
+	ctx := sdk.UnwrapSDKContext(goCtx)
+
+	portID, err := icatypes.NewControllerPortID(req.Owner)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	var accounts []types.InterchainAccount
+	for _, account := range k.icaControllerKeeper.GetAllInterchainAccounts(ctx) {
+		if account.PortId == portID {
+			accounts = append(accounts, types.InterchainAccount{
+				ConnectionId: account.ConnectionId,
+				PortId:       account.PortId,
+				Address:      account.AccountAddress,
+			})
+		}
+	}
+
+	return &types.QueryInterchainAccountsResponse{Accounts: accounts}, nil
+}
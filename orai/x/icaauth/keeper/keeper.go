@@ -0,0 +1,42 @@
+package keeper
+
+import (
+	"fmt"
+
+	"github.com/cosmos/cosmos-sdk/codec"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	capabilitykeeper "github.com/cosmos/cosmos-sdk/x/capability/keeper"
+	capabilitytypes "github.com/cosmos/cosmos-sdk/x/capability/types"
+	icacontrollerkeeper "github.com/cosmos/ibc-go/v4/modules/apps/27-interchain-accounts/controller/keeper"
+	host "github.com/cosmos/ibc-go/v4/modules/core/24-host"
+	"github.com/oraichain/orai/x/icaauth/types"
+	"github.com/tendermint/tendermint/libs/log"
+)
+
+// Keeper owns the channel capabilities of the interchain accounts registered through the module and sends their
+// txs through the interchain accounts controller
+type Keeper struct {
+	cdc codec.Codec
+
+	scopedKeeper        capabilitykeeper.ScopedKeeper
+	icaControllerKeeper icacontrollerkeeper.Keeper
+}
+
+// NewKeeper creates a new Keeper
+func NewKeeper(cdc codec.Codec, icaControllerKeeper icacontrollerkeeper.Keeper, scopedKeeper capabilitykeeper.ScopedKeeper) Keeper {
+	return Keeper{
+		cdc:                 cdc,
+		scopedKeeper:        scopedKeeper,
+		icaControllerKeeper: icaControllerKeeper,
+	}
+}
+
+// Logger returns the logger of the module
+func (k Keeper) Logger(ctx sdk.Context) log.Logger {
+	return ctx.Logger().With("module", fmt.Sprintf("x/%s-%s", host.ModuleName, types.ModuleName))
+}
+
+// ClaimCapability claims the channel capability of an interchain account
+func (k Keeper) ClaimCapability(ctx sdk.Context, cap *capabilitytypes.Capability, name string) error {
+	return k.scopedKeeper.ClaimCapability(ctx, cap, name)
+}
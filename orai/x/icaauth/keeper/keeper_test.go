@@ -0,0 +1,315 @@
+package keeper_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	wasmkeeper "github.com/CosmWasm/wasmd/x/wasm/keeper"
+	wasmvmtypes "github.com/CosmWasm/wasmvm/types"
+	"github.com/cosmos/cosmos-sdk/baseapp"
+	"github.com/cosmos/cosmos-sdk/simapp"
+	"github.com/cosmos/cosmos-sdk/store"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	banktypes "github.com/cosmos/cosmos-sdk/x/bank/types"
+	capabilitykeeper "github.com/cosmos/cosmos-sdk/x/capability/keeper"
+	capabilitytypes "github.com/cosmos/cosmos-sdk/x/capability/types"
+	paramtypes "github.com/cosmos/cosmos-sdk/x/params/types"
+	icacontrollerkeeper "github.com/cosmos/ibc-go/v4/modules/apps/27-interchain-accounts/controller/keeper"
+	icacontrollertypes "github.com/cosmos/ibc-go/v4/modules/apps/27-interchain-accounts/controller/types"
+	icatypes "github.com/cosmos/ibc-go/v4/modules/apps/27-interchain-accounts/types"
+	channeltypes "github.com/cosmos/ibc-go/v4/modules/core/04-channel/types"
+	host "github.com/cosmos/ibc-go/v4/modules/core/24-host"
+	ibcexported "github.com/cosmos/ibc-go/v4/modules/core/exported"
+	"github.com/oraichain/orai/x/icaauth/keeper"
+	"github.com/oraichain/orai/x/icaauth/types"
+	"github.com/stretchr/testify/require"
+	"github.com/tendermint/tendermint/libs/log"
+	tmproto "github.com/tendermint/tendermint/proto/tendermint/types"
+	dbm "github.com/tendermint/tm-db"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const connectionID = "connection-0"
+
+// mockIBC stands for the IBC core keepers of the interchain accounts controller, the capabilities of the ports and
+// channels being owned by its scoped keeper
+type mockIBC struct {
+	scopedKeeper capabilitykeeper.ScopedKeeper
+	channels     map[string]channeltypes.Channel
+	openInits    []*channeltypes.MsgChannelOpenInit
+	sentPackets  []ibcexported.PacketI
+	openInitErr  error
+}
+
+func (m *mockIBC) BindPort(ctx sdk.Context, portID string) *capabilitytypes.Capability {
+	portCap, err := m.scopedKeeper.NewCapability(ctx, host.PortPath(portID))
+	if err != nil {
+		panic(err)
+	}
+	return portCap
+}
+
+func (m *mockIBC) IsBound(ctx sdk.Context, portID string) bool {
+	_, found := m.scopedKeeper.GetCapability(ctx, host.PortPath(portID))
+	return found
+}
+
+func (m *mockIBC) GetChannel(_ sdk.Context, portID, channelID string) (channeltypes.Channel, bool) {
+	channel, found := m.channels[portID+"/"+channelID]
+	return channel, found
+}
+
+func (m *mockIBC) GetNextSequenceSend(sdk.Context, string, string) (uint64, bool) {
+	return uint64(len(m.sentPackets) + 1), true
+}
+
+func (m *mockIBC) GetConnection(sdk.Context, string) (ibcexported.ConnectionI, error) {
+	return nil, nil
+}
+
+func (m *mockIBC) SendPacket(_ sdk.Context, _ *capabilitytypes.Capability, packet ibcexported.PacketI) error {
+	m.sentPackets = append(m.sentPackets, packet)
+	return nil
+}
+
+func (m *mockIBC) GetAppVersion(sdk.Context, string, string) (string, bool) {
+	return "", false
+}
+
+// mockChannelMsgServer handles the channel open init msgs sent by the interchain accounts controller
+type mockChannelMsgServer struct {
+	channeltypes.MsgServer
+	ibc *mockIBC
+}
+
+func (m mockChannelMsgServer) ChannelOpenInit(_ context.Context, msg *channeltypes.MsgChannelOpenInit) (*channeltypes.MsgChannelOpenInitResponse, error) {
+	if m.ibc.openInitErr != nil {
+		return nil, m.ibc.openInitErr
+	}
+	m.ibc.openInits = append(m.ibc.openInits, msg)
+	return &channeltypes.MsgChannelOpenInitResponse{ChannelId: "channel-0"}, nil
+}
+
+type testKeepers struct {
+	ctx                 sdk.Context
+	ibc                 *mockIBC
+	icaAuthKeeper       keeper.Keeper
+	icaControllerKeeper icacontrollerkeeper.Keeper
+	// scopedIBCKeeper creates the channel capabilities claimed by icaauth
+	scopedIBCKeeper capabilitykeeper.ScopedKeeper
+}
+
+func setupKeepers(t *testing.T) testKeepers {
+	encCfg := simapp.MakeTestEncodingConfig()
+	channeltypes.RegisterInterfaces(encCfg.InterfaceRegistry)
+	types.RegisterInterfaces(encCfg.InterfaceRegistry)
+
+	keys := sdk.NewKVStoreKeys(capabilitytypes.StoreKey, icacontrollertypes.StoreKey, paramtypes.StoreKey)
+	memKey := sdk.NewMemoryStoreKeys(capabilitytypes.MemStoreKey)[capabilitytypes.MemStoreKey]
+	tkey := sdk.NewTransientStoreKey(paramtypes.TStoreKey)
+	cms := store.NewCommitMultiStore(dbm.NewMemDB())
+	for _, key := range keys {
+		cms.MountStoreWithDB(key, sdk.StoreTypeIAVL, nil)
+	}
+	cms.MountStoreWithDB(memKey, sdk.StoreTypeMemory, nil)
+	cms.MountStoreWithDB(tkey, sdk.StoreTypeTransient, nil)
+	require.NoError(t, cms.LoadLatestVersion())
+	ctx := sdk.NewContext(cms, tmproto.Header{Height: 1, Time: time.Unix(1000, 0).UTC()}, false, log.NewNopLogger())
+
+	capabilityKeeper := capabilitykeeper.NewKeeper(encCfg.Marshaler, keys[capabilitytypes.StoreKey], memKey)
+	scopedIBCKeeper := capabilityKeeper.ScopeToModule(host.ModuleName)
+	scopedICAControllerKeeper := capabilityKeeper.ScopeToModule(icacontrollertypes.SubModuleName)
+	scopedICAAuthKeeper := capabilityKeeper.ScopeToModule(types.ModuleName)
+	capabilityKeeper.Seal()
+	capabilityKeeper.InitMemStore(ctx)
+
+	ibc := &mockIBC{scopedKeeper: scopedIBCKeeper, channels: make(map[string]channeltypes.Channel)}
+	msgRouter := baseapp.NewMsgServiceRouter()
+	msgRouter.SetInterfaceRegistry(encCfg.InterfaceRegistry)
+	channeltypes.RegisterMsgServer(msgRouter, mockChannelMsgServer{ibc: ibc})
+
+	subspace := paramtypes.NewSubspace(encCfg.Marshaler, encCfg.Amino, keys[paramtypes.StoreKey], tkey, icacontrollertypes.SubModuleName)
+	icaControllerKeeper := icacontrollerkeeper.NewKeeper(
+		encCfg.Marshaler, keys[icacontrollertypes.StoreKey], subspace, ibc, ibc, ibc, scopedICAControllerKeeper, msgRouter,
+	)
+	icaControllerKeeper.SetParams(ctx, icacontrollertypes.NewParams(true))
+
+	return testKeepers{
+		ctx:                 ctx,
+		ibc:                 ibc,
+		icaAuthKeeper:       keeper.NewKeeper(encCfg.Marshaler, icaControllerKeeper, scopedICAAuthKeeper),
+		icaControllerKeeper: icaControllerKeeper,
+		scopedIBCKeeper:     scopedIBCKeeper,
+	}
+}
+
+// openChannel opens the channel of the interchain account of portID, whose capability icaauth claims if claim is set
+func (k testKeepers) openChannel(t *testing.T, portID string, claim bool) {
+	k.ibc.channels[portID+"/channel-0"] = channeltypes.NewChannel(
+		channeltypes.OPEN, channeltypes.ORDERED, channeltypes.NewCounterparty(icatypes.PortID, "channel-7"), []string{connectionID}, "",
+	)
+	k.icaControllerKeeper.SetActiveChannelID(k.ctx, connectionID, portID, "channel-0")
+	if claim {
+		chanCap, err := k.scopedIBCKeeper.NewCapability(k.ctx, host.ChannelCapabilityPath(portID, "channel-0"))
+		require.NoError(t, err)
+		require.NoError(t, k.icaAuthKeeper.ClaimCapability(k.ctx, chanCap, host.ChannelCapabilityPath(portID, "channel-0")))
+	}
+}
+
+func TestRegisterAccount(t *testing.T) {
+	k := setupKeepers(t)
+	msgServer := keeper.NewMsgServerImpl(k.icaAuthKeeper)
+	owner := sdk.AccAddress("owner_______________").String()
+	portID, err := icatypes.NewControllerPortID(owner)
+	require.NoError(t, err)
+
+	res, err := msgServer.RegisterAccount(sdk.WrapSDKContext(k.ctx), types.NewMsgRegisterAccount(owner, connectionID, "version"))
+	require.NoError(t, err)
+	require.Equal(t, portID, res.PortId)
+
+	// the controller binds the port and opens the channel of the account
+	require.True(t, k.icaControllerKeeper.IsBound(k.ctx, portID))
+	require.Len(t, k.ibc.openInits, 1)
+	require.Equal(t, portID, k.ibc.openInits[0].PortId)
+	require.Equal(t, "version", k.ibc.openInits[0].Channel.Version)
+	require.Equal(t, []string{connectionID}, k.ibc.openInits[0].Channel.ConnectionHops)
+	require.Equal(t, icatypes.PortID, k.ibc.openInits[0].Channel.Counterparty.PortId)
+
+	// registering again opens a new channel while the previous one is not open, and fails once it is
+	_, err = msgServer.RegisterAccount(sdk.WrapSDKContext(k.ctx), types.NewMsgRegisterAccount(owner, connectionID, "version"))
+	require.NoError(t, err)
+	require.Len(t, k.ibc.openInits, 2)
+	k.openChannel(t, portID, false)
+	_, err = msgServer.RegisterAccount(sdk.WrapSDKContext(k.ctx), types.NewMsgRegisterAccount(owner, connectionID, "version"))
+	require.ErrorIs(t, err, icatypes.ErrActiveChannelAlreadySet)
+
+	// the errors of the channel handshake are returned
+	k.ibc.openInitErr = channeltypes.ErrInvalidChannelVersion
+	other := sdk.AccAddress("other_______________").String()
+	_, err = msgServer.RegisterAccount(sdk.WrapSDKContext(k.ctx), types.NewMsgRegisterAccount(other, connectionID, "version"))
+	require.ErrorIs(t, err, channeltypes.ErrInvalidChannelVersion)
+
+	_, err = msgServer.RegisterAccount(sdk.WrapSDKContext(k.ctx), types.NewMsgRegisterAccount("", connectionID, "version"))
+	require.Error(t, err)
+}
+
+func TestSubmitTx(t *testing.T) {
+	k := setupKeepers(t)
+	msgServer := keeper.NewMsgServerImpl(k.icaAuthKeeper)
+	owner := sdk.AccAddress("owner_______________")
+	portID, err := icatypes.NewControllerPortID(owner.String())
+	require.NoError(t, err)
+
+	send := banktypes.NewMsgSend(owner, owner, sdk.NewCoins(sdk.NewInt64Coin("uatom", 1)))
+	msg, err := types.NewMsgSubmitTx(owner.String(), connectionID, []sdk.Msg{send}, time.Minute)
+	require.NoError(t, err)
+
+	// no active channel
+	_, err = msgServer.SubmitTx(sdk.WrapSDKContext(k.ctx), msg)
+	require.ErrorIs(t, err, icatypes.ErrActiveChannelNotFound)
+
+	// an active channel whose capability icaauth does not own
+	k.openChannel(t, portID, false)
+	_, err = msgServer.SubmitTx(sdk.WrapSDKContext(k.ctx), msg)
+	require.ErrorIs(t, err, channeltypes.ErrChannelCapabilityNotFound)
+	require.Empty(t, k.ibc.sentPackets)
+
+	k.openChannel(t, portID, true)
+	res, err := msgServer.SubmitTx(sdk.WrapSDKContext(k.ctx), msg)
+	require.NoError(t, err)
+	require.Equal(t, uint64(1), res.Sequence)
+
+	require.Len(t, k.ibc.sentPackets, 1)
+	packet := k.ibc.sentPackets[0]
+	require.Equal(t, portID, packet.GetSourcePort())
+	require.Equal(t, "channel-0", packet.GetSourceChannel())
+	require.Equal(t, "channel-7", packet.GetDestChannel())
+	require.Equal(t, uint64(k.ctx.BlockTime().Add(time.Minute).UnixNano()), packet.GetTimeoutTimestamp())
+
+	var packetData icatypes.InterchainAccountPacketData
+	require.NoError(t, icatypes.ModuleCdc.UnmarshalJSON(packet.GetData(), &packetData))
+	require.Equal(t, icatypes.EXECUTE_TX, packetData.Type)
+	msgs, err := icatypes.DeserializeCosmosTx(simapp.MakeTestEncodingConfig().Marshaler, packetData.Data)
+	require.NoError(t, err)
+	require.Equal(t, []sdk.Msg{send}, msgs)
+
+	// a closed channel is no longer active
+	channel := k.ibc.channels[portID+"/channel-0"]
+	channel.State = channeltypes.CLOSED
+	k.ibc.channels[portID+"/channel-0"] = channel
+	_, err = msgServer.SubmitTx(sdk.WrapSDKContext(k.ctx), msg)
+	require.ErrorIs(t, err, icatypes.ErrActiveChannelNotFound)
+	require.Len(t, k.ibc.sentPackets, 1)
+}
+
+func TestQueryInterchainAccounts(t *testing.T) {
+	k := setupKeepers(t)
+	goCtx := sdk.WrapSDKContext(k.ctx)
+	owner := sdk.AccAddress("owner_______________").String()
+	portID, err := icatypes.NewControllerPortID(owner)
+	require.NoError(t, err)
+	otherPortID, err := icatypes.NewControllerPortID(sdk.AccAddress("other_______________").String())
+	require.NoError(t, err)
+
+	k.icaControllerKeeper.SetInterchainAccountAddress(k.ctx, connectionID, portID, "cosmos1account0")
+	k.icaControllerKeeper.SetInterchainAccountAddress(k.ctx, "connection-1", portID, "cosmos1account1")
+	k.icaControllerKeeper.SetInterchainAccountAddress(k.ctx, connectionID, otherPortID, "cosmos1other")
+
+	res, err := k.icaAuthKeeper.InterchainAccount(goCtx, &types.QueryInterchainAccountRequest{Owner: owner, ConnectionId: connectionID})
+	require.NoError(t, err)
+	require.Equal(t, "cosmos1account0", res.Address)
+
+	_, err = k.icaAuthKeeper.InterchainAccount(goCtx, &types.QueryInterchainAccountRequest{Owner: owner, ConnectionId: "connection-2"})
+	require.Equal(t, codes.NotFound, status.Code(err))
+	_, err = k.icaAuthKeeper.InterchainAccount(goCtx, &types.QueryInterchainAccountRequest{ConnectionId: connectionID})
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+	_, err = k.icaAuthKeeper.InterchainAccount(goCtx, nil)
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	accounts, err := k.icaAuthKeeper.InterchainAccounts(goCtx, &types.QueryInterchainAccountsRequest{Owner: owner})
+	require.NoError(t, err)
+	require.Equal(t, []types.InterchainAccount{
+		{ConnectionId: connectionID, PortId: portID, Address: "cosmos1account0"},
+		{ConnectionId: "connection-1", PortId: portID, Address: "cosmos1account1"},
+	}, accounts.Accounts)
+
+	_, err = k.icaAuthKeeper.InterchainAccounts(goCtx, nil)
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestStargateQueryInterchainAccount(t *testing.T) {
+	k := setupKeepers(t)
+	encCfg := simapp.MakeTestEncodingConfig()
+	owner := sdk.AccAddress("owner_______________").String()
+	portID, err := icatypes.NewControllerPortID(owner)
+	require.NoError(t, err)
+	k.icaControllerKeeper.SetInterchainAccountAddress(k.ctx, connectionID, portID, "cosmos1account0")
+
+	queryRouter := baseapp.NewGRPCQueryRouter()
+	queryRouter.SetInterfaceRegistry(encCfg.InterfaceRegistry)
+	types.RegisterQueryServer(queryRouter, k.icaAuthKeeper)
+	querier := wasmkeeper.AcceptListStargateQuerier(types.StargateQueries(), queryRouter, encCfg.Marshaler)
+
+	// contracts get the JSON response of the accepted queries
+	req, err := encCfg.Marshaler.Marshal(&types.QueryInterchainAccountRequest{Owner: owner, ConnectionId: connectionID})
+	require.NoError(t, err)
+	bz, err := querier(k.ctx, &wasmvmtypes.StargateQuery{Path: "/oraichain.icaauth.v1.Query/InterchainAccount", Data: req})
+	require.NoError(t, err)
+	var res types.QueryInterchainAccountResponse
+	require.NoError(t, encCfg.Marshaler.UnmarshalJSON(bz, &res))
+	require.Equal(t, "cosmos1account0", res.Address)
+
+	req, err = encCfg.Marshaler.Marshal(&types.QueryInterchainAccountsRequest{Owner: owner})
+	require.NoError(t, err)
+	bz, err = querier(k.ctx, &wasmvmtypes.StargateQuery{Path: "/oraichain.icaauth.v1.Query/InterchainAccounts", Data: req})
+	require.NoError(t, err)
+	var accounts types.QueryInterchainAccountsResponse
+	require.NoError(t, encCfg.Marshaler.UnmarshalJSON(bz, &accounts))
+	require.Equal(t, []types.InterchainAccount{{ConnectionId: connectionID, PortId: portID, Address: "cosmos1account0"}}, accounts.Accounts)
+
+	// the queries of the other modules are not accepted
+	_, err = querier(k.ctx, &wasmvmtypes.StargateQuery{Path: "/cosmos.bank.v1beta1.Query/AllBalances"})
+	require.ErrorAs(t, err, &wasmvmtypes.UnsupportedRequest{})
+}
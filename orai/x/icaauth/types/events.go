@@ -0,0 +1,13 @@
+package types
+
+// events of the module
+const (
+	EventTypeAcknowledgement = "icaauth_acknowledgement"
+	EventTypeTimeout         = "icaauth_timeout"
+
+	AttributeKeyPortID   = "port_id"
+	AttributeKeyChannel  = "channel_id"
+	AttributeKeySequence = "sequence"
+	AttributeKeySuccess  = "success"
+	AttributeKeyError    = "error"
+)
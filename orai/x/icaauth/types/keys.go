@@ -0,0 +1,13 @@
+package types
+
+const (
+	// ModuleName defines the name of the module, which authenticates the owners of interchain accounts for the
+	// interchain accounts controller
+	ModuleName = "icaauth"
+
+	// RouterKey is the message route of the module
+	RouterKey = ModuleName
+
+	// QuerierRoute is the querier route of the module
+	QuerierRoute = ModuleName
+)
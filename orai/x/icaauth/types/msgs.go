@@ -0,0 +1,185 @@
+package types
+
+import (
+	"encoding/json"
+	"strconv"
+	"time"
+
+	codectypes "github.com/cosmos/cosmos-sdk/codec/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	sdkerrors "github.com/cosmos/cosmos-sdk/types/errors"
+	"github.com/cosmos/cosmos-sdk/x/auth/legacy/legacytx"
+	host "github.com/cosmos/ibc-go/v4/modules/core/24-host"
+)
+
+// msg types, used in the amino JSON signing of the msgs
+const (
+	TypeMsgRegisterAccount = "register_account"
+	TypeMsgSubmitTx        = "submit_tx"
+)
+
+var (
+	_ sdk.Msg            = &MsgRegisterAccount{}
+	_ sdk.Msg            = &MsgSubmitTx{}
+	_ legacytx.LegacyMsg = &MsgRegisterAccount{}
+	_ legacytx.LegacyMsg = &MsgSubmitTx{}
+
+	_ codectypes.UnpackInterfacesMessage = MsgSubmitTx{}
+)
+
+// NewMsgRegisterAccount creates a new MsgRegisterAccount
+func NewMsgRegisterAccount(owner, connectionID, version string) *MsgRegisterAccount {
+	return &MsgRegisterAccount{
+		Owner:        owner,
+		ConnectionId: connectionID,
+		Version:      version,
+	}
+}
+
+// ValidateBasic implements sdk.Msg
+func (msg MsgRegisterAccount) ValidateBasic() error {
+	if _, err := sdk.AccAddressFromBech32(msg.Owner); err != nil {
+		return sdkerrors.Wrapf(sdkerrors.ErrInvalidAddress, "invalid owner address: %s", err)
+	}
+	if err := host.ConnectionIdentifierValidator(msg.ConnectionId); err != nil {
+		return sdkerrors.Wrap(err, "invalid connection id")
+	}
+	return nil
+}
+
+// GetSigners implements sdk.Msg
+func (msg MsgRegisterAccount) GetSigners() []sdk.AccAddress {
+	owner, err := sdk.AccAddressFromBech32(msg.Owner)
+	if err != nil {
+		panic(err)
+	}
+	return []sdk.AccAddress{owner}
+}
+
+// Route implements legacytx.LegacyMsg
+func (msg MsgRegisterAccount) Route() string { return RouterKey }
+
+// Type implements legacytx.LegacyMsg
+func (msg MsgRegisterAccount) Type() string { return TypeMsgRegisterAccount }
+
+// GetSignBytes implements legacytx.LegacyMsg
+func (msg MsgRegisterAccount) GetSignBytes() []byte {
+	return sdk.MustSortJSON(ModuleCdc.MustMarshalJSON(&msg))
+}
+
+// NewMsgSubmitTx creates a new MsgSubmitTx executing msgs with the interchain account of owner, timing out
+// relativeTimeout after the block time
+func NewMsgSubmitTx(owner, connectionID string, msgs []sdk.Msg, relativeTimeout time.Duration) (*MsgSubmitTx, error) {
+	anys := make([]*codectypes.Any, len(msgs))
+	for i, msg := range msgs {
+		any, err := codectypes.NewAnyWithValue(msg)
+		if err != nil {
+			return nil, err
+		}
+		anys[i] = any
+	}
+
+	return &MsgSubmitTx{
+		Owner:           owner,
+		ConnectionId:    connectionID,
+		Msgs:            anys,
+		RelativeTimeout: uint64(relativeTimeout),
+	}, nil
+}
+
+// GetTxMsgs returns the messages executed on the host chain
+func (msg MsgSubmitTx) GetTxMsgs() ([]sdk.Msg, error) {
+	msgs := make([]sdk.Msg, len(msg.Msgs))
+	for i, any := range msg.Msgs {
+		txMsg, ok := any.GetCachedValue().(sdk.Msg)
+		if !ok {
+			return nil, sdkerrors.Wrapf(sdkerrors.ErrInvalidType, "message %s is not a sdk.Msg", any.TypeUrl)
+		}
+		msgs[i] = txMsg
+	}
+	return msgs, nil
+}
+
+// ValidateBasic implements sdk.Msg
+func (msg MsgSubmitTx) ValidateBasic() error {
+	if _, err := sdk.AccAddressFromBech32(msg.Owner); err != nil {
+		return sdkerrors.Wrapf(sdkerrors.ErrInvalidAddress, "invalid owner address: %s", err)
+	}
+	if err := host.ConnectionIdentifierValidator(msg.ConnectionId); err != nil {
+		return sdkerrors.Wrap(err, "invalid connection id")
+	}
+	if len(msg.Msgs) == 0 {
+		return sdkerrors.Wrap(sdkerrors.ErrInvalidRequest, "no messages to execute")
+	}
+	if msg.RelativeTimeout == 0 {
+		return sdkerrors.Wrap(sdkerrors.ErrInvalidRequest, "relative timeout must be positive")
+	}
+	return nil
+}
+
+// GetSigners implements sdk.Msg
+func (msg MsgSubmitTx) GetSigners() []sdk.AccAddress {
+	owner, err := sdk.AccAddressFromBech32(msg.Owner)
+	if err != nil {
+		panic(err)
+	}
+	return []sdk.AccAddress{owner}
+}
+
+// Route implements legacytx.LegacyMsg
+func (msg MsgSubmitTx) Route() string { return RouterKey }
+
+// Type implements legacytx.LegacyMsg
+func (msg MsgSubmitTx) Type() string { return TypeMsgSubmitTx }
+
+// signedTxMsg is the amino JSON of a msg executed on the host chain: its type URL, so that the signature commits to
+// the type of the msg and not only to its fields, and its amino JSON value
+type signedTxMsg struct {
+	TypeURL string          `json:"type_url"`
+	Value   json.RawMessage `json:"value"`
+}
+
+// GetSignBytes implements legacytx.LegacyMsg, the msgs executed on the host chain being encoded as their type URL and
+// amino JSON value
+func (msg MsgSubmitTx) GetSignBytes() []byte {
+	msgs := make([]signedTxMsg, len(msg.Msgs))
+	for i, any := range msg.Msgs {
+		// the msgs which are not unpacked are signed as their proto bytes
+		var value interface{} = any.Value
+		if cached := any.GetCachedValue(); cached != nil {
+			value = cached
+		}
+		msgs[i] = signedTxMsg{TypeURL: any.TypeUrl, Value: ModuleCdc.LegacyAmino.MustMarshalJSON(value)}
+	}
+
+	signDoc := struct {
+		Type  string `json:"type"`
+		Value struct {
+			Owner           string        `json:"owner"`
+			ConnectionID    string        `json:"connection_id"`
+			Msgs            []signedTxMsg `json:"msgs"`
+			RelativeTimeout string        `json:"relative_timeout"`
+		} `json:"value"`
+	}{Type: "icaauth/MsgSubmitTx"}
+	signDoc.Value.Owner = msg.Owner
+	signDoc.Value.ConnectionID = msg.ConnectionId
+	signDoc.Value.Msgs = msgs
+	signDoc.Value.RelativeTimeout = strconv.FormatUint(msg.RelativeTimeout, 10)
+
+	bz, err := json.Marshal(signDoc)
+	if err != nil {
+		panic(err)
+	}
+	return sdk.MustSortJSON(bz)
+}
+
+// UnpackInterfaces implements codectypes.UnpackInterfacesMessage
+func (msg MsgSubmitTx) UnpackInterfaces(unpacker codectypes.AnyUnpacker) error {
+	for _, any := range msg.Msgs {
+		var txMsg sdk.Msg
+		if err := unpacker.UnpackAny(any, &txMsg); err != nil {
+			return err
+		}
+	}
+	return nil
+}
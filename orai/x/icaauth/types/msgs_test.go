@@ -0,0 +1,85 @@
+package types_test
+
+import (
+	"testing"
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/x/auth/legacy/legacytx"
+	banktypes "github.com/cosmos/cosmos-sdk/x/bank/types"
+	stakingtypes "github.com/cosmos/cosmos-sdk/x/staking/types"
+	"github.com/oraichain/orai/x/icaauth/types"
+	"github.com/stretchr/testify/require"
+	"github.com/tendermint/tendermint/crypto/ed25519"
+)
+
+func TestMsgRegisterAccountValidateBasic(t *testing.T) {
+	owner := sdk.AccAddress(ed25519.GenPrivKey().PubKey().Address()).String()
+
+	require.NoError(t, types.NewMsgRegisterAccount(owner, "connection-0", "").ValidateBasic())
+	require.Error(t, types.NewMsgRegisterAccount("", "connection-0", "").ValidateBasic())
+	require.Error(t, types.NewMsgRegisterAccount(owner, "", "").ValidateBasic())
+	require.Error(t, types.NewMsgRegisterAccount(owner, "channel-0", "").ValidateBasic())
+}
+
+func TestMsgSubmitTxValidateBasic(t *testing.T) {
+	owner := sdk.AccAddress(ed25519.GenPrivKey().PubKey().Address())
+	send := banktypes.NewMsgSend(owner, owner, sdk.NewCoins(sdk.NewInt64Coin("uatom", 1)))
+
+	msg, err := types.NewMsgSubmitTx(owner.String(), "connection-0", []sdk.Msg{send}, time.Minute)
+	require.NoError(t, err)
+	require.NoError(t, msg.ValidateBasic())
+	require.Equal(t, uint64(time.Minute), msg.RelativeTimeout)
+
+	msgs, err := msg.GetTxMsgs()
+	require.NoError(t, err)
+	require.Equal(t, []sdk.Msg{send}, msgs)
+
+	noMsgs, err := types.NewMsgSubmitTx(owner.String(), "connection-0", nil, time.Minute)
+	require.NoError(t, err)
+	require.Error(t, noMsgs.ValidateBasic())
+
+	noTimeout, err := types.NewMsgSubmitTx(owner.String(), "connection-0", []sdk.Msg{send}, 0)
+	require.NoError(t, err)
+	require.Error(t, noTimeout.ValidateBasic())
+
+	badOwner, err := types.NewMsgSubmitTx("owner", "connection-0", []sdk.Msg{send}, time.Minute)
+	require.NoError(t, err)
+	require.Error(t, badOwner.ValidateBasic())
+}
+
+func TestMsgsLegacyAminoSignBytes(t *testing.T) {
+	owner := sdk.AccAddress("owner_______________")
+	send := banktypes.NewMsgSend(owner, owner, sdk.NewCoins(sdk.NewInt64Coin("uatom", 1)))
+	submitTx, err := types.NewMsgSubmitTx(owner.String(), "connection-0", []sdk.Msg{send}, time.Minute)
+	require.NoError(t, err)
+	registerAccount := types.NewMsgRegisterAccount(owner.String(), "connection-0", "")
+
+	require.Equal(t, types.RouterKey, registerAccount.Route())
+	require.Equal(t, types.TypeMsgRegisterAccount, registerAccount.Type())
+	require.Equal(t, types.RouterKey, submitTx.Route())
+	require.Equal(t, types.TypeMsgSubmitTx, submitTx.Type())
+
+	require.Equal(t,
+		`{"type":"icaauth/MsgRegisterAccount","value":{"connection_id":"connection-0","owner":"`+owner.String()+`"}}`,
+		string(registerAccount.GetSignBytes()))
+	require.Equal(t,
+		`{"type":"icaauth/MsgSubmitTx","value":{"connection_id":"connection-0","msgs":[{"type_url":"/cosmos.bank.v1beta1.MsgSend","value":`+
+			`{"amount":[{"amount":"1","denom":"uatom"}],"from_address":"`+owner.String()+`","to_address":"`+owner.String()+`"}}],"owner":"`+owner.String()+`","relative_timeout":"60000000000"}}`,
+		string(submitTx.GetSignBytes()))
+
+	// the type of the msgs executed on the host chain is signed, not only their fields
+	delegate := stakingtypes.NewMsgDelegate(owner, sdk.ValAddress(owner), sdk.NewInt64Coin("uatom", 1))
+	undelegate := stakingtypes.NewMsgUndelegate(owner, sdk.ValAddress(owner), sdk.NewInt64Coin("uatom", 1))
+	submitDelegate, err := types.NewMsgSubmitTx(owner.String(), "connection-0", []sdk.Msg{delegate}, time.Minute)
+	require.NoError(t, err)
+	submitUndelegate, err := types.NewMsgSubmitTx(owner.String(), "connection-0", []sdk.Msg{undelegate}, time.Minute)
+	require.NoError(t, err)
+	require.Contains(t, string(submitDelegate.GetSignBytes()), `"type_url":"`+sdk.MsgTypeURL(delegate)+`"`)
+	require.NotEqual(t, submitDelegate.GetSignBytes(), submitUndelegate.GetSignBytes())
+
+	// the msgs are signed in amino JSON along with the ones of the other modules
+	signBytes := legacytx.StdSignBytes("orai", 1, 2, 0, legacytx.NewStdFee(200000, sdk.NewCoins(sdk.NewInt64Coin("orai", 1))),
+		[]sdk.Msg{registerAccount, submitTx}, "")
+	require.Contains(t, string(signBytes), `"type":"icaauth/MsgSubmitTx"`)
+}
@@ -0,0 +1,14 @@
+package types
+
+import (
+	"github.com/cosmos/cosmos-sdk/codec"
+)
+
+// StargateQueries returns the queries of the module that contracts can send as stargate queries, by gRPC path with
+// their response type, so that contracts can look up the interchain accounts of their owners
+func StargateQueries() map[string]codec.ProtoMarshaler {
+	return map[string]codec.ProtoMarshaler{
+		"/oraichain.icaauth.v1.Query/InterchainAccount":  &QueryInterchainAccountResponse{},
+		"/oraichain.icaauth.v1.Query/InterchainAccounts": &QueryInterchainAccountsResponse{},
+	}
+}